@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/database"
+	"holding-snapshots/pkg/utils"
+)
+
+// Carga precios históricos de un asset sin levantar el servidor.
+// Uso: go run cmd/backfill/main.go -asset <id> -from 2023-01-01 [-to 2024-01-01] [-interval week] [-symbol AAPL]
+func main() {
+	assetID := flag.String("asset", "", "ID del asset")
+	from := flag.String("from", "", "Fecha de inicio (YYYY-MM-DD)")
+	to := flag.String("to", "", "Fecha de fin (YYYY-MM-DD, por defecto hoy)")
+	interval := flag.String("interval", "day", "Intervalo de precios (day o week)")
+	symbol := flag.String("symbol", "", "Símbolo en el proveedor (por defecto el código del asset)")
+	flag.Parse()
+
+	if !utils.IsValidUUID(*assetID) {
+		log.Fatalf("❌ ID de asset inválido: %q", *assetID)
+	}
+
+	fromDate, toDate, err := utils.ParseDateRange(*from, *to)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// Cargar configuración
+	cfg := config.LoadConfig()
+
+	// Conectar a la base de datos
+	if err := database.Connect(cfg.DatabaseURL); err != nil {
+		log.Fatalf("❌ Error conectando a la base de datos: %v", err)
+	}
+
+	// Migrar tablas usadas por el backfill
+	if err := database.AutoMigrate(&models.AssetPrice{}, &models.BackfillJob{}, &models.SymbolMapping{}); err != nil {
+		log.Fatalf("❌ Error ejecutando migraciones: %v", err)
+	}
+
+	req := services.BackfillRequest{
+		AssetID:  *assetID,
+		Symbol:   *symbol,
+		Interval: *interval,
+	}
+	if fromDate != nil {
+		req.From = *fromDate
+	}
+	if toDate != nil {
+		req.To = *toDate
+	}
+
+	backfillService := services.NewBackfillService()
+
+	job, err := backfillService.CreateJob(req)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if err := backfillService.RunJob(job); err != nil {
+		log.Fatalf("❌ Backfill %s fallido: %v", job.ID, err)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"syscall"
 
 	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
 	"holding-snapshots/internal/routes"
 	"holding-snapshots/internal/services"
 	"holding-snapshots/pkg/cache"
 	"holding-snapshots/pkg/database"
+	"holding-snapshots/pkg/mailer"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -31,9 +33,65 @@ func main() {
 		log.Printf("⚠️ Advertencia: Error habilitando extensión UUID: %v", err)
 	}
 
-	// Conectar a Redis
+	// Migrar tablas propias del servicio
+	if err := database.AutoMigrate(
+		&models.WebhookDelivery{},
+		&models.NotificationPreference{},
+		&models.AlertRule{},
+		&models.AlertTrigger{},
+		&models.Transaction{},
+		&models.SnapshotPnL{},
+		&models.FXRate{},
+		&models.Benchmark{},
+		&models.AssetPrice{},
+		&models.BackfillJob{},
+		&models.GroupSnapshot{},
+		&models.UserSnapshot{},
+		&models.SnapshotRollup{},
+		&models.SymbolMapping{},
+		&models.CorporateAction{},
+		&models.DividendIncome{},
+	); err != nil {
+		log.Fatalf("❌ Error ejecutando migraciones: %v", err)
+	}
+
+	// Referencia de cada snapshot al precio del asset
+	if err := database.AddColumnIfMissing(&models.Snapshot{}, "AssetPriceID"); err != nil {
+		log.Fatalf("❌ Error agregando columna a Snapshot: %v", err)
+	}
+
+	// El mismo código puede existir como asset en distintos tipos de inversión (mercados). Asset es una
+	// tabla de la aplicación principal, así que el cambio de índice es opcional y se hace una sola vez.
+	if config.AppConfig.AssetTypeCodeIndexEnabled {
+		if err := database.ReplaceUniqueIndex("Asset", "code", "idx_asset_type_code", `("typeId", UPPER(code))`); err != nil {
+			log.Fatalf("❌ Error actualizando índice único de Asset: %v", err)
+		}
+	}
+
+	// Conectar a Redis. Sin Redis el servicio funciona sin cache; el cliente reintenta en cada uso.
 	if err := cache.Connect(cfg.RedisURL); err != nil {
-		log.Fatalf("❌ Error conectando a Redis: %v", err)
+		log.Printf("⚠️ Redis no disponible, se continúa sin cache: %v", err)
+	}
+
+	// Configurar envío de emails
+	if cfg.SMTPHost != "" {
+		mailer.Configure(mailer.Settings{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
+
+	// Reanudar las entregas de webhooks que quedaron pendientes o en reintento antes del reinicio
+	if _, err := services.NewWebhookService().ResumePending(); err != nil {
+		log.Printf("⚠️ Error reanudando entregas de webhooks: %v", err)
+	}
+
+	// Los backfills lanzados por HTTP corren en una goroutine y no sobreviven a un reinicio
+	if _, err := services.NewBackfillService().FailInterrupted(); err != nil {
+		log.Printf("⚠️ Error marcando backfills interrumpidos: %v", err)
 	}
 
 	// Crear aplicación Fiber
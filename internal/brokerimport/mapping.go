@@ -0,0 +1,179 @@
+package brokerimport
+
+import (
+	"fmt"
+	"strings"
+
+	"holding-snapshots/internal/models"
+)
+
+// Mapping indica cómo leer el CSV de un broker: separador, columnas y formatos de fecha y números.
+// Los nombres de columna se comparan sin distinguir mayúsculas ni espacios de los extremos.
+type Mapping struct {
+	Name         string            `json:"name"`
+	Delimiter    string            `json:"delimiter"`              // Por defecto ","
+	DecimalComma bool              `json:"decimalComma"`           // "1.234,56" en lugar de "1,234.56"
+	DateLayouts  []string          `json:"dateLayouts"`            // Layouts de Go probados en orden
+	Date         string            `json:"date,omitempty"`         // Sin columna de fecha las filas son posiciones
+	Type         string            `json:"type,omitempty"`         // Sin columna de tipo se usa el signo de la cantidad
+	Code         string            `json:"code"`                   // Requerida
+	AssetName    string            `json:"assetName,omitempty"`    // Nombre del asset, opcional
+	Quantity     string            `json:"quantity"`               // Requerida
+	Price        string            `json:"price,omitempty"`        // Precio unitario
+	Fees         string            `json:"fees,omitempty"`         // Comisiones y gastos
+	TypeAliases  map[string]string `json:"typeAliases,omitempty"`  // Valor del broker -> buy, sell, transfer_in, transfer_out
+	SkipPrefixes []string          `json:"skipPrefixes,omitempty"` // Filas cuya primera celda empieza así se ignoran (totales, notas)
+}
+
+// Validate verifica que el mapeo tenga las columnas mínimas
+func (m *Mapping) Validate() error {
+	if strings.TrimSpace(m.Code) == "" {
+		return fmt.Errorf("el mapeo requiere la columna de código")
+	}
+	if strings.TrimSpace(m.Quantity) == "" {
+		return fmt.Errorf("el mapeo requiere la columna de cantidad")
+	}
+	if len([]rune(m.delimiter())) != 1 {
+		return fmt.Errorf("el separador debe ser un único carácter")
+	}
+	if m.Date != "" && len(m.DateLayouts) == 0 {
+		return fmt.Errorf("el mapeo requiere al menos un formato de fecha")
+	}
+	for alias, transactionType := range m.TypeAliases {
+		if !isValidTransactionType(transactionType) {
+			return fmt.Errorf("tipo de transacción inválido para %q: %s", alias, transactionType)
+		}
+	}
+	return nil
+}
+
+// HasTransactions indica si las filas son transacciones (con fecha) o posiciones
+func (m *Mapping) HasTransactions() bool {
+	return m.Date != ""
+}
+
+// delimiter retorna el separador configurado o la coma
+func (m *Mapping) delimiter() string {
+	if m.Delimiter == "" {
+		return ","
+	}
+	return m.Delimiter
+}
+
+// resolveType traduce el tipo de operación del broker a un tipo de transacción
+func (m *Mapping) resolveType(value string) (string, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	for alias, transactionType := range m.TypeAliases {
+		if strings.ToLower(alias) == normalized {
+			return transactionType, true
+		}
+	}
+	if isValidTransactionType(normalized) {
+		return normalized, true
+	}
+	return "", false
+}
+
+// isValidTransactionType verifica si el tipo es uno de los de Transaction
+func isValidTransactionType(value string) bool {
+	switch value {
+	case models.TransactionBuy, models.TransactionSell, models.TransactionTransferIn, models.TransactionTransferOut:
+		return true
+	}
+	return false
+}
+
+// Formatos predefinidos de brokers argentinos y estadounidenses. Los encabezados siguen los
+// reportes de operaciones de cada broker; para otros formatos se envía un mapeo propio.
+var presets = map[string]Mapping{
+	"generic": {
+		Name:        "generic",
+		DateLayouts: []string{"2006-01-02", "2006-01-02 15:04:05", "02/01/2006"},
+		Date:        "date",
+		Type:        "type",
+		Code:        "code",
+		AssetName:   "name",
+		Quantity:    "quantity",
+		Price:       "price",
+		Fees:        "fees",
+	},
+	"positions": {
+		Name:      "positions",
+		Code:      "code",
+		AssetName: "name",
+		Quantity:  "quantity",
+		Price:     "price",
+	},
+	"iol": {
+		Name:         "iol",
+		Delimiter:    ";",
+		DecimalComma: true,
+		DateLayouts:  []string{"02/01/2006", "02/01/2006 15:04:05", "02/01/2006 15:04"},
+		Date:         "Fecha Operación",
+		Type:         "Tipo Operación",
+		Code:         "Símbolo",
+		AssetName:    "Descripción",
+		Quantity:     "Cantidad",
+		Price:        "Precio",
+		Fees:         "Comisión",
+		TypeAliases:  map[string]string{"Compra": models.TransactionBuy, "Venta": models.TransactionSell},
+	},
+	"balanz": {
+		Name:         "balanz",
+		Delimiter:    ";",
+		DecimalComma: true,
+		DateLayouts:  []string{"02/01/2006", "2006-01-02"},
+		Date:         "Fecha",
+		Type:         "Operación",
+		Code:         "Ticker",
+		AssetName:    "Especie",
+		Quantity:     "Cantidad",
+		Price:        "Precio",
+		Fees:         "Gastos",
+		TypeAliases: map[string]string{
+			"Compra":            models.TransactionBuy,
+			"Venta":             models.TransactionSell,
+			"Ingreso de Título": models.TransactionTransferIn,
+			"Egreso de Título":  models.TransactionTransferOut,
+		},
+	},
+	"schwab": {
+		Name:         "schwab",
+		DateLayouts:  []string{"01/02/2006"},
+		Date:         "Date",
+		Type:         "Action",
+		Code:         "Symbol",
+		AssetName:    "Description",
+		Quantity:     "Quantity",
+		Price:        "Price",
+		Fees:         "Fees & Comm",
+		SkipPrefixes: []string{"Transactions Total"},
+		TypeAliases: map[string]string{
+			"Buy":               models.TransactionBuy,
+			"Sell":              models.TransactionSell,
+			"Reinvest Shares":   models.TransactionBuy,
+			"Security Transfer": models.TransactionTransferIn,
+			"Journaled Shares":  models.TransactionTransferIn,
+		},
+	},
+	"ibkr": {
+		Name:        "ibkr",
+		DateLayouts: []string{"2006-01-02, 15:04:05", "2006-01-02"},
+		Date:        "Date/Time",
+		Code:        "Symbol",
+		Quantity:    "Quantity", // Negativa en las ventas
+		Price:       "T. Price",
+		Fees:        "Comm/Fee",
+	},
+}
+
+// Preset retorna una copia del formato predefinido de un broker
+func Preset(name string) (Mapping, bool) {
+	mapping, ok := presets[strings.ToLower(name)]
+	return mapping, ok
+}
+
+// PresetNames retorna los nombres de los formatos predefinidos
+func PresetNames() []string {
+	return []string{"generic", "positions", "iol", "balanz", "schwab", "ibkr"}
+}
@@ -0,0 +1,266 @@
+package brokerimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+// Line es una fila del CSV ya interpretada. Sin fecha es una posición (cantidad total del holding).
+type Line struct {
+	Number    int       `json:"line"` // Número de línea en el archivo (el encabezado es la 1)
+	Code      string    `json:"code"`
+	AssetName string    `json:"assetName,omitempty"`
+	Date      time.Time `json:"date,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Fees      float64   `json:"fees"`
+}
+
+// IsPosition indica si la línea es una posición en lugar de una transacción
+func (l *Line) IsPosition() bool {
+	return l.Type == ""
+}
+
+// LineError es un problema en una línea del archivo
+type LineError struct {
+	Line    int    `json:"line"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result es el resultado de interpretar un archivo
+type Result struct {
+	Lines   []Line      `json:"lines"`
+	Errors  []LineError `json:"errors"`  // Filas que no se pudieron interpretar
+	Skipped []LineError `json:"skipped"` // Filas ignoradas (operaciones que no son movimientos de títulos)
+}
+
+// Parse interpreta un CSV según el mapeo. Los errores de una fila no detienen la lectura.
+func Parse(r io.Reader, mapping Mapping) (*Result, error) {
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = []rune(mapping.delimiter())[0]
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo encabezado: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[normalizeHeader(name)] = i
+	}
+
+	column := func(name string, required bool) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := index[normalizeHeader(name)]
+		if !ok {
+			if required {
+				return -1, fmt.Errorf("el archivo no tiene la columna %q", name)
+			}
+			return -1, nil
+		}
+		return i, nil
+	}
+
+	codeCol, err := column(mapping.Code, true)
+	if err != nil {
+		return nil, err
+	}
+	quantityCol, err := column(mapping.Quantity, true)
+	if err != nil {
+		return nil, err
+	}
+	dateCol, err := column(mapping.Date, true)
+	if err != nil {
+		return nil, err
+	}
+	typeCol, err := column(mapping.Type, true)
+	if err != nil {
+		return nil, err
+	}
+	nameCol, _ := column(mapping.AssetName, false)
+	priceCol, _ := column(mapping.Price, false)
+	feesCol, _ := column(mapping.Fees, false)
+
+	result := &Result{Lines: []Line{}, Errors: []LineError{}, Skipped: []LineError{}}
+	number := 1
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		number++
+		if err != nil {
+			result.Errors = append(result.Errors, LineError{Line: number, Message: err.Error()})
+			continue
+		}
+
+		if isBlank(record) || hasSkipPrefix(record, mapping.SkipPrefixes) {
+			continue
+		}
+
+		cell := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		line := Line{
+			Number:    number,
+			Code:      strings.ToUpper(cell(codeCol)),
+			AssetName: cell(nameCol),
+		}
+
+		lineErr := func(format string, args ...interface{}) {
+			result.Errors = append(result.Errors, LineError{Line: number, Code: line.Code, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if line.Code == "" {
+			lineErr("código vacío")
+			continue
+		}
+
+		quantity, err := parseNumber(cell(quantityCol), mapping.DecimalComma)
+		if err != nil {
+			lineErr("cantidad inválida: %v", err)
+			continue
+		}
+
+		if line.Price, err = parseOptionalNumber(cell(priceCol), mapping.DecimalComma); err != nil {
+			lineErr("precio inválido: %v", err)
+			continue
+		}
+		line.Price = math.Abs(line.Price)
+
+		if line.Fees, err = parseOptionalNumber(cell(feesCol), mapping.DecimalComma); err != nil {
+			lineErr("comisión inválida: %v", err)
+			continue
+		}
+		line.Fees = math.Abs(line.Fees) // Algunos brokers informan las comisiones como importe negativo
+
+		if mapping.HasTransactions() {
+			if line.Date, err = parseDate(cell(dateCol), mapping.DateLayouts); err != nil {
+				lineErr("%v", err)
+				continue
+			}
+
+			if typeCol >= 0 {
+				transactionType, ok := mapping.resolveType(cell(typeCol))
+				if !ok {
+					result.Skipped = append(result.Skipped, LineError{Line: number, Code: line.Code,
+						Message: fmt.Sprintf("operación %q no es un movimiento de títulos", cell(typeCol))})
+					continue
+				}
+				line.Type = transactionType
+			} else if quantity < 0 {
+				line.Type = models.TransactionSell
+			} else {
+				line.Type = models.TransactionBuy
+			}
+		}
+
+		line.Quantity = math.Abs(quantity)
+		if line.Quantity == 0 {
+			lineErr("la cantidad debe ser distinta de 0")
+			continue
+		}
+
+		result.Lines = append(result.Lines, line)
+	}
+
+	return result, nil
+}
+
+// normalizeHeader normaliza un encabezado para compararlo (sin BOM, espacios ni mayúsculas)
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+// isBlank indica si todas las celdas de la fila están vacías
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSkipPrefix indica si la primera celda empieza con alguno de los prefijos a ignorar
+func hasSkipPrefix(record []string, prefixes []string) bool {
+	if len(record) == 0 {
+		return false
+	}
+	first := strings.TrimSpace(record[0])
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(first, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate prueba los layouts del mapeo en orden
+func parseDate(value string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha inválida: %q", value)
+}
+
+// parseOptionalNumber interpreta un número que puede estar vacío (vale 0)
+func parseOptionalNumber(value string, decimalComma bool) (float64, error) {
+	if value == "" || value == "-" {
+		return 0, nil
+	}
+	return parseNumber(value, decimalComma)
+}
+
+// parseNumber interpreta importes con símbolos de moneda, separadores de miles y negativos entre paréntesis
+func parseNumber(value string, decimalComma bool) (float64, error) {
+	cleaned := strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")") {
+		negative = true
+		cleaned = cleaned[1 : len(cleaned)-1]
+	}
+
+	cleaned = strings.NewReplacer("US$", "", "u$s", "", "$", "", " ", "", "\u00a0", "").Replace(cleaned)
+	if decimalComma {
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	} else {
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	number, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q no es un número", value)
+	}
+	if negative {
+		number = -number
+	}
+	return number, nil
+}
@@ -0,0 +1,210 @@
+package brokerimport
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+const tolerance = 1e-9
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		decimalComma bool
+		want         float64
+	}{
+		{name: "entero", value: "100", want: 100},
+		{name: "separador de miles", value: "1,234.56", want: 1234.56},
+		{name: "negativo", value: "-15.5", want: -15.5},
+		{name: "negativo entre paréntesis", value: "(1,234.56)", want: -1234.56},
+		{name: "prefijo de dólar", value: "$1,234.56", want: 1234.56},
+		{name: "prefijo US$", value: "US$ 250.10", want: 250.1},
+		{name: "prefijo u$s", value: "u$s 99.9", want: 99.9},
+		{name: "coma decimal", value: "1.234,56", decimalComma: true, want: 1234.56},
+		{name: "coma decimal con pesos", value: "$ 1.234.567,89", decimalComma: true, want: 1234567.89},
+		{name: "coma decimal entre paréntesis", value: "($ 12,50)", decimalComma: true, want: -12.5},
+		{name: "coma decimal negativo", value: "-0,75", decimalComma: true, want: -0.75},
+		{name: "coma decimal sin miles", value: "150", decimalComma: true, want: 150},
+		{name: "espacio no separable", value: "1.000,5 ", decimalComma: true, want: 1000.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNumber(tt.value, tt.decimalComma)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if math.Abs(got-tt.want) > tolerance {
+				t.Errorf("parseNumber(%q) = %v, se esperaba %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumberErrors(t *testing.T) {
+	for _, value := range []string{"", "abc", "12a", "$"} {
+		if _, err := parseNumber(value, false); err == nil {
+			t.Errorf("parseNumber(%q) debería fallar", value)
+		}
+	}
+}
+
+func TestParsePresets(t *testing.T) {
+	tests := []struct {
+		preset string
+		csv    string
+		want   []Line
+	}{
+		{
+			preset: "generic",
+			csv: "date,type,code,name,quantity,price,fees\n" +
+				"2024-01-02,buy,aapl,Apple,10,185.5,1\n" +
+				"2024-02-01 10:30:00,sell,AAPL,Apple,4,190,0.5\n",
+			want: []Line{
+				{Number: 2, Code: "AAPL", AssetName: "Apple", Date: date(2024, 1, 2), Type: models.TransactionBuy, Quantity: 10, Price: 185.5, Fees: 1},
+				{Number: 3, Code: "AAPL", AssetName: "Apple", Date: time.Date(2024, 2, 1, 10, 30, 0, 0, time.UTC), Type: models.TransactionSell, Quantity: 4, Price: 190, Fees: 0.5},
+			},
+		},
+		{
+			preset: "positions",
+			csv: "code,name,quantity,price\n" +
+				"GGAL,Galicia,\"1,500\",\n" +
+				"YPF,YPF,20,30.25\n",
+			want: []Line{
+				{Number: 2, Code: "GGAL", AssetName: "Galicia", Quantity: 1500},
+				{Number: 3, Code: "YPF", AssetName: "YPF", Quantity: 20, Price: 30.25},
+			},
+		},
+		{
+			// Formato argentino: punto de miles, coma decimal y punto y coma como separador
+			preset: "iol",
+			csv: "Fecha Operación;Tipo Operación;Símbolo;Descripción;Cantidad;Precio;Comisión\n" +
+				"15/03/2024;Compra;GGAL;Grupo Galicia;1.000;$ 2.345,50;-1.234,56\n" +
+				"20/03/2024 14:05;Venta;ggal;Grupo Galicia;250;$ 2.500,00;(312,5)\n",
+			want: []Line{
+				{Number: 2, Code: "GGAL", AssetName: "Grupo Galicia", Date: date(2024, 3, 15), Type: models.TransactionBuy, Quantity: 1000, Price: 2345.5, Fees: 1234.56},
+				{Number: 3, Code: "GGAL", AssetName: "Grupo Galicia", Date: time.Date(2024, 3, 20, 14, 5, 0, 0, time.UTC), Type: models.TransactionSell, Quantity: 250, Price: 2500, Fees: 312.5},
+			},
+		},
+		{
+			preset: "balanz",
+			csv: "Fecha;Operación;Ticker;Especie;Cantidad;Precio;Gastos\n" +
+				"02/01/2024;Ingreso de Título;AL30;Bono AL30;10.000;0;\n" +
+				"2024-01-05;Egreso de Título;AL30;Bono AL30;2.500;-;-\n" +
+				"08/01/2024;Venta;AL30;Bono AL30;1.000;u$s 51,25;0,35\n",
+			want: []Line{
+				{Number: 2, Code: "AL30", AssetName: "Bono AL30", Date: date(2024, 1, 2), Type: models.TransactionTransferIn, Quantity: 10000},
+				{Number: 3, Code: "AL30", AssetName: "Bono AL30", Date: date(2024, 1, 5), Type: models.TransactionTransferOut, Quantity: 2500},
+				{Number: 4, Code: "AL30", AssetName: "Bono AL30", Date: date(2024, 1, 8), Type: models.TransactionSell, Quantity: 1000, Price: 51.25, Fees: 0.35},
+			},
+		},
+		{
+			preset: "schwab",
+			csv: "Date,Action,Symbol,Description,Quantity,Price,Fees & Comm,Amount\n" +
+				"03/15/2024,Buy,MSFT,MICROSOFT CORP,\"1,200\",$415.10,$0.65,\"-$498,120.65\"\n" +
+				"03/18/2024,Reinvest Shares,MSFT,MICROSOFT CORP,0.5,$420.00,,-$210.00\n" +
+				"03/19/2024,Sell,MSFT,MICROSOFT CORP,100,$425.00,($1.00),\"$42,499.00\"\n" +
+				"Transactions Total,,,,,,,\"-$455,831.65\"\n",
+			want: []Line{
+				{Number: 2, Code: "MSFT", AssetName: "MICROSOFT CORP", Date: date(2024, 3, 15), Type: models.TransactionBuy, Quantity: 1200, Price: 415.1, Fees: 0.65},
+				{Number: 3, Code: "MSFT", AssetName: "MICROSOFT CORP", Date: date(2024, 3, 18), Type: models.TransactionBuy, Quantity: 0.5, Price: 420},
+				{Number: 4, Code: "MSFT", AssetName: "MICROSOFT CORP", Date: date(2024, 3, 19), Type: models.TransactionSell, Quantity: 100, Price: 425, Fees: 1},
+			},
+		},
+		{
+			// Sin columna de tipo: el signo de la cantidad indica compra o venta
+			preset: "ibkr",
+			csv: "Symbol,Date/Time,Quantity,T. Price,Comm/Fee\n" +
+				"SPY,\"2024-04-01, 09:31:02\",25,520.15,-1.25\n" +
+				"SPY,2024-04-10,-10,515.5,-1\n",
+			want: []Line{
+				{Number: 2, Code: "SPY", Date: time.Date(2024, 4, 1, 9, 31, 2, 0, time.UTC), Type: models.TransactionBuy, Quantity: 25, Price: 520.15, Fees: 1.25},
+				{Number: 3, Code: "SPY", Date: date(2024, 4, 10), Type: models.TransactionSell, Quantity: 10, Price: 515.5, Fees: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			mapping, ok := Preset(tt.preset)
+			if !ok {
+				t.Fatalf("no existe el formato %s", tt.preset)
+			}
+
+			result, err := Parse(strings.NewReader(tt.csv), mapping)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("errores inesperados: %+v", result.Errors)
+			}
+			if len(result.Lines) != len(tt.want) {
+				t.Fatalf("lines = %d, se esperaban %d", len(result.Lines), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				got := result.Lines[i]
+				if got.Number != want.Number || got.Code != want.Code || got.AssetName != want.AssetName ||
+					got.Type != want.Type || !got.Date.Equal(want.Date) {
+					t.Errorf("línea %d = %+v, se esperaba %+v", i, got, want)
+				}
+				if math.Abs(got.Quantity-want.Quantity) > tolerance ||
+					math.Abs(got.Price-want.Price) > tolerance ||
+					math.Abs(got.Fees-want.Fees) > tolerance {
+					t.Errorf("línea %d: cantidad %v, precio %v, comisión %v; se esperaban %v, %v, %v",
+						i, got.Quantity, got.Price, got.Fees, want.Quantity, want.Price, want.Fees)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLineErrors(t *testing.T) {
+	mapping, _ := Preset("iol")
+	csv := "Fecha Operación;Tipo Operación;Símbolo;Descripción;Cantidad;Precio;Comisión\n" +
+		"15/03/2024;Compra;;Sin código;10;100;0\n" +
+		"15/03/2024;Compra;GGAL;Grupo Galicia;diez;100;0\n" +
+		"2024-03-15;Compra;GGAL;Grupo Galicia;10;100;0\n" +
+		"15/03/2024;Compra;GGAL;Grupo Galicia;0;100;0\n" +
+		"15/03/2024;Dividendo;GGAL;Grupo Galicia;10;100;0\n" +
+		";;;;;;\n" +
+		"16/03/2024;Compra;GGAL;Grupo Galicia;5;100;0\n"
+
+	result, err := Parse(strings.NewReader(csv), mapping)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	wantErrors := []int{2, 3, 4, 5}
+	if len(result.Errors) != len(wantErrors) {
+		t.Fatalf("errores = %+v, se esperaban en las líneas %v", result.Errors, wantErrors)
+	}
+	for i, line := range wantErrors {
+		if result.Errors[i].Line != line {
+			t.Errorf("error %d en la línea %d, se esperaba %d", i, result.Errors[i].Line, line)
+		}
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Line != 6 {
+		t.Errorf("skipped = %+v, se esperaba la línea 6", result.Skipped)
+	}
+	if len(result.Lines) != 1 || result.Lines[0].Number != 8 {
+		t.Errorf("lines = %+v, se esperaba solo la línea 8", result.Lines)
+	}
+}
+
+func TestParseMissingColumn(t *testing.T) {
+	mapping, _ := Preset("generic")
+	if _, err := Parse(strings.NewReader("date,code,quantity\n2024-01-02,AAPL,1\n"), mapping); err == nil {
+		t.Fatal("se esperaba un error por la columna de tipo faltante")
+	}
+}
@@ -3,17 +3,80 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DatabaseURL       string
-	RedisURL          string
-	Port              string
-	Environment       string
-	APIKey            string
+	DatabaseURL          string
+	RedisURL             string
+	Port                 string
+	Environment          string
+	APIKey               string
 	ScrapingCronSchedule string
+
+	// Webhooks salientes
+	WebhookURLs            []string
+	WebhookSecret          string
+	WebhookEvents          []string
+	WebhookMaxRetries      int
+	WebhookTimeoutSeconds  int
+	WebhookPriceAnomalyPct float64
+
+	// Envío de emails (resumen semanal)
+	SMTPHost                  string
+	SMTPPort                  int
+	SMTPUsername              string
+	SMTPPassword              string
+	SMTPFrom                  string
+	WeeklySummaryEnabled      bool
+	WeeklySummaryDefaultOptIn bool
+
+	// Método de costo para P&L (average o fifo)
+	PnLCostMethod string
+
+	// Tipos de cambio
+	FXEnabled        bool
+	FXProviderURL    string
+	FXDefaultVariant string
+
+	// Analytics
+	RiskFreeRate           float64
+	AnalyticsCacheTTLHours int
+
+	// Backfill de precios históricos
+	BackfillProviderURL string
+
+	// Retención y resumen de snapshots antiguos
+	RetentionEnabled            bool
+	RetentionCronSchedule       string
+	RetentionFullResolutionDays int
+	RetentionMonthlyDays        int
+
+	// Validación de holdings en lote
+	ValidationBatchMaxCodes    int
+	ValidationBatchConcurrency int
+	ScrapingMinIntervalMs      int
+
+	// Cache de validaciones en Redis
+	ValidationCacheValidTTLHours   int
+	ValidationCacheInvalidTTLHours int
+
+	// Cotizaciones
+	QuoteCacheTTLs              []string
+	QuoteCacheDefaultTTLMinutes int
+
+	// Búsqueda de símbolos
+	SymbolSearchProviderURL     string
+	SymbolSearchCacheTTLMinutes int
+
+	// Sufijo de mercado de cada tipo de inversión en el proveedor, ej. "Cedears=.BA"
+	SymbolProviderSuffixes []string
+
+	// Migración del índice único de Asset a (typeId, UPPER(code))
+	AssetTypeCodeIndexEnabled bool
 }
 
 var AppConfig *Config
@@ -27,11 +90,59 @@ func LoadConfig() *Config {
 
 	config := &Config{
 		DatabaseURL:          getEnv("DATABASE_URL", ""),
-		RedisURL:            getEnv("REDIS_URL", "redis://localhost:6379"),
-		Port:                getEnv("PORT", "8080"),
-		Environment:         getEnv("ENV", "development"),
-		APIKey:              getEnv("SNAPSHOT_SERVICE_API_KEY", ""),
+		RedisURL:             getEnv("REDIS_URL", "redis://localhost:6379"),
+		Port:                 getEnv("PORT", "8080"),
+		Environment:          getEnv("ENV", "development"),
+		APIKey:               getEnv("SNAPSHOT_SERVICE_API_KEY", ""),
 		ScrapingCronSchedule: getEnv("SCRAPING_CRON_SCHEDULE", "0 1 * * 0"), // Domingos 1:00 AM
+
+		WebhookURLs:            getEnvList("WEBHOOK_URLS"),
+		WebhookSecret:          getEnv("WEBHOOK_SECRET", ""),
+		WebhookEvents:          getEnvList("WEBHOOK_EVENTS"), // Vacío = todos los eventos
+		WebhookMaxRetries:      getEnvInt("WEBHOOK_MAX_RETRIES", 5),
+		WebhookTimeoutSeconds:  getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookPriceAnomalyPct: getEnvFloat("WEBHOOK_PRICE_ANOMALY_PCT", 25),
+
+		SMTPHost:                  getEnv("SMTP_HOST", ""),
+		SMTPPort:                  getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:              getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:              getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                  getEnv("SMTP_FROM", "Holding Snapshots <no-reply@holding-snapshots.local>"),
+		WeeklySummaryEnabled:      getEnvBool("WEEKLY_SUMMARY_ENABLED", false),
+		WeeklySummaryDefaultOptIn: getEnvBool("WEEKLY_SUMMARY_DEFAULT_OPT_IN", false), // Usuarios sin preferencia guardada
+
+		PnLCostMethod: getEnv("PNL_COST_METHOD", "average"),
+
+		FXEnabled:        getEnvBool("FX_ENABLED", true),
+		FXProviderURL:    getEnv("FX_PROVIDER_URL", "https://dolarapi.com/v1/dolares"),
+		FXDefaultVariant: getEnv("FX_DEFAULT_VARIANT", "mep"),
+
+		RiskFreeRate:           getEnvFloat("RISK_FREE_RATE", 0), // Tasa anual en %
+		AnalyticsCacheTTLHours: getEnvInt("ANALYTICS_CACHE_TTL_HOURS", 168),
+
+		BackfillProviderURL: getEnv("BACKFILL_PROVIDER_URL", "https://query1.finance.yahoo.com/v8/finance/chart"),
+
+		RetentionEnabled:            getEnvBool("RETENTION_ENABLED", false),
+		RetentionCronSchedule:       getEnv("RETENTION_CRON_SCHEDULE", "0 5 * * 0"), // Domingos 5:00 AM, después del scraping
+		RetentionFullResolutionDays: getEnvInt("RETENTION_FULL_RESOLUTION_DAYS", 365),
+		RetentionMonthlyDays:        getEnvInt("RETENTION_MONTHLY_DAYS", 1095),
+
+		ValidationBatchMaxCodes:    getEnvInt("VALIDATION_BATCH_MAX_CODES", 50),
+		ValidationBatchConcurrency: getEnvInt("VALIDATION_BATCH_CONCURRENCY", 4),
+		ScrapingMinIntervalMs:      getEnvInt("SCRAPING_MIN_INTERVAL_MS", 200), // Pausa mínima entre requests al sitio de scraping
+
+		ValidationCacheValidTTLHours:   getEnvInt("VALIDATION_CACHE_VALID_TTL_HOURS", 24),
+		ValidationCacheInvalidTTLHours: getEnvInt("VALIDATION_CACHE_INVALID_TTL_HOURS", 2),
+
+		QuoteCacheTTLs:              getEnvList("QUOTE_CACHE_TTLS"), // Ej: "Criptomonedas=3m,Acciones=15m"
+		QuoteCacheDefaultTTLMinutes: getEnvInt("QUOTE_CACHE_DEFAULT_TTL_MINUTES", 15),
+
+		SymbolSearchProviderURL:     getEnv("SYMBOL_SEARCH_PROVIDER_URL", "https://query1.finance.yahoo.com/v1/finance/search"),
+		SymbolSearchCacheTTLMinutes: getEnvInt("SYMBOL_SEARCH_CACHE_TTL_MINUTES", 60),
+
+		SymbolProviderSuffixes: getEnvList("SYMBOL_PROVIDER_SUFFIXES"),
+
+		AssetTypeCodeIndexEnabled: getEnvBool("ASSET_TYPE_CODE_INDEX_ENABLED", false),
 	}
 
 	if config.DatabaseURL == "" {
@@ -42,6 +153,14 @@ func LoadConfig() *Config {
 		log.Fatal("SNAPSHOT_SERVICE_API_KEY es requerido")
 	}
 
+	if config.WeeklySummaryEnabled && config.SMTPHost == "" {
+		log.Println("⚠️ WEEKLY_SUMMARY_ENABLED sin SMTP_HOST, no se enviarán resúmenes semanales")
+	}
+
+	if len(config.WebhookURLs) > 0 && config.WebhookSecret == "" {
+		log.Fatal("WEBHOOK_SECRET es requerido cuando WEBHOOK_URLS está configurado")
+	}
+
 	AppConfig = config
 	return config
 }
@@ -52,4 +171,60 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getEnvInt obtiene una variable de entorno entera con un valor por defecto
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido para %s (%s), usando %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloat obtiene una variable de entorno decimal con un valor por defecto
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido para %s (%s), usando %.2f", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvBool obtiene una variable de entorno booleana con un valor por defecto
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️ Valor inválido para %s (%s), usando %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvList obtiene una variable de entorno separada por comas como slice
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AlertController struct {
+	alertService *services.AlertService
+}
+
+// NewAlertController crea una nueva instancia del controlador de alertas
+func NewAlertController() *AlertController {
+	return &AlertController{
+		alertService: services.NewAlertService(),
+	}
+}
+
+// CreateAlertRequest representa la request de creación de una regla de alerta
+type CreateAlertRequest struct {
+	AssetID   *string `json:"assetId,omitempty"`
+	HoldingID *string `json:"holdingId,omitempty"`
+	Type      string  `json:"type"`
+	Threshold float64 `json:"threshold"`
+	Channel   string  `json:"channel,omitempty"`
+}
+
+// UpdateAlertRequest representa la request de actualización de una regla de alerta
+type UpdateAlertRequest struct {
+	IsActive bool `json:"isActive"`
+}
+
+// GetUserAlerts lista las reglas de alerta de un usuario
+// GET /api/users/:id/alerts
+func (ac *AlertController) GetUserAlerts(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	rules, err := ac.alertService.GetUserRules(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Reglas de alerta obtenidas exitosamente", rules)
+}
+
+// CreateUserAlert crea una regla de alerta para un usuario
+// POST /api/users/:id/alerts
+func (ac *AlertController) CreateUserAlert(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	var req CreateAlertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if (req.AssetID != nil && !utils.IsValidUUID(*req.AssetID)) ||
+		(req.HoldingID != nil && !utils.IsValidUUID(*req.HoldingID)) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "assetId u holdingId inválido")
+	}
+
+	rule := &models.AlertRule{
+		UserID:    userID,
+		AssetID:   req.AssetID,
+		HoldingID: req.HoldingID,
+		Type:      req.Type,
+		Threshold: req.Threshold,
+		Channel:   req.Channel,
+	}
+
+	if err := ac.alertService.CreateRule(rule); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Regla de alerta creada exitosamente", rule)
+}
+
+// GetUserTriggeredAlerts lista las alertas disparadas de un usuario
+// GET /api/users/:id/alerts/triggered?limit=50
+func (ac *AlertController) GetUserTriggeredAlerts(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	triggers, err := ac.alertService.GetUserTriggers(userID, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Alertas disparadas obtenidas exitosamente", triggers)
+}
+
+// UpdateAlert activa o desactiva una regla de alerta
+// PATCH /api/alerts/:id
+func (ac *AlertController) UpdateAlert(c *fiber.Ctx) error {
+	ruleID := c.Params("id")
+	if !utils.IsValidUUID(ruleID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de alerta inválido")
+	}
+
+	var req UpdateAlertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	rule, err := ac.alertService.SetRuleActive(ruleID, req.IsActive)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Regla de alerta actualizada exitosamente", rule)
+}
+
+// DeleteAlert elimina una regla de alerta
+// DELETE /api/alerts/:id
+func (ac *AlertController) DeleteAlert(c *fiber.Ctx) error {
+	ruleID := c.Params("id")
+	if !utils.IsValidUUID(ruleID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de alerta inválido")
+	}
+
+	if err := ac.alertService.DeleteRule(ruleID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Regla de alerta eliminada exitosamente", fiber.Map{
+		"id": ruleID,
+	})
+}
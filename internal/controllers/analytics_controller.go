@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnalyticsController struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsController crea una nueva instancia del controlador de analytics
+func NewAnalyticsController() *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: services.NewAnalyticsService(),
+	}
+}
+
+// GetHoldingRisk obtiene las métricas de riesgo de un holding
+// GET /api/analytics/holdings/:id/risk?from=2024-01-01&to=2024-12-31
+func (ac *AnalyticsController) GetHoldingRisk(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	metrics, err := ac.analyticsService.GetHoldingRisk(holdingID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Métricas de riesgo del holding obtenidas exitosamente", metrics)
+}
+
+// GetGroupRisk obtiene las métricas de riesgo de un grupo
+// GET /api/analytics/groups/:id/risk?from=2024-01-01&currency=USD
+func (ac *AnalyticsController) GetGroupRisk(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	metrics, err := ac.analyticsService.GetGroupRisk(groupID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Métricas de riesgo del grupo obtenidas exitosamente", metrics)
+}
+
+// GetUserRisk obtiene las métricas de riesgo de todos los grupos de un usuario
+// GET /api/analytics/users/:id/risk?currency=USD&fxVariant=mep
+func (ac *AnalyticsController) GetUserRisk(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	metrics, err := ac.analyticsService.GetUserRisk(userID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Métricas de riesgo del usuario obtenidas exitosamente", metrics)
+}
+
+// GetGroupBenchmark compara el rendimiento de un grupo con su benchmark
+// GET /api/analytics/groups/:id/benchmark?from=2024-01-01&assetId=...
+func (ac *AnalyticsController) GetGroupBenchmark(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	assetID := c.Query("assetId")
+	if assetID != "" && !utils.IsValidUUID(assetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	comparison, err := ac.analyticsService.GetGroupBenchmarkComparison(groupID, assetID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Comparación contra benchmark obtenida exitosamente", comparison)
+}
+
+// GetUserBenchmark compara el rendimiento de los grupos de un usuario con su benchmark
+// GET /api/analytics/users/:id/benchmark?currency=USD
+func (ac *AnalyticsController) GetUserBenchmark(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	assetID := c.Query("assetId")
+	if assetID != "" && !utils.IsValidUUID(assetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	comparison, err := ac.analyticsService.GetUserBenchmarkComparison(userID, assetID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Comparación contra benchmark obtenida exitosamente", comparison)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AssetController struct {
+	assetPriceService *services.AssetPriceService
+}
+
+// NewAssetController crea una nueva instancia del controlador de assets
+func NewAssetController() *AssetController {
+	return &AssetController{
+		assetPriceService: services.NewAssetPriceService(),
+	}
+}
+
+// GetAssetPrices obtiene el historial de precios diarios de un asset, tenga o no holdings
+// GET /api/assets/:id/prices?from=2024-01-01&to=2024-06-30
+func (ac *AssetController) GetAssetPrices(c *fiber.Ctx) error {
+	assetID := c.Params("id")
+	if !utils.IsValidUUID(assetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	history, err := ac.assetPriceService.GetHistory(assetID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Historial de precios obtenido exitosamente", history)
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"time"
+
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BackfillController struct {
+	backfillService *services.BackfillService
+}
+
+// NewBackfillController crea una nueva instancia del controlador de backfill de precios
+func NewBackfillController() *BackfillController {
+	return &BackfillController{
+		backfillService: services.NewBackfillService(),
+	}
+}
+
+// CreateBackfillRequest representa la request de carga de precios históricos
+type CreateBackfillRequest struct {
+	AssetID  string `json:"assetId"`
+	Symbol   string `json:"symbol,omitempty"`   // Por defecto el código del asset
+	Interval string `json:"interval,omitempty"` // day (por defecto) o week
+	From     string `json:"from"`               // YYYY-MM-DD o RFC3339
+	To       string `json:"to,omitempty"`       // Por defecto ahora
+}
+
+// CreateBackfill registra un backfill y lo ejecuta en segundo plano
+// POST /api/admin/backfill
+func (bc *BackfillController) CreateBackfill(c *fiber.Ctx) error {
+	var req CreateBackfillRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if !utils.IsValidUUID(req.AssetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(req.From, req.To)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	backfill := services.BackfillRequest{
+		AssetID:  req.AssetID,
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+	}
+	if from != nil {
+		backfill.From = *from
+	}
+	if to != nil {
+		backfill.To = *to
+	}
+	if backfill.To.After(time.Now()) {
+		backfill.To = time.Now()
+	}
+
+	job, err := bc.backfillService.CreateJob(backfill)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	bc.backfillService.StartJob(job)
+
+	return utils.SuccessResponse(c, "Backfill iniciado en segundo plano", job)
+}
+
+// GetBackfills lista los backfills más recientes
+// GET /api/admin/backfill?assetId=...&status=failed&limit=50
+func (bc *BackfillController) GetBackfills(c *fiber.Ctx) error {
+	limit := utils.ClampLimit(c.QueryInt("limit"), 50, 500)
+
+	jobs, err := bc.backfillService.GetJobs(c.Query("assetId"), c.Query("status"), limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Backfills obtenidos exitosamente", jobs)
+}
+
+// GetBackfill obtiene el progreso de un backfill
+// GET /api/admin/backfill/:id
+func (bc *BackfillController) GetBackfill(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de backfill inválido")
+	}
+
+	job, err := bc.backfillService.GetJob(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Backfill obtenido exitosamente", job)
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BenchmarkController struct {
+	benchmarkService *services.BenchmarkService
+}
+
+// NewBenchmarkController crea una nueva instancia del controlador de benchmarks
+func NewBenchmarkController() *BenchmarkController {
+	return &BenchmarkController{
+		benchmarkService: services.NewBenchmarkService(),
+	}
+}
+
+// SetBenchmarkRequest representa la request de asignación de benchmark
+type SetBenchmarkRequest struct {
+	AssetID string `json:"assetId"`
+}
+
+// GetGroupBenchmark obtiene el benchmark de un grupo (propio o heredado de su usuario)
+// GET /api/groups/:id/benchmark
+func (bc *BenchmarkController) GetGroupBenchmark(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	assignment, err := bc.benchmarkService.GetGroupBenchmark(groupID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Benchmark del grupo obtenido exitosamente", assignment)
+}
+
+// SetGroupBenchmark asigna el benchmark de un grupo
+// PUT /api/groups/:id/benchmark
+func (bc *BenchmarkController) SetGroupBenchmark(c *fiber.Ctx) error {
+	return bc.setBenchmark(c, models.BenchmarkScopeGroup, "ID de grupo inválido")
+}
+
+// DeleteGroupBenchmark quita el benchmark propio de un grupo
+// DELETE /api/groups/:id/benchmark
+func (bc *BenchmarkController) DeleteGroupBenchmark(c *fiber.Ctx) error {
+	return bc.deleteBenchmark(c, models.BenchmarkScopeGroup, "ID de grupo inválido")
+}
+
+// GetUserBenchmark obtiene el benchmark de un usuario
+// GET /api/users/:id/benchmark
+func (bc *BenchmarkController) GetUserBenchmark(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	assignment, err := bc.benchmarkService.GetUserBenchmark(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Benchmark del usuario obtenido exitosamente", assignment)
+}
+
+// SetUserBenchmark asigna el benchmark de un usuario, usado también por sus grupos sin benchmark propio
+// PUT /api/users/:id/benchmark
+func (bc *BenchmarkController) SetUserBenchmark(c *fiber.Ctx) error {
+	return bc.setBenchmark(c, models.BenchmarkScopeUser, "ID de usuario inválido")
+}
+
+// DeleteUserBenchmark quita el benchmark de un usuario
+// DELETE /api/users/:id/benchmark
+func (bc *BenchmarkController) DeleteUserBenchmark(c *fiber.Ctx) error {
+	return bc.deleteBenchmark(c, models.BenchmarkScopeUser, "ID de usuario inválido")
+}
+
+// setBenchmark valida la request y asigna el benchmark del alcance indicado
+func (bc *BenchmarkController) setBenchmark(c *fiber.Ctx, scope, invalidIDMessage string) error {
+	ownerID := c.Params("id")
+	if !utils.IsValidUUID(ownerID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, invalidIDMessage)
+	}
+
+	var req SetBenchmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if !utils.IsValidUUID(req.AssetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	assignment, err := bc.benchmarkService.SetBenchmark(scope, ownerID, req.AssetID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Benchmark asignado exitosamente", assignment)
+}
+
+// deleteBenchmark quita el benchmark del alcance indicado
+func (bc *BenchmarkController) deleteBenchmark(c *fiber.Ctx, scope, invalidIDMessage string) error {
+	ownerID := c.Params("id")
+	if !utils.IsValidUUID(ownerID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, invalidIDMessage)
+	}
+
+	if err := bc.benchmarkService.DeleteBenchmark(scope, ownerID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Benchmark eliminado exitosamente", fiber.Map{
+		"scope":   scope,
+		"ownerId": ownerID,
+	})
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CorporateActionController struct {
+	corporateActionService *services.CorporateActionService
+}
+
+// NewCorporateActionController crea una nueva instancia del controlador de eventos corporativos
+func NewCorporateActionController() *CorporateActionController {
+	return &CorporateActionController{
+		corporateActionService: services.NewCorporateActionService(),
+	}
+}
+
+// CreateCorporateActionRequest representa la carga manual de un split o dividendo
+type CreateCorporateActionRequest struct {
+	AssetID  string  `json:"assetId"`
+	Type     string  `json:"type"`               // split o dividend
+	ExDate   string  `json:"exDate"`             // YYYY-MM-DD o RFC3339
+	Ratio    float64 `json:"ratio,omitempty"`    // Split: 4 para un 4:1
+	Amount   float64 `json:"amount,omitempty"`   // Dividendo: importe por acción
+	Currency string  `json:"currency,omitempty"` // Por defecto la del tipo de inversión
+	Apply    bool    `json:"apply,omitempty"`
+}
+
+// SyncCorporateActionsRequest representa la importación de eventos desde el proveedor
+type SyncCorporateActionsRequest struct {
+	AssetID string `json:"assetId"`
+	From    string `json:"from,omitempty"` // Por defecto un año antes de to
+	To      string `json:"to,omitempty"`   // Por defecto ahora
+	Apply   bool   `json:"apply,omitempty"`
+}
+
+// GetCorporateActions lista los eventos corporativos
+// GET /api/admin/corporate-actions?assetId=...&status=pending&limit=100
+func (cac *CorporateActionController) GetCorporateActions(c *fiber.Ctx) error {
+	limit := utils.ClampLimit(c.QueryInt("limit", 100), 100, 1000)
+
+	actions, err := cac.corporateActionService.GetActions(c.Query("assetId"), c.Query("status"), limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Eventos corporativos obtenidos exitosamente", actions)
+}
+
+// CreateCorporateAction registra un split o dividendo manual, aplicándolo si se pide
+// POST /api/admin/corporate-actions
+func (cac *CorporateActionController) CreateCorporateAction(c *fiber.Ctx) error {
+	var req CreateCorporateActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if !utils.IsValidUUID(req.AssetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	exDate, err := utils.ParseDateParam(req.ExDate)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if exDate == nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "La fecha ex es requerida")
+	}
+
+	result, err := cac.corporateActionService.Create(services.CorporateActionRequest{
+		AssetID:  req.AssetID,
+		Type:     req.Type,
+		ExDate:   *exDate,
+		Ratio:    req.Ratio,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Apply:    req.Apply,
+	})
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Evento corporativo registrado exitosamente", result)
+}
+
+// SyncCorporateActions importa splits y dividendos del proveedor para un asset
+// POST /api/admin/corporate-actions/sync
+func (cac *CorporateActionController) SyncCorporateActions(c *fiber.Ctx) error {
+	var req SyncCorporateActionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if !utils.IsValidUUID(req.AssetID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de asset inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(req.From, req.To)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	sync := services.CorporateActionSyncRequest{AssetID: req.AssetID, Apply: req.Apply}
+	if from != nil {
+		sync.From = *from
+	}
+	if to != nil {
+		sync.To = *to
+	}
+
+	report, err := cac.corporateActionService.Sync(sync)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadGateway, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Eventos corporativos sincronizados", report)
+}
+
+// ApplyCorporateAction aplica un evento pendiente
+// POST /api/admin/corporate-actions/:id/apply
+func (cac *CorporateActionController) ApplyCorporateAction(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de evento inválido")
+	}
+
+	result, err := cac.corporateActionService.Apply(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Evento corporativo aplicado exitosamente", result)
+}
+
+// DeleteCorporateAction elimina un evento que todavía no se aplicó
+// DELETE /api/admin/corporate-actions/:id
+func (cac *CorporateActionController) DeleteCorporateAction(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de evento inválido")
+	}
+
+	if err := cac.corporateActionService.Delete(id); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Evento corporativo eliminado exitosamente", nil)
+}
+
+// GetHoldingDividends obtiene los dividendos cobrados por un holding
+// GET /api/holdings/:id/dividends?from=2024-01-01&to=2024-12-31
+func (cac *CorporateActionController) GetHoldingDividends(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	dividends, err := cac.corporateActionService.GetHoldingDividends(id, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Dividendos obtenidos exitosamente", dividends)
+}
@@ -46,6 +46,11 @@ func (cc *CronController) GetNextScheduledRun(c *fiber.Ctx) error {
 		"description":     "Se ejecuta todos los domingos a las 3:00 AM UTC",
 	}
 
+	// La retención de snapshots es un job aparte, solo si está habilitada
+	if nextRetention := cc.cronService.GetNextRetentionRun(); !nextRetention.IsZero() {
+		data["next_retention_execution"] = nextRetention.Format("2006-01-02 15:04:05 UTC")
+	}
+
 	return utils.SuccessResponse(c, "Próxima ejecución obtenida exitosamente", data)
 }
 
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EarningsController struct {
+	earningsService *services.EarningsService
+}
+
+// NewEarningsController crea una nueva instancia del controlador de recálculo de earnings
+func NewEarningsController() *EarningsController {
+	return &EarningsController{
+		earningsService: services.NewEarningsService(),
+	}
+}
+
+// RecomputeEarningsRequest representa la request de recálculo de earnings
+type RecomputeEarningsRequest struct {
+	HoldingID string `json:"holdingId,omitempty"` // Vacío para recalcular todos los holdings
+	DryRun    *bool  `json:"dryRun,omitempty"`    // Por defecto true: solo reporta diferencias
+}
+
+// RecomputeEarnings recalcula los resultados de cada snapshot y los earnings de los holdings a partir
+// del historial completo de snapshots
+// POST /api/admin/earnings/recompute
+func (ec *EarningsController) RecomputeEarnings(c *fiber.Ctx) error {
+	var req RecomputeEarningsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+		}
+	}
+
+	if req.HoldingID != "" && !utils.IsValidUUID(req.HoldingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	dryRun := true
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	report, err := ec.earningsService.Recompute(req.HoldingID, dryRun)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	message := "Earnings recalculados exitosamente"
+	if dryRun {
+		message = "Diferencias de earnings calculadas (sin guardar)"
+	}
+
+	return utils.SuccessResponse(c, message, report)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportController struct {
+	exportService *services.ExportService
+}
+
+// NewExportController crea una nueva instancia del controlador de exportaciones
+func NewExportController() *ExportController {
+	return &ExportController{
+		exportService: services.NewExportService(),
+	}
+}
+
+// ExportHolding exporta los snapshots o transacciones de un holding
+// GET /api/holdings/:id/export?dataset=snapshots&format=csv&from=&to=&columns=date,price&currency=USD&fxVariant=mep
+func (ec *ExportController) ExportHolding(c *fiber.Ctx) error {
+	return ec.export(c, services.ExportScopeHolding, "ID de holding inválido")
+}
+
+// ExportGroup exporta los snapshots o transacciones de todos los holdings de un grupo
+// GET /api/groups/:id/export?dataset=transactions&format=xlsx
+func (ec *ExportController) ExportGroup(c *fiber.Ctx) error {
+	return ec.export(c, services.ExportScopeGroup, "ID de grupo inválido")
+}
+
+// ExportUser exporta los snapshots o transacciones de todos los grupos de un usuario
+// GET /api/users/:id/export?dataset=snapshots&format=jsonl
+func (ec *ExportController) ExportUser(c *fiber.Ctx) error {
+	return ec.export(c, services.ExportScopeUser, "ID de usuario inválido")
+}
+
+// export valida los parámetros y envía el archivo en streaming
+func (ec *ExportController) export(c *fiber.Ctx, scope, invalidIDMessage string) error {
+	ownerID := c.Params("id")
+	if !utils.IsValidUUID(ownerID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, invalidIDMessage)
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	var columns []string
+	if value := c.Query("columns"); value != "" {
+		columns = strings.Split(value, ",")
+	}
+
+	export, err := ec.exportService.Prepare(services.ExportRequest{
+		Scope:    scope,
+		OwnerID:  ownerID,
+		Dataset:  c.Query("dataset", services.ExportDatasetSnapshots),
+		Format:   strings.ToLower(c.Query("format", "csv")),
+		Columns:  columns,
+		From:     from,
+		To:       to,
+		Currency: opts,
+	})
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", export.Filename))
+
+	// Las filas se leen y envían a medida que se escriben; un error a mitad del envío solo puede registrarse
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Write(w); err != nil {
+			log.Printf("❌ Error exportando %s: %v", export.Filename, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("⚠️ Error enviando %s: %v", export.Filename, err)
+		}
+	})
+
+	return nil
+}
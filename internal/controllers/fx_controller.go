@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FXController struct {
+	fxService *services.FXService
+}
+
+// NewFXController crea una nueva instancia del controlador de tipos de cambio
+func NewFXController() *FXController {
+	return &FXController{
+		fxService: services.NewFXService(),
+	}
+}
+
+// CreateFXRateRequest representa la request de alta manual de una cotización
+type CreateFXRateRequest struct {
+	Base    string  `json:"base"`
+	Quote   string  `json:"quote"`
+	Variant string  `json:"variant"`
+	Rate    float64 `json:"rate"`
+	Date    string  `json:"date,omitempty"` // YYYY-MM-DD o RFC3339, por defecto ahora
+}
+
+// GetRates lista las cotizaciones registradas
+// GET /api/admin/fx/rates?base=USD&quote=ARS&variant=mep&from=2024-01-01&to=2024-06-30
+func (fc *FXController) GetRates(c *fiber.Ctx) error {
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	rates, err := fc.fxService.GetRates(c.Query("base"), c.Query("quote"), c.Query("variant"), from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Cotizaciones obtenidas exitosamente", rates)
+}
+
+// CreateRate registra manualmente una cotización
+// POST /api/admin/fx/rates
+func (fc *FXController) CreateRate(c *fiber.Ctx) error {
+	var req CreateFXRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	date, err := utils.ParseDateParam(req.Date)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	rate := &models.FXRate{
+		Base:    req.Base,
+		Quote:   req.Quote,
+		Variant: req.Variant,
+		Rate:    req.Rate,
+	}
+	if date != nil {
+		rate.CreatedAt = *date
+	}
+
+	if err := fc.fxService.CreateRate(rate); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Cotización registrada exitosamente", rate)
+}
+
+// IngestRates obtiene y guarda las cotizaciones actuales del proveedor
+// POST /api/admin/fx/ingest
+func (fc *FXController) IngestRates(c *fiber.Ctx) error {
+	rates, err := fc.fxService.IngestRates()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadGateway, err.Error())
+	}
+
+	if rates == nil {
+		return utils.ErrorResponse(c, fiber.StatusConflict, "La obtención de tipos de cambio está deshabilitada (FX_ENABLED=false)")
+	}
+
+	return utils.SuccessResponse(c, "Cotizaciones obtenidas exitosamente", rates)
+}
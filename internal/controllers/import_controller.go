@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"holding-snapshots/internal/brokerimport"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxImportFileSize es el tamaño máximo aceptado para un CSV de broker
+const maxImportFileSize = 5 * 1024 * 1024
+
+type ImportController struct {
+	importService *services.ImportService
+}
+
+// NewImportController crea una nueva instancia del controlador de importación de CSV de brokers
+func NewImportController() *ImportController {
+	return &ImportController{
+		importService: services.NewImportService(),
+	}
+}
+
+// PreviewImport interpreta y valida el CSV mostrando los cambios sin guardarlos
+// POST /api/groups/:id/imports/preview (multipart: file, broker, mapping)
+func (ic *ImportController) PreviewImport(c *fiber.Ctx) error {
+	req, cleanup, err := ic.parseImportRequest(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	defer cleanup()
+
+	report, err := ic.importService.Preview(*req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Vista previa de importación generada", report)
+}
+
+// CommitImport guarda el contenido del CSV en una única transacción
+// POST /api/groups/:id/imports (multipart: file, broker, mapping, skipInvalid)
+func (ic *ImportController) CommitImport(c *fiber.Ctx) error {
+	req, cleanup, err := ic.parseImportRequest(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	defer cleanup()
+
+	report, err := ic.importService.Commit(*req)
+	if err != nil {
+		if report != nil {
+			// Líneas con errores: se devuelve el reporte para corregir el archivo
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+				"data":    report,
+			})
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Importación realizada exitosamente", report)
+}
+
+// GetImportFormats lista los formatos de broker predefinidos
+// GET /api/imports/formats
+func (ic *ImportController) GetImportFormats(c *fiber.Ctx) error {
+	formats := make([]brokerimport.Mapping, 0, len(brokerimport.PresetNames()))
+	for _, name := range brokerimport.PresetNames() {
+		mapping, _ := brokerimport.Preset(name)
+		formats = append(formats, mapping)
+	}
+
+	return utils.SuccessResponse(c, "Formatos de importación obtenidos exitosamente", formats)
+}
+
+// parseImportRequest lee el archivo y las opciones del formulario multipart
+func (ic *ImportController) parseImportRequest(c *fiber.Ctx) (*services.ImportRequest, func(), error) {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return nil, nil, errors.New("ID de grupo inválido")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, nil, errors.New("Se requiere el archivo CSV en el campo file")
+	}
+	if fileHeader.Size > maxImportFileSize {
+		return nil, nil, errors.New("El archivo supera el tamaño máximo de 5 MB")
+	}
+
+	req := &services.ImportRequest{
+		GroupID:     groupID,
+		Broker:      c.FormValue("broker"),
+		SkipInvalid: c.FormValue("skipInvalid") == "true",
+	}
+
+	if value := c.FormValue("mapping"); value != "" {
+		var mapping brokerimport.Mapping
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			return nil, nil, errors.New("Mapeo de columnas inválido")
+		}
+		req.Mapping = &mapping
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, errors.New("No se pudo leer el archivo")
+	}
+	req.Content = file
+
+	return req, func() { file.Close() }, nil
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PerformanceController struct {
+	performanceService *services.PerformanceService
+}
+
+// NewPerformanceController crea una nueva instancia del controlador de rendimientos
+func NewPerformanceController() *PerformanceController {
+	return &PerformanceController{
+		performanceService: services.NewPerformanceService(),
+	}
+}
+
+// GetHoldingPerformance obtiene el rendimiento TWR y MWR de un holding
+// GET /api/holdings/:id/performance?from=2024-01-01&to=2024-12-31
+func (pc *PerformanceController) GetHoldingPerformance(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.performanceService.GetHoldingPerformance(holdingID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Rendimiento del holding obtenido exitosamente", report)
+}
+
+// GetGroupPerformance obtiene el rendimiento TWR y MWR de un grupo
+// GET /api/groups/:id/performance?from=2024-01-01&to=2024-12-31&currency=USD
+func (pc *PerformanceController) GetGroupPerformance(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.performanceService.GetGroupPerformance(groupID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Rendimiento del grupo obtenido exitosamente", report)
+}
+
+// GetUserPerformance obtiene el rendimiento TWR y MWR de todos los grupos de un usuario
+// GET /api/users/:id/performance?from=2024-01-01&currency=USD&fxVariant=mep
+func (pc *PerformanceController) GetUserPerformance(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.performanceService.GetUserPerformance(userID, from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Rendimiento del usuario obtenido exitosamente", report)
+}
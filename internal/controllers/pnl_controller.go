@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PnLController struct {
+	pnlService *services.PnLService
+}
+
+// NewPnLController crea una nueva instancia del controlador de resultados
+func NewPnLController() *PnLController {
+	return &PnLController{
+		pnlService: services.NewPnLService(),
+	}
+}
+
+// GetHoldingPnL obtiene el historial de resultados realizados y no realizados de un holding
+// GET /api/holdings/:id/pnl?from=2024-01-01&to=2024-06-30
+func (pc *PnLController) GetHoldingPnL(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.pnlService.GetHoldingReport(holdingID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Resultados del holding obtenidos exitosamente", report)
+}
+
+// GetGroupPnL obtiene la serie de resultados de un grupo
+// GET /api/groups/:id/pnl?interval=week&from=2024-01-01&currency=USD
+func (pc *PnLController) GetGroupPnL(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.pnlService.GetGroupReport(groupID, c.Query("interval", "week"), from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Resultados del grupo obtenidos exitosamente", report)
+}
+
+// GetUserPnL obtiene la serie de resultados de un usuario por moneda
+// GET /api/users/:id/pnl?interval=month&currency=USD&fxVariant=mep
+func (pc *PnLController) GetUserPnL(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := pc.pnlService.GetUserReport(userID, c.Query("interval", "week"), from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Resultados del usuario obtenidos exitosamente", report)
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PortfolioController struct {
+	portfolioService *services.PortfolioService
+}
+
+// NewPortfolioController crea una nueva instancia del controlador de portafolios
+func NewPortfolioController() *PortfolioController {
+	return &PortfolioController{
+		portfolioService: services.NewPortfolioService(),
+	}
+}
+
+// GetGroupValueSeries obtiene la serie de valor total de un grupo
+// GET /api/groups/:id/value-series?interval=week&from=2024-01-01&to=2024-06-30&currency=ARS&fxVariant=ccl
+func (pc *PortfolioController) GetGroupValueSeries(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	series, err := pc.portfolioService.GetGroupValueSeries(groupID, c.Query("interval", "week"), from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Serie de valor del grupo obtenida exitosamente", series)
+}
+
+// GetUserValueSeries obtiene la serie de valor total de todos los grupos de un usuario
+// GET /api/users/:id/value-series?interval=week&from=2024-01-01&currency=USD&fxVariant=mep
+func (pc *PortfolioController) GetUserValueSeries(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	opts, err := services.ParseCurrencyOptions(c.Query("currency"), c.Query("fxVariant"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	series, err := pc.portfolioService.GetUserValueSeries(userID, c.Query("interval", "week"), from, to, opts)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Serie de valor del usuario obtenida exitosamente", series)
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QuoteController struct {
+	quoteService *services.QuoteService
+}
+
+// NewQuoteController crea una nueva instancia del controlador de cotizaciones
+func NewQuoteController() *QuoteController {
+	return &QuoteController{
+		quoteService: services.NewQuoteService(),
+	}
+}
+
+// GetQuote obtiene la cotización actual de un código
+// GET /api/quotes/:typeId/:code
+func (qc *QuoteController) GetQuote(c *fiber.Ctx) error {
+	typeID := c.Params("typeId")
+	if !utils.IsValidUUID(typeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	quote, err := qc.quoteService.GetQuote(typeID, c.Params("code"))
+	if err != nil {
+		if errors.Is(err, services.ErrQuoteUnavailable) {
+			return utils.ErrorResponse(c, fiber.StatusBadGateway, err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Cotización obtenida exitosamente", quote)
+}
+
+// GetQuotes obtiene las cotizaciones de varios códigos del mismo tipo de inversión
+// GET /api/quotes/:typeId?codes=AAPL,MSFT
+func (qc *QuoteController) GetQuotes(c *fiber.Ctx) error {
+	typeID := c.Params("typeId")
+	if !utils.IsValidUUID(typeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, code := range strings.Split(c.Query("codes"), ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code != "" && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) == 0 {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Se requiere al menos un código en codes")
+	}
+
+	maxCodes := 50
+	if config.AppConfig != nil && config.AppConfig.ValidationBatchMaxCodes > 0 {
+		maxCodes = config.AppConfig.ValidationBatchMaxCodes
+	}
+	if len(codes) > maxCodes {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, fmt.Sprintf("Se admiten como máximo %d códigos", maxCodes))
+	}
+
+	results, err := qc.quoteService.GetQuotes(typeID, codes)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Cotizaciones obtenidas exitosamente", results)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RetentionController struct {
+	retentionService *services.RetentionService
+}
+
+// NewRetentionController crea una nueva instancia del controlador de retención de snapshots
+func NewRetentionController() *RetentionController {
+	return &RetentionController{
+		retentionService: services.NewRetentionService(),
+	}
+}
+
+// RunRetentionRequest representa la request de ejecución de la política de retención
+type RunRetentionRequest struct {
+	DryRun *bool `json:"dryRun,omitempty"` // Por defecto true: solo reporta lo que se resumiría
+}
+
+// RunRetention aplica la política de retención a los snapshots antiguos
+// POST /api/admin/retention/run
+func (rc *RetentionController) RunRetention(c *fiber.Ctx) error {
+	var req RunRetentionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+		}
+	}
+
+	dryRun := true
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	report, err := rc.retentionService.Run(dryRun)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	message := "Retención aplicada exitosamente"
+	if dryRun {
+		message = "Reporte de retención calculado (sin cambios)"
+	}
+
+	return utils.SuccessResponse(c, message, report)
+}
+
+// GetHoldingRollups lista los agregados mensuales y trimestrales de un holding
+// GET /api/holdings/:id/rollups?from=2020-01-01&to=2023-12-31
+func (rc *RetentionController) GetHoldingRollups(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	rollups, err := rc.retentionService.GetHoldingRollups(holdingID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Agregados del holding obtenidos exitosamente", rollups)
+}
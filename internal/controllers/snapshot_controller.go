@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SnapshotController struct {
+	snapshotService  *services.SnapshotService
+	aggregateService *services.AggregateSnapshotService
+}
+
+// NewSnapshotController crea una nueva instancia del controlador de snapshots
+func NewSnapshotController() *SnapshotController {
+	return &SnapshotController{
+		snapshotService:  services.NewSnapshotService(),
+		aggregateService: services.NewAggregateSnapshotService(),
+	}
+}
+
+// GetHoldingSnapshots lista los snapshots de un holding
+// GET /api/holdings/:id/snapshots?from=2024-01-01&to=2024-03-31&limit=50&cursor=...&sort=asc
+func (sc *SnapshotController) GetHoldingSnapshots(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	if !sc.snapshotService.HoldingExists(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Holding no encontrado")
+	}
+
+	filter, err := parseSnapshotFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	filter.HoldingID = holdingID
+
+	page, err := sc.snapshotService.ListSnapshots(*filter)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Snapshots obtenidos exitosamente", page)
+}
+
+// GetSnapshots lista snapshots filtrando por asset, grupo, usuario y fechas
+// GET /api/snapshots?assetId=&groupId=&userId=&from=&to=&limit=&cursor=&sort=
+func (sc *SnapshotController) GetSnapshots(c *fiber.Ctx) error {
+	filter, err := parseSnapshotFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	for name, value := range map[string]string{
+		"holdingId": c.Query("holdingId"),
+		"assetId":   c.Query("assetId"),
+		"groupId":   c.Query("groupId"),
+		"userId":    c.Query("userId"),
+	} {
+		if value != "" && !utils.IsValidUUID(value) {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "Parámetro "+name+" inválido")
+		}
+	}
+
+	filter.HoldingID = c.Query("holdingId")
+	filter.AssetID = c.Query("assetId")
+	filter.GroupID = c.Query("groupId")
+	filter.UserID = c.Query("userId")
+
+	page, err := sc.snapshotService.ListSnapshots(*filter)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Snapshots obtenidos exitosamente", page)
+}
+
+// GetGroupSnapshots lista los totales de un grupo guardados en cada ejecución del cron
+// GET /api/groups/:id/snapshots?from=2024-01-01&to=2024-06-30
+func (sc *SnapshotController) GetGroupSnapshots(c *fiber.Ctx) error {
+	groupID := c.Params("id")
+	if !utils.IsValidUUID(groupID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de grupo inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	snapshots, err := sc.aggregateService.GetGroupSnapshots(groupID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Snapshots del grupo obtenidos exitosamente", snapshots)
+}
+
+// GetUserSnapshots lista los totales por moneda de un usuario guardados en cada ejecución del cron
+// GET /api/users/:id/snapshots?currency=ARS&from=2024-01-01&to=2024-06-30
+func (sc *SnapshotController) GetUserSnapshots(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	snapshots, err := sc.aggregateService.GetUserSnapshots(userID, c.Query("currency"), from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Snapshots del usuario obtenidos exitosamente", snapshots)
+}
+
+// parseSnapshotFilter interpreta los parámetros comunes de fechas, orden y paginación
+func parseSnapshotFilter(c *fiber.Ctx) (*services.SnapshotFilter, error) {
+	from, to, err := utils.ParseDateRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &services.SnapshotFilter{
+		From:      from,
+		To:        to,
+		Limit:     utils.ClampLimit(c.QueryInt("limit", 50), 50, 500),
+		Ascending: c.Query("sort", "desc") == "asc",
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		filter.Cursor, err = utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SummaryController struct {
+	summaryService *services.SummaryService
+}
+
+// NewSummaryController crea una nueva instancia del controlador de resúmenes
+func NewSummaryController() *SummaryController {
+	return &SummaryController{
+		summaryService: services.NewSummaryService(),
+	}
+}
+
+// UpdatePreferencesRequest representa la request para actualizar preferencias de notificación
+type UpdatePreferencesRequest struct {
+	WeeklySummary bool `json:"weeklySummary"`
+}
+
+// GetPreferences obtiene las preferencias de notificación de un usuario
+// GET /api/users/:id/preferences
+func (sc *SummaryController) GetPreferences(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	preference, err := sc.summaryService.GetPreference(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Preferencias obtenidas exitosamente", preference)
+}
+
+// UpdatePreferences actualiza las preferencias de notificación de un usuario
+// PUT /api/users/:id/preferences
+func (sc *SummaryController) UpdatePreferences(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	preference, err := sc.summaryService.UpdatePreference(userID, req.WeeklySummary)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Preferencias actualizadas exitosamente", preference)
+}
+
+// PreviewSummary renderiza el resumen semanal de un usuario sin enviarlo
+// GET /api/admin/summaries/users/:id?format=html|text|json
+func (sc *SummaryController) PreviewSummary(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	summary, err := sc.summaryService.BuildUserSummary(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	format := c.Query("format", "json")
+	if format == "json" {
+		return utils.SuccessResponse(c, "Resumen semanal generado exitosamente", summary)
+	}
+
+	html, text, err := sc.summaryService.Render(summary)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	if format == "text" {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(text)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(html)
+}
+
+// SendUserSummary envía el resumen semanal a un usuario puntual
+// POST /api/admin/summaries/users/:id/send
+func (sc *SummaryController) SendUserSummary(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if !utils.IsValidUUID(userID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de usuario inválido")
+	}
+
+	if err := sc.summaryService.SendUserSummary(userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Resumen semanal enviado exitosamente", fiber.Map{
+		"userId": userID,
+	})
+}
+
+// SendAllSummaries envía el resumen semanal a todos los usuarios suscriptos
+// POST /api/admin/summaries/send
+func (sc *SummaryController) SendAllSummaries(c *fiber.Ctx) error {
+	// Ejecutar en background para no bloquear la respuesta HTTP
+	go sc.summaryService.SendWeeklySummaries()
+
+	return utils.SuccessResponse(c, "Envío de resúmenes iniciado en segundo plano", fiber.Map{
+		"message": "Los resúmenes se están enviando. Revisa los logs del servidor para ver el progreso.",
+	})
+}
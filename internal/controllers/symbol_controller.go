@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SymbolController struct {
+	symbolSearchService  *services.SymbolSearchService
+	symbolMappingService *services.SymbolMappingService
+}
+
+// NewSymbolController crea una nueva instancia del controlador de búsqueda de símbolos
+func NewSymbolController() *SymbolController {
+	return &SymbolController{
+		symbolSearchService:  services.NewSymbolSearchService(),
+		symbolMappingService: services.NewSymbolMappingService(),
+	}
+}
+
+// SearchSymbols busca tickers por código o nombre en los assets registrados y en el proveedor
+// GET /api/symbols/search?q=apple&typeId=&limit=10
+func (sc *SymbolController) SearchSymbols(c *fiber.Ctx) error {
+	query := c.Query("q")
+	if query == "" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "El parámetro q es requerido")
+	}
+
+	typeID := c.Query("typeId")
+	if typeID != "" && !utils.IsValidUUID(typeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	limit := utils.ClampLimit(c.QueryInt("limit", 10), 10, 25)
+
+	matches, err := sc.symbolSearchService.Search(query, typeID, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Símbolos encontrados", matches)
+}
+
+// NormalizeSymbol retorna el código canónico de una entrada y su símbolo en cada proveedor
+// GET /api/symbols/normalize?typeId=&code=brk-b
+func (sc *SymbolController) NormalizeSymbol(c *fiber.Ctx) error {
+	typeID := c.Query("typeId")
+	if !utils.IsValidUUID(typeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	normalized, err := sc.symbolMappingService.Resolve(typeID, c.Query("code"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Código normalizado", normalized)
+}
+
+// GetSymbolMappings lista los mapeos de símbolos
+// GET /api/admin/symbol-mappings?typeId=&code=
+func (sc *SymbolController) GetSymbolMappings(c *fiber.Ctx) error {
+	typeID := c.Query("typeId")
+	if typeID != "" && !utils.IsValidUUID(typeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	mappings, err := sc.symbolMappingService.GetMappings(typeID, c.Query("code"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Mapeos de símbolos obtenidos exitosamente", mappings)
+}
+
+// CreateSymbolMapping registra el símbolo de un código en un proveedor o un alias de usuario
+// POST /api/admin/symbol-mappings
+func (sc *SymbolController) CreateSymbolMapping(c *fiber.Ctx) error {
+	var req services.SymbolMappingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	if !utils.IsValidUUID(req.TypeID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de tipo de inversión inválido")
+	}
+
+	mapping, err := sc.symbolMappingService.CreateMapping(req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Mapeo de símbolo creado exitosamente", mapping)
+}
+
+// DeleteSymbolMapping elimina un mapeo de símbolo
+// DELETE /api/admin/symbol-mappings/:id
+func (sc *SymbolController) DeleteSymbolMapping(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de mapeo inválido")
+	}
+
+	if err := sc.symbolMappingService.DeleteMapping(id); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Mapeo de símbolo eliminado exitosamente", nil)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransactionController struct {
+	transactionService *services.TransactionService
+}
+
+// NewTransactionController crea una nueva instancia del controlador de transacciones
+func NewTransactionController() *TransactionController {
+	return &TransactionController{
+		transactionService: services.NewTransactionService(),
+	}
+}
+
+// CreateTransactionRequest representa la request de alta de una transacción
+type CreateTransactionRequest struct {
+	Type     string  `json:"type"`
+	Date     string  `json:"date"` // YYYY-MM-DD o RFC3339
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Fees     float64 `json:"fees"`
+	Currency string  `json:"currency,omitempty"`
+	Notes    string  `json:"notes,omitempty"`
+}
+
+// GetHoldingTransactions lista las transacciones de un holding
+// GET /api/holdings/:id/transactions
+func (tc *TransactionController) GetHoldingTransactions(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	transactions, err := tc.transactionService.GetHoldingTransactions(holdingID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Transacciones obtenidas exitosamente", transactions)
+}
+
+// CreateHoldingTransaction registra una transacción en un holding
+// POST /api/holdings/:id/transactions
+func (tc *TransactionController) CreateHoldingTransaction(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	var req CreateTransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Formato de request inválido")
+	}
+
+	date, err := utils.ParseDateParam(req.Date)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if date == nil {
+		date = new(time.Time)
+	}
+
+	transaction := &models.Transaction{
+		HoldingID: holdingID,
+		Type:      req.Type,
+		Date:      *date,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		Fees:      req.Fees,
+		Currency:  req.Currency,
+		Notes:     utils.SanitizeString(req.Notes),
+	}
+
+	if err := tc.transactionService.CreateTransaction(transaction); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Transacción registrada exitosamente", transaction)
+}
+
+// DeleteTransaction elimina una transacción
+// DELETE /api/transactions/:id
+func (tc *TransactionController) DeleteTransaction(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if !utils.IsValidUUID(transactionID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de transacción inválido")
+	}
+
+	if err := tc.transactionService.DeleteTransaction(transactionID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Transacción eliminada exitosamente", fiber.Map{
+		"id": transactionID,
+	})
+}
+
+// GetHoldingCostBasis obtiene el costo y las ganancias desde el inicio de un holding
+// GET /api/holdings/:id/cost-basis?method=average|fifo
+func (tc *TransactionController) GetHoldingCostBasis(c *fiber.Ctx) error {
+	holdingID := c.Params("id")
+	if !utils.IsValidUUID(holdingID) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de holding inválido")
+	}
+
+	report, err := tc.transactionService.GetCostBasis(holdingID, c.Query("method", "average"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Costo del holding obtenido exitosamente", report)
+}
@@ -1,27 +1,39 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"holding-snapshots/internal/config"
 	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type ValidationController struct {
-	scrapingService *services.ScrapingService
+	scrapingService          *services.ScrapingService
+	symbolSearchService      *services.SymbolSearchService
+	assetRegistrationService *services.AssetRegistrationService
 }
 
 // NewValidationController crea una nueva instancia del controlador
 func NewValidationController() *ValidationController {
 	return &ValidationController{
-		scrapingService: services.NewScrapingService(),
+		scrapingService:          services.NewScrapingService(),
+		symbolSearchService:      services.NewSymbolSearchService(),
+		assetRegistrationService: services.NewAssetRegistrationService(),
 	}
 }
 
+// suggestionsLimit es la cantidad de símbolos sugeridos cuando una validación falla
+const suggestionsLimit = 5
+
 // ValidateHoldingRequest representa la estructura de la request de validación
 type ValidateHoldingRequest struct {
 	Code             string `json:"code" validate:"required"`
 	TypeInvestmentID string `json:"typeInvestmentId,omitempty"`
+	Register         bool   `json:"register,omitempty"` // Crear o actualizar el Asset si el código es válido
 }
 
 // ValidateHoldingResponse representa la estructura de la response de validación
@@ -41,21 +53,157 @@ func (vc *ValidationController) ValidateHolding(c *fiber.Ctx) error {
 		})
 	}
 
-	price, err := vc.scrapingService.ValidateHolding(req.TypeInvestmentID, req.Code)
+	price, cached, err := vc.scrapingService.ValidateHoldingCached(req.TypeInvestmentID, req.Code)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Error al validar el holding: " + err.Error(),
+			"error":       "Error al validar el holding: " + err.Error(),
+			"suggestions": vc.symbolSearchService.Suggest(req.TypeInvestmentID, req.Code, suggestionsLimit),
 		})
 	}
+
+	holding := fiber.Map{
+		"code":      req.Code,
+		"lastPrice": price,
+	}
+
+	if req.Register && price > 0 {
+		asset, created, err := vc.assetRegistrationService.Register(req.TypeInvestmentID, req.Code, price, !cached)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Error al registrar el asset: " + err.Error(),
+				"isValid": true,
+			})
+		}
+		holding["assetId"] = asset.ID
+		holding["assetCreated"] = created
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"holding": fiber.Map{
-			"code":      req.Code,
-			"lastPrice": price,
-		},
+		"holding": holding,
 		"isValid": price > 0,
 	})
 }
 
+// ValidateBatchRequest representa la request de validación en lote. Los items sin
+// typeInvestmentId usan el del nivel superior.
+type ValidateBatchRequest struct {
+	TypeInvestmentID string                         `json:"typeInvestmentId,omitempty"`
+	Items            []services.BatchValidationItem `json:"items"`
+	Register         bool                           `json:"register,omitempty"` // Crear o actualizar el Asset de cada código válido
+}
+
+// ValidateBatch valida varios códigos, de uno o más tipos de inversión, en una sola request
+// POST /api/validate/batch
+func (vc *ValidationController) ValidateBatch(c *fiber.Ctx) error {
+	var req ValidateBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de request inválido",
+		})
+	}
+
+	if len(req.Items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Se requiere al menos un código en items",
+		})
+	}
+
+	maxCodes := 50
+	if config.AppConfig != nil && config.AppConfig.ValidationBatchMaxCodes > 0 {
+		maxCodes = config.AppConfig.ValidationBatchMaxCodes
+	}
+	if len(req.Items) > maxCodes {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("El lote admite como máximo %d códigos", maxCodes),
+		})
+	}
+
+	for i := range req.Items {
+		if req.Items[i].TypeInvestmentID == "" {
+			req.Items[i].TypeInvestmentID = req.TypeInvestmentID
+		}
+	}
+
+	results := vc.scrapingService.ValidateBatch(req.Items)
+
+	validCount := 0
+	suggestions := make(map[string][]services.SymbolMatch)
+	for i, result := range results {
+		if result.IsValid {
+			validCount++
+			if req.Register {
+				vc.registerBatchResult(&results[i])
+			}
+			continue
+		}
+		if result.Code == "" || result.Currency == "" {
+			continue // Código vacío o tipo de inversión inexistente
+		}
+
+		key := result.TypeInvestmentID + "|" + result.Code
+		if _, ok := suggestions[key]; !ok {
+			suggestions[key] = vc.symbolSearchService.Suggest(result.TypeInvestmentID, result.Code, suggestionsLimit)
+		}
+		results[i].Suggestions = suggestions[key]
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"results":      results,
+		"total":        len(results),
+		"validCount":   validCount,
+		"invalidCount": len(results) - validCount,
+	})
+}
+
+// registerBatchResult registra el asset de un resultado válido del lote; los errores quedan en el resultado
+func (vc *ValidationController) registerBatchResult(result *services.BatchValidationResult) {
+	asset, created, err := vc.assetRegistrationService.Register(result.TypeInvestmentID, result.Code, *result.Price, !result.Cached)
+	if err != nil {
+		result.RegisterError = err.Error()
+		return
+	}
+	result.AssetID = asset.ID
+	result.AssetCreated = created
+}
+
+// GetValidationCache lista las validaciones cacheadas
+// GET /api/admin/validation-cache?typeInvestmentId=&code=&limit=
+func (vc *ValidationController) GetValidationCache(c *fiber.Ctx) error {
+	limit := utils.ClampLimit(c.QueryInt("limit", 100), 100, 1000)
+
+	entries, err := vc.scrapingService.GetValidationCacheEntries(c.Query("typeInvestmentId"), c.Query("code"), limit)
+	if err != nil {
+		return validationCacheError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Validaciones cacheadas obtenidas exitosamente", entries)
+}
+
+// PurgeValidationCache elimina validaciones cacheadas; sin filtros elimina todas
+// DELETE /api/admin/validation-cache?typeInvestmentId=&code=
+func (vc *ValidationController) PurgeValidationCache(c *fiber.Ctx) error {
+	deleted, err := vc.scrapingService.PurgeValidationCache(c.Query("typeInvestmentId"), c.Query("code"))
+	if err != nil {
+		return validationCacheError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Validaciones eliminadas del cache", fiber.Map{"deleted": deleted})
+}
+
+// GetValidationCacheStats retorna hits, misses y cantidad de entradas del cache de validaciones
+// GET /api/admin/validation-cache/stats
+func (vc *ValidationController) GetValidationCacheStats(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "Estadísticas del cache de validaciones", vc.scrapingService.GetValidationCacheStats())
+}
+
+// validationCacheError responde 503 si Redis no está disponible y 500 en otro caso
+func validationCacheError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrValidationCacheUnavailable) {
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, err.Error())
+	}
+	return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+}
+
 // HealthCheck endpoint simple para verificar el estado del servicio
 // GET /api/health
 func (vc *ValidationController) HealthCheck(c *fiber.Ctx) error {
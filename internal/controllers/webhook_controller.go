@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"holding-snapshots/internal/services"
+	"holding-snapshots/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookController struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookController crea una nueva instancia del controlador de webhooks
+func NewWebhookController() *WebhookController {
+	return &WebhookController{
+		webhookService: services.NewWebhookService(),
+	}
+}
+
+// GetDeliveries lista las entregas de webhooks más recientes
+// GET /api/admin/webhooks/deliveries?status=failed&event=run.failed&limit=50
+func (wc *WebhookController) GetDeliveries(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	deliveries, err := wc.webhookService.GetDeliveries(c.Query("status"), c.Query("event"), limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Entregas de webhooks obtenidas exitosamente", deliveries)
+}
+
+// ReplayDelivery reenvía una entrega de webhook existente
+// POST /api/admin/webhooks/deliveries/:id/replay
+func (wc *WebhookController) ReplayDelivery(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !utils.IsValidUUID(id) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "ID de entrega inválido")
+	}
+
+	delivery, err := wc.webhookService.ReplayDelivery(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, "Reenvío de webhook iniciado en segundo plano", delivery)
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formatos de exportación
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl"
+)
+
+// Writer escribe filas de a una sobre un io.Writer, sin mantener el archivo completo en memoria.
+// Los valores de cada fila deben estar en el orden de las columnas recibidas al crearlo;
+// se admiten string, float64, int, bool, time.Time y nil (celda vacía).
+type Writer interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// IsValidFormat verifica si el formato de exportación es soportado
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX || format == FormatJSONL
+}
+
+// ContentType retorna el Content-Type de un formato
+func ContentType(format string) string {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewWriter crea un writer del formato indicado y escribe el encabezado
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatJSONL:
+		return &jsonlWriter{w: w, columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("formato de exportación inválido: %s", format)
+	}
+}
+
+// csvWriter escribe filas separadas por comas con encabezado
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w)}
+	if err := writer.w.Write(columns); err != nil {
+		return nil, fmt.Errorf("error escribiendo encabezado CSV: %w", err)
+	}
+	return writer, nil
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatText(value)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlWriter escribe un objeto JSON por línea, respetando el orden de las columnas
+type jsonlWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (jw *jsonlWriter) WriteRow(values []interface{}) error {
+	line := []byte{'{'}
+	for i, column := range jw.columns {
+		if i > 0 {
+			line = append(line, ',')
+		}
+
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339)
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("error codificando columna %s: %w", column, err)
+		}
+
+		line = append(line, key...)
+		line = append(line, ':')
+		line = append(line, encoded...)
+	}
+	line = append(line, '}', '\n')
+
+	_, err := jw.w.Write(line)
+	return err
+}
+
+func (jw *jsonlWriter) Close() error {
+	return nil
+}
+
+// formatText convierte un valor a texto para CSV
+func formatText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
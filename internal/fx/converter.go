@@ -0,0 +1,86 @@
+package fx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+// Converter convierte importes entre monedas usando la cotización vigente a cada fecha.
+// Trabaja en memoria sobre un conjunto de cotizaciones ya cargadas.
+type Converter struct {
+	variant string
+	rates   map[string][]models.FXRate // "BASE/QUOTE" -> cotizaciones ordenadas por fecha
+}
+
+// NewConverter crea un conversor para la variante indicada a partir de cotizaciones de cualquier par
+func NewConverter(variant string, rates []models.FXRate) *Converter {
+	c := &Converter{
+		variant: variant,
+		rates:   make(map[string][]models.FXRate),
+	}
+
+	for _, rate := range rates {
+		if rate.Variant != variant || rate.Rate <= 0 {
+			continue
+		}
+		key := rate.Base + "/" + rate.Quote
+		c.rates[key] = append(c.rates[key], rate)
+	}
+
+	for key := range c.rates {
+		sort.Slice(c.rates[key], func(i, j int) bool {
+			return c.rates[key][i].CreatedAt.Before(c.rates[key][j].CreatedAt)
+		})
+	}
+
+	return c
+}
+
+// RateAt retorna cuántas unidades de "to" vale 1 unidad de "from" a la fecha indicada.
+// Usa la última cotización anterior o igual a la fecha; antes de la primera cotización del par no hay tipo de cambio.
+func (c *Converter) RateAt(from, to string, at time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	if rate, ok := c.lookup(from+"/"+to, at); ok {
+		return rate, nil
+	}
+
+	if rate, ok := c.lookup(to+"/"+from, at); ok {
+		return 1 / rate, nil
+	}
+
+	return 0, fmt.Errorf("no hay cotización %s/%s (%s) disponible al %s", from, to, c.variant, at.Format("2006-01-02"))
+}
+
+// Convert convierte un importe de "from" a "to" a la fecha indicada
+func (c *Converter) Convert(amount float64, from, to string, at time.Time) (float64, error) {
+	rate, err := c.RateAt(from, to, at)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// lookup busca la cotización vigente de un par a una fecha. Una cotización posterior no se usa
+// para fechas anteriores porque el importe convertido sería incorrecto.
+func (c *Converter) lookup(pair string, at time.Time) (float64, bool) {
+	rates := c.rates[pair]
+	if len(rates) == 0 {
+		return 0, false
+	}
+
+	// Primer índice con fecha posterior a "at"
+	idx := sort.Search(len(rates), func(i int) bool {
+		return rates[i].CreatedAt.After(at)
+	})
+
+	if idx == 0 {
+		return 0, false
+	}
+	return rates[idx-1].Rate, true
+}
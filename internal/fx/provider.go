@@ -0,0 +1,94 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+// RateProvider define la interfaz para las fuentes de tipos de cambio
+type RateProvider interface {
+	// Name retorna el nombre de la fuente, guardado en cada cotización
+	Name() string
+
+	// FetchRates obtiene las cotizaciones actuales
+	FetchRates() ([]models.FXRate, error)
+}
+
+// DolarAPIProvider obtiene las cotizaciones del dólar en Argentina desde una API estilo dolarapi.com
+type DolarAPIProvider struct {
+	URL    string
+	client *http.Client
+}
+
+// dolarAPIQuote es cada elemento de la respuesta de /v1/dolares
+type dolarAPIQuote struct {
+	Moneda string  `json:"moneda"`
+	Casa   string  `json:"casa"`
+	Compra float64 `json:"compra"`
+	Venta  float64 `json:"venta"`
+}
+
+// dolarAPIVariants traduce el campo "casa" de la API a las variantes del servicio
+var dolarAPIVariants = map[string]string{
+	"oficial":         models.FXVariantOfficial,
+	"bolsa":           models.FXVariantMEP,
+	"contadoconliqui": models.FXVariantCCL,
+	"blue":            models.FXVariantBlue,
+	"mayorista":       models.FXVariantWholesale,
+}
+
+// NewDolarAPIProvider crea un proveedor para la URL indicada
+func NewDolarAPIProvider(url string) *DolarAPIProvider {
+	return &DolarAPIProvider{
+		URL:    url,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (p *DolarAPIProvider) Name() string {
+	return "dolarapi"
+}
+
+// FetchRates obtiene las cotizaciones USD/ARS usando el precio de venta de cada variante
+func (p *DolarAPIProvider) FetchRates() ([]models.FXRate, error) {
+	resp, err := p.client.Get(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando %s: %w", p.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta no exitosa de %s: %d", p.URL, resp.StatusCode)
+	}
+
+	var quotes []dolarAPIQuote
+	if err := json.NewDecoder(resp.Body).Decode(&quotes); err != nil {
+		return nil, fmt.Errorf("error decodificando cotizaciones: %w", err)
+	}
+
+	now := time.Now()
+	var rates []models.FXRate
+	for _, quote := range quotes {
+		variant, ok := dolarAPIVariants[quote.Casa]
+		if !ok || quote.Venta <= 0 {
+			continue
+		}
+
+		rates = append(rates, models.FXRate{
+			Base:      "USD",
+			Quote:     "ARS",
+			Variant:   variant,
+			Rate:      quote.Venta,
+			Source:    p.Name(),
+			CreatedAt: now,
+		})
+	}
+
+	log.Printf("💱 [DolarAPIProvider] %d cotizaciones obtenidas", len(rates))
+	return rates, nil
+}
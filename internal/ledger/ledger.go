@@ -0,0 +1,185 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+// quantityEpsilon tolera errores de redondeo al comparar cantidades
+const quantityEpsilon = 1e-9
+
+// Métodos de cálculo de costo
+const (
+	MethodAverage = "average"
+	MethodFIFO    = "fifo"
+)
+
+// Lot es un lote abierto de unidades con su costo unitario (incluye comisiones)
+type Lot struct {
+	Date     time.Time `json:"date"`
+	Quantity float64   `json:"quantity"`
+	UnitCost float64   `json:"unitCost"`
+}
+
+// Position es el estado de un holding reconstruido a partir de sus transacciones
+type Position struct {
+	Quantity      float64 `json:"quantity"`
+	TotalInvested float64 `json:"totalInvested"` // Costo total de todas las entradas, incluyendo comisiones
+	TotalFees     float64 `json:"totalFees"`
+
+	// Método de costo promedio
+	AverageCost        float64 `json:"averageCost"`
+	AverageCostBasis   float64 `json:"averageCostBasis"`
+	AverageRealizedPnL float64 `json:"averageRealizedPnL"`
+
+	// Método FIFO
+	FIFOCostBasis   float64 `json:"fifoCostBasis"`
+	FIFORealizedPnL float64 `json:"fifoRealizedPnL"`
+	OpenLots        []Lot   `json:"openLots"`
+}
+
+// SortTransactions ordena las transacciones por fecha y, a igual fecha, por orden de carga
+func SortTransactions(transactions []models.Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].CreatedAt.Before(transactions[j].CreatedAt)
+		}
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+}
+
+// BuildPosition reconstruye la posición aplicando las transacciones en orden cronológico.
+// Retorna error si alguna salida supera las unidades disponibles en ese momento.
+func BuildPosition(transactions []models.Transaction) (*Position, error) {
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+	SortTransactions(sorted)
+
+	position := &Position{OpenLots: []Lot{}}
+
+	for _, tx := range sorted {
+		if tx.Quantity <= 0 {
+			return nil, fmt.Errorf("transacción %s con cantidad inválida: %f", tx.ID, tx.Quantity)
+		}
+
+		if tx.IsInflow() {
+			position.applyInflow(tx)
+			continue
+		}
+
+		if err := position.applyOutflow(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if position.Quantity > quantityEpsilon {
+		position.AverageCost = position.AverageCostBasis / position.Quantity
+	}
+
+	return position, nil
+}
+
+// applyInflow agrega unidades (compra o transferencia entrante) a ambos métodos
+func (p *Position) applyInflow(tx models.Transaction) {
+	cost := tx.Quantity*tx.Price + tx.Fees
+
+	p.Quantity += tx.Quantity
+	p.TotalInvested += cost
+	p.TotalFees += tx.Fees
+	p.AverageCostBasis += cost
+	p.FIFOCostBasis += cost
+	p.OpenLots = append(p.OpenLots, Lot{
+		Date:     tx.Date,
+		Quantity: tx.Quantity,
+		UnitCost: cost / tx.Quantity,
+	})
+}
+
+// applyOutflow retira unidades (venta o transferencia saliente) de ambos métodos.
+// Solo las ventas generan resultado realizado; las transferencias salientes retiran el costo.
+func (p *Position) applyOutflow(tx models.Transaction) error {
+	if tx.Quantity > p.Quantity+quantityEpsilon {
+		return fmt.Errorf("la transacción del %s retira %f unidades pero solo hay %f disponibles",
+			tx.Date.Format("2006-01-02"), tx.Quantity, p.Quantity)
+	}
+
+	proceeds := 0.0
+	if tx.Type == models.TransactionSell {
+		proceeds = tx.Quantity*tx.Price - tx.Fees
+	}
+	p.TotalFees += tx.Fees
+
+	// Costo promedio: se retira la proporción del costo acumulado
+	averageCost := p.AverageCostBasis / p.Quantity
+	averageRemoved := averageCost * tx.Quantity
+	p.AverageCostBasis -= averageRemoved
+
+	// FIFO: se consumen los lotes más antiguos primero
+	fifoRemoved := 0.0
+	remaining := tx.Quantity
+	for remaining > quantityEpsilon && len(p.OpenLots) > 0 {
+		lot := &p.OpenLots[0]
+		used := lot.Quantity
+		if used > remaining {
+			used = remaining
+		}
+
+		fifoRemoved += used * lot.UnitCost
+		lot.Quantity -= used
+		remaining -= used
+
+		if lot.Quantity <= quantityEpsilon {
+			p.OpenLots = p.OpenLots[1:]
+		}
+	}
+	p.FIFOCostBasis -= fifoRemoved
+
+	if tx.Type == models.TransactionSell {
+		p.AverageRealizedPnL += proceeds - averageRemoved
+		p.FIFORealizedPnL += proceeds - fifoRemoved
+	}
+
+	p.Quantity -= tx.Quantity
+	if p.Quantity <= quantityEpsilon {
+		// Posición cerrada: limpiar residuos de redondeo
+		p.Quantity = 0
+		p.AverageCostBasis = 0
+		p.FIFOCostBasis = 0
+		p.OpenLots = []Lot{}
+	}
+
+	return nil
+}
+
+// UnrealizedPnL calcula el resultado no realizado a un precio de mercado con el método indicado
+func (p *Position) UnrealizedPnL(marketPrice float64, method string) float64 {
+	marketValue := p.Quantity * marketPrice
+	if method == MethodFIFO {
+		return marketValue - p.FIFOCostBasis
+	}
+	return marketValue - p.AverageCostBasis
+}
+
+// RealizedPnL retorna el resultado realizado con el método indicado
+func (p *Position) RealizedPnL(method string) float64 {
+	if method == MethodFIFO {
+		return p.FIFORealizedPnL
+	}
+	return p.AverageRealizedPnL
+}
+
+// CostBasis retorna el costo de la posición abierta con el método indicado
+func (p *Position) CostBasis(method string) float64 {
+	if method == MethodFIFO {
+		return p.FIFOCostBasis
+	}
+	return p.AverageCostBasis
+}
+
+// IsValidMethod indica si el método de costo es soportado
+func IsValidMethod(method string) bool {
+	return method == MethodAverage || method == MethodFIFO
+}
@@ -0,0 +1,242 @@
+package ledger
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+const tolerance = 1e-9
+
+func day(n int) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, n)
+}
+
+func transaction(txType string, date time.Time, quantity, price, fees float64) models.Transaction {
+	return models.Transaction{Type: txType, Date: date, Quantity: quantity, Price: price, Fees: fees}
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.10f, se esperaba %.10f", name, got, want)
+	}
+}
+
+func TestBuildPosition(t *testing.T) {
+	tests := []struct {
+		name                  string
+		transactions          []models.Transaction
+		wantQuantity          float64
+		wantInvested          float64
+		wantFees              float64
+		wantAverageCost       float64
+		wantAverageBasis      float64
+		wantAverageRealized   float64
+		wantFIFOBasis         float64
+		wantFIFORealized      float64
+		wantOpenLots          int
+		marketPrice           float64
+		wantAverageUnrealized float64
+		wantFIFOUnrealized    float64
+	}{
+		{
+			name: "compras sin ventas",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 10, 100, 0),
+				transaction(models.TransactionBuy, day(1), 10, 200, 0),
+			},
+			wantQuantity:          20,
+			wantInvested:          3000,
+			wantAverageCost:       150,
+			wantAverageBasis:      3000,
+			wantFIFOBasis:         3000,
+			wantOpenLots:          2,
+			marketPrice:           160,
+			wantAverageUnrealized: 200,
+			wantFIFOUnrealized:    200,
+		},
+		{
+			// Promedio: se retiran 15 × 150; FIFO: 10 × 100 + 5 × 200
+			name: "venta parcial con costo promedio y FIFO",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 10, 100, 0),
+				transaction(models.TransactionBuy, day(1), 10, 200, 0),
+				transaction(models.TransactionSell, day(2), 15, 180, 0),
+			},
+			wantQuantity:          5,
+			wantInvested:          3000,
+			wantAverageCost:       150,
+			wantAverageBasis:      750,
+			wantAverageRealized:   15*180 - 15*150,
+			wantFIFOBasis:         1000,
+			wantFIFORealized:      15*180 - (10*100 + 5*200),
+			wantOpenLots:          1,
+			marketPrice:           180,
+			wantAverageUnrealized: 5*180 - 750,
+			wantFIFOUnrealized:    5*180 - 1000,
+		},
+		{
+			// Las comisiones de compra suman al costo y las de venta restan a lo cobrado
+			name: "comisiones",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 10, 100, 10),
+				transaction(models.TransactionSell, day(1), 4, 120, 2),
+			},
+			wantQuantity:          6,
+			wantInvested:          1010,
+			wantFees:              12,
+			wantAverageCost:       101,
+			wantAverageBasis:      606,
+			wantAverageRealized:   (4*120 - 2) - 4*101,
+			wantFIFOBasis:         606,
+			wantFIFORealized:      (4*120 - 2) - 4*101,
+			wantOpenLots:          1,
+			marketPrice:           101,
+			wantAverageUnrealized: 0,
+			wantFIFOUnrealized:    0,
+		},
+		{
+			// Las transferencias salientes retiran costo sin generar resultado realizado
+			name: "transferencias",
+			transactions: []models.Transaction{
+				transaction(models.TransactionTransferIn, day(0), 10, 50, 0),
+				transaction(models.TransactionTransferOut, day(1), 4, 0, 0),
+			},
+			wantQuantity:          6,
+			wantInvested:          500,
+			wantAverageCost:       50,
+			wantAverageBasis:      300,
+			wantFIFOBasis:         300,
+			wantOpenLots:          1,
+			marketPrice:           60,
+			wantAverageUnrealized: 60,
+			wantFIFOUnrealized:    60,
+		},
+		{
+			name: "posición cerrada",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 3, 100, 0),
+				transaction(models.TransactionBuy, day(1), 7, 110, 0),
+				transaction(models.TransactionSell, day(2), 10, 120, 0),
+			},
+			wantQuantity:        0,
+			wantInvested:        1070,
+			wantAverageRealized: 1200 - 1070,
+			wantFIFORealized:    1200 - 1070,
+			wantOpenLots:        0,
+		},
+		{
+			// Se ordenan por fecha: la venta del día 2 ocurre después de ambas compras
+			name: "transacciones desordenadas",
+			transactions: []models.Transaction{
+				transaction(models.TransactionSell, day(2), 5, 130, 0),
+				transaction(models.TransactionBuy, day(1), 5, 120, 0),
+				transaction(models.TransactionBuy, day(0), 5, 100, 0),
+			},
+			wantQuantity:          5,
+			wantInvested:          1100,
+			wantAverageCost:       110,
+			wantAverageBasis:      550,
+			wantAverageRealized:   5*130 - 5*110,
+			wantFIFOBasis:         600,
+			wantFIFORealized:      5*130 - 5*100,
+			wantOpenLots:          1,
+			marketPrice:           130,
+			wantAverageUnrealized: 650 - 550,
+			wantFIFOUnrealized:    650 - 600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := BuildPosition(tt.transactions)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			assertClose(t, "Quantity", position.Quantity, tt.wantQuantity)
+			assertClose(t, "TotalInvested", position.TotalInvested, tt.wantInvested)
+			assertClose(t, "TotalFees", position.TotalFees, tt.wantFees)
+			assertClose(t, "AverageCost", position.AverageCost, tt.wantAverageCost)
+			assertClose(t, "CostBasis(average)", position.CostBasis(MethodAverage), tt.wantAverageBasis)
+			assertClose(t, "RealizedPnL(average)", position.RealizedPnL(MethodAverage), tt.wantAverageRealized)
+			assertClose(t, "CostBasis(fifo)", position.CostBasis(MethodFIFO), tt.wantFIFOBasis)
+			assertClose(t, "RealizedPnL(fifo)", position.RealizedPnL(MethodFIFO), tt.wantFIFORealized)
+			assertClose(t, "UnrealizedPnL(average)", position.UnrealizedPnL(tt.marketPrice, MethodAverage), tt.wantAverageUnrealized)
+			assertClose(t, "UnrealizedPnL(fifo)", position.UnrealizedPnL(tt.marketPrice, MethodFIFO), tt.wantFIFOUnrealized)
+			if len(position.OpenLots) != tt.wantOpenLots {
+				t.Errorf("OpenLots = %d, se esperaban %d", len(position.OpenLots), tt.wantOpenLots)
+			}
+		})
+	}
+}
+
+func TestBuildPositionErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+	}{
+		{
+			name: "venta mayor a la posición",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 10, 100, 0),
+				transaction(models.TransactionSell, day(1), 11, 100, 0),
+			},
+		},
+		{
+			name: "venta anterior a la compra",
+			transactions: []models.Transaction{
+				transaction(models.TransactionSell, day(0), 5, 100, 0),
+				transaction(models.TransactionBuy, day(1), 10, 100, 0),
+			},
+		},
+		{
+			name: "dos ventas que juntas superan la posición",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 10, 100, 0),
+				transaction(models.TransactionSell, day(1), 6, 100, 0),
+				transaction(models.TransactionSell, day(1), 6, 100, 0),
+			},
+		},
+		{
+			name: "transferencia saliente sin unidades",
+			transactions: []models.Transaction{
+				transaction(models.TransactionTransferOut, day(0), 1, 0, 0),
+			},
+		},
+		{
+			name: "cantidad no positiva",
+			transactions: []models.Transaction{
+				transaction(models.TransactionBuy, day(0), 0, 100, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildPosition(tt.transactions); err == nil {
+				t.Fatal("se esperaba un error")
+			}
+		})
+	}
+}
+
+func TestSortTransactionsSameDate(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "venta", Type: models.TransactionSell, Date: day(0), Quantity: 5, Price: 100, CreatedAt: created.Add(time.Second)},
+		{ID: "compra", Type: models.TransactionBuy, Date: day(0), Quantity: 5, Price: 100, CreatedAt: created},
+	}
+
+	SortTransactions(transactions)
+	if transactions[0].ID != "compra" {
+		t.Errorf("a igual fecha se esperaba primero la cargada antes, se obtuvo %s", transactions[0].ID)
+	}
+
+	if _, err := BuildPosition(transactions); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GroupSnapshot guarda los totales de un grupo al final de cada ejecución del cron,
+// para leer el valor del portafolio sin agregar los snapshots de cada holding
+type GroupSnapshot struct {
+	ID                   string    `json:"id" gorm:"type:uuid;primary_key"`
+	GroupID              string    `json:"groupId" gorm:"type:uuid;not null;uniqueIndex:idx_group_snapshot_run;index:idx_group_snapshot_date;column:groupId"`
+	UserID               string    `json:"userId" gorm:"type:uuid;not null;index;column:userId"`
+	RunID                string    `json:"runId" gorm:"type:uuid;not null;uniqueIndex:idx_group_snapshot_run;column:runId"`
+	Currency             string    `json:"currency" gorm:"not null"`
+	TotalValue           float64   `json:"totalValue" gorm:"not null;default:0;column:totalValue"`
+	CostBasis            float64   `json:"costBasis" gorm:"not null;default:0;column:costBasis"` // Solo holdings con transacciones
+	Earnings             float64   `json:"earnings" gorm:"not null;default:0"`                   // Variación de los holdings actualizados en la ejecución
+	Holdings             int       `json:"holdings" gorm:"not null;default:0"`
+	HoldingsWithoutBasis int       `json:"holdingsWithoutBasis" gorm:"not null;default:0;column:holdingsWithoutBasis"`
+	CreatedAt            time.Time `json:"createdAt" gorm:"column:createdAt;index:idx_group_snapshot_date"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (s *GroupSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (GroupSnapshot) TableName() string {
+	return "GroupSnapshot"
+}
+
+// UserSnapshot guarda los totales de un usuario por moneda al final de cada ejecución del cron
+type UserSnapshot struct {
+	ID                   string    `json:"id" gorm:"type:uuid;primary_key"`
+	UserID               string    `json:"userId" gorm:"type:uuid;not null;uniqueIndex:idx_user_snapshot_run;index:idx_user_snapshot_date;column:userId"`
+	RunID                string    `json:"runId" gorm:"type:uuid;not null;uniqueIndex:idx_user_snapshot_run;column:runId"`
+	Currency             string    `json:"currency" gorm:"not null;uniqueIndex:idx_user_snapshot_run"`
+	TotalValue           float64   `json:"totalValue" gorm:"not null;default:0;column:totalValue"`
+	CostBasis            float64   `json:"costBasis" gorm:"not null;default:0;column:costBasis"`
+	Earnings             float64   `json:"earnings" gorm:"not null;default:0"`
+	Groups               int       `json:"groups" gorm:"not null;default:0"`
+	Holdings             int       `json:"holdings" gorm:"not null;default:0"`
+	HoldingsWithoutBasis int       `json:"holdingsWithoutBasis" gorm:"not null;default:0;column:holdingsWithoutBasis"`
+	CreatedAt            time.Time `json:"createdAt" gorm:"column:createdAt;index:idx_user_snapshot_date"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (s *UserSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (UserSnapshot) TableName() string {
+	return "UserSnapshot"
+}
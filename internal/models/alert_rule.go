@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de regla de alerta soportados
+const (
+	AlertPriceAbove        = "price_above"
+	AlertPriceBelow        = "price_below"
+	AlertPercentMove       = "percent_move"
+	AlertHoldingValueAbove = "holding_value_above"
+	AlertHoldingValueBelow = "holding_value_below"
+)
+
+// Canales de notificación de alertas
+const (
+	AlertChannelWebhook = "webhook"
+	AlertChannelEmail   = "email"
+)
+
+// AlertRule define una condición de precio o valor sobre un asset o holding de un usuario
+type AlertRule struct {
+	ID              string     `json:"id" gorm:"type:uuid;primary_key"`
+	UserID          string     `json:"userId" gorm:"type:uuid;not null;index;column:userId"`
+	AssetID         *string    `json:"assetId" gorm:"type:uuid;index;column:assetId"`
+	HoldingID       *string    `json:"holdingId" gorm:"type:uuid;index;column:holdingId"`
+	Type            string     `json:"type" gorm:"not null"`
+	Threshold       float64    `json:"threshold" gorm:"not null"`
+	Channel         string     `json:"channel" gorm:"not null;default:webhook"`
+	IsActive        bool       `json:"isActive" gorm:"not null;default:true;column:isActive"`
+	Triggered       bool       `json:"triggered" gorm:"not null;default:false"` // Evita repetir la alerta mientras la condición se mantenga
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt" gorm:"column:lastTriggeredAt"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (a *AlertRule) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (AlertRule) TableName() string {
+	return "AlertRule"
+}
+
+// IsHoldingRule indica si la regla se evalúa sobre el valor de un holding
+func (a *AlertRule) IsHoldingRule() bool {
+	return a.Type == AlertHoldingValueAbove || a.Type == AlertHoldingValueBelow
+}
+
+// Evaluate indica si la regla se cumple para el precio actual, el precio anterior y el valor del holding
+func (a *AlertRule) Evaluate(currentPrice, previousPrice, holdingValue float64) bool {
+	switch a.Type {
+	case AlertPriceAbove:
+		return currentPrice >= a.Threshold
+	case AlertPriceBelow:
+		return currentPrice <= a.Threshold
+	case AlertPercentMove:
+		if previousPrice <= 0 {
+			return false
+		}
+		change := ((currentPrice - previousPrice) / previousPrice) * 100
+		return change >= a.Threshold || change <= -a.Threshold
+	case AlertHoldingValueAbove:
+		return holdingValue >= a.Threshold
+	case AlertHoldingValueBelow:
+		return holdingValue <= a.Threshold
+	default:
+		return false
+	}
+}
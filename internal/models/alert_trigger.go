@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AlertTrigger registra cada vez que una regla de alerta se cumplió y su envío
+type AlertTrigger struct {
+	ID            string    `json:"id" gorm:"type:uuid;primary_key"`
+	RuleID        string    `json:"ruleId" gorm:"type:uuid;not null;index;column:ruleId"`
+	UserID        string    `json:"userId" gorm:"type:uuid;not null;index;column:userId"`
+	AssetID       string    `json:"assetId" gorm:"type:uuid;not null;column:assetId"`
+	HoldingID     *string   `json:"holdingId" gorm:"type:uuid;column:holdingId"`
+	Type          string    `json:"type" gorm:"not null"`
+	Threshold     float64   `json:"threshold" gorm:"not null"`
+	Price         float64   `json:"price" gorm:"not null"`
+	PreviousPrice float64   `json:"previousPrice" gorm:"column:previousPrice"`
+	HoldingValue  float64   `json:"holdingValue" gorm:"column:holdingValue"`
+	Message       string    `json:"message" gorm:"type:text"`
+	Channel       string    `json:"channel" gorm:"not null"`
+	Notified      bool      `json:"notified" gorm:"not null;default:false"`
+	NotifyError   string    `json:"notifyError" gorm:"type:text;column:notifyError"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (a *AlertTrigger) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (AlertTrigger) TableName() string {
+	return "AlertTrigger"
+}
@@ -11,7 +11,7 @@ import (
 type Asset struct {
 	ID        string         `json:"id" gorm:"type:uuid;primary_key"`
 	Name      string         `json:"name" gorm:"not null"`
-	Code      string         `json:"code" gorm:"not null;uniqueIndex"`
+	Code      string         `json:"code" gorm:"not null"` // Único por tipo de inversión sin distinguir mayúsculas (idx_asset_type_code)
 	LastPrice float64        `json:"lastPrice" gorm:"not null;column:lastPrice"`
 	CreatedAt time.Time      `json:"createdAt" gorm:"column:createdAt"`
 	TypeID    string         `json:"typeId" gorm:"type:uuid;not null;column:typeId"`
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Orígenes de un precio de asset
+const (
+	AssetPriceSourceScraper  = "scraper"
+	AssetPriceSourceBackfill = "backfill"
+)
+
+// AssetPrice es el precio de un asset en un momento dado. Se guarda una fila por scraping
+// exitoso, tenga o no holdings el asset, y los snapshots de holdings la referencian.
+type AssetPrice struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key"`
+	AssetID   string    `json:"assetId" gorm:"type:uuid;not null;index:idx_asset_price_date;uniqueIndex:idx_asset_price_source_date;column:assetId"`
+	Price     float64   `json:"price" gorm:"not null"`
+	Source    string    `json:"source" gorm:"not null;uniqueIndex:idx_asset_price_source_date"`
+	RunID     string    `json:"runId,omitempty" gorm:"column:runId"` // Ejecución del cron o backfill que lo registró
+	CreatedAt time.Time `json:"createdAt" gorm:"not null;index:idx_asset_price_date;uniqueIndex:idx_asset_price_source_date;column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (a *AssetPrice) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (AssetPrice) TableName() string {
+	return "AssetPrice"
+}
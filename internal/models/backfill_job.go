@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Estados de un backfill de precios
+const (
+	BackfillStatusPending   = "pending"
+	BackfillStatusRunning   = "running"
+	BackfillStatusCompleted = "completed"
+	BackfillStatusFailed    = "failed"
+)
+
+// BackfillJob registra el progreso de una carga de precios históricos de un asset
+type BackfillJob struct {
+	ID             string     `json:"id" gorm:"type:uuid;primary_key"`
+	AssetID        string     `json:"assetId" gorm:"type:uuid;not null;index;column:assetId"`
+	Symbol         string     `json:"symbol" gorm:"not null"`
+	Interval       string     `json:"interval" gorm:"not null"`
+	From           time.Time  `json:"from" gorm:"not null;column:fromDate"`
+	To             time.Time  `json:"to" gorm:"not null;column:toDate"`
+	Status         string     `json:"status" gorm:"not null;index"`
+	TotalPoints    int        `json:"totalPoints" gorm:"not null;default:0;column:totalPoints"`
+	InsertedPoints int        `json:"insertedPoints" gorm:"not null;default:0;column:insertedPoints"`
+	SkippedPoints  int        `json:"skippedPoints" gorm:"not null;default:0;column:skippedPoints"` // Ya existentes
+	Error          string     `json:"error,omitempty"`
+	StartedAt      *time.Time `json:"startedAt,omitempty" gorm:"column:startedAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty" gorm:"column:finishedAt"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (b *BackfillJob) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (BackfillJob) TableName() string {
+	return "BackfillJob"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Alcances a los que se puede asignar un benchmark
+const (
+	BenchmarkScopeGroup = "group"
+	BenchmarkScopeUser  = "user"
+)
+
+// Benchmark asocia un asset de referencia (por ejemplo SPY o MERVAL) a un grupo o a un usuario
+type Benchmark struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key"`
+	Scope     string    `json:"scope" gorm:"not null;uniqueIndex:idx_benchmark_owner"`
+	OwnerID   string    `json:"ownerId" gorm:"type:uuid;not null;uniqueIndex:idx_benchmark_owner;column:ownerId"`
+	AssetID   string    `json:"assetId" gorm:"type:uuid;not null;column:assetId"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (b *Benchmark) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (Benchmark) TableName() string {
+	return "Benchmark"
+}
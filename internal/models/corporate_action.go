@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de evento corporativo
+const (
+	CorporateActionSplit    = "split"
+	CorporateActionDividend = "dividend"
+)
+
+// Orígenes de un evento corporativo
+const (
+	CorporateActionSourceManual   = "manual"
+	CorporateActionSourceProvider = "provider"
+)
+
+// CorporateAction es un split o un dividendo en efectivo de un asset. Los splits se aplican una sola vez
+// (AppliedAt) ajustando cantidades y precios anteriores a ExDate; los dividendos generan DividendIncome.
+type CorporateAction struct {
+	ID        string     `json:"id" gorm:"type:uuid;primary_key"`
+	AssetID   string     `json:"assetId" gorm:"type:uuid;not null;uniqueIndex:idx_corporate_action_event;column:assetId"`
+	Type      string     `json:"type" gorm:"not null;uniqueIndex:idx_corporate_action_event"`
+	ExDate    time.Time  `json:"exDate" gorm:"not null;uniqueIndex:idx_corporate_action_event;column:exDate"`
+	Ratio     float64    `json:"ratio,omitempty" gorm:"not null;default:0"`  // Split: acciones nuevas por cada una anterior (4:1 = 4, 1:10 = 0.1)
+	Amount    float64    `json:"amount,omitempty" gorm:"not null;default:0"` // Dividendo: importe por acción
+	Currency  string     `json:"currency,omitempty"`
+	Source    string     `json:"source" gorm:"not null;default:manual"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty" gorm:"column:appliedAt"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (ca *CorporateAction) BeforeCreate(tx *gorm.DB) error {
+	if ca.ID == "" {
+		ca.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (CorporateAction) TableName() string {
+	return "CorporateAction"
+}
+
+// DividendIncome es el dividendo cobrado por un holding: las acciones que tenía al ex-date por el importe por acción
+type DividendIncome struct {
+	ID                string    `json:"id" gorm:"type:uuid;primary_key"`
+	HoldingID         string    `json:"holdingId" gorm:"type:uuid;not null;uniqueIndex:idx_dividend_income_action;column:holdingId"`
+	CorporateActionID string    `json:"corporateActionId" gorm:"type:uuid;not null;uniqueIndex:idx_dividend_income_action;column:corporateActionId"`
+	ExDate            time.Time `json:"exDate" gorm:"not null;index;column:exDate"`
+	Quantity          float64   `json:"quantity" gorm:"not null"`
+	AmountPerShare    float64   `json:"amountPerShare" gorm:"not null;column:amountPerShare"`
+	Amount            float64   `json:"amount" gorm:"not null"`
+	Currency          string    `json:"currency" gorm:"not null"`
+	CreatedAt         time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (di *DividendIncome) BeforeCreate(tx *gorm.DB) error {
+	if di.ID == "" {
+		di.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (DividendIncome) TableName() string {
+	return "DividendIncome"
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Variantes de tipo de cambio (el mercado argentino tiene varias cotizaciones del dólar)
+const (
+	FXVariantOfficial  = "official"
+	FXVariantMEP       = "mep"
+	FXVariantCCL       = "ccl"
+	FXVariantBlue      = "blue"
+	FXVariantWholesale = "wholesale"
+)
+
+// FXRate es una cotización observada: 1 unidad de Base equivale a Rate unidades de Quote
+type FXRate struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key"`
+	Base      string    `json:"base" gorm:"not null;index:idx_fx_pair"`
+	Quote     string    `json:"quote" gorm:"not null;index:idx_fx_pair"`
+	Variant   string    `json:"variant" gorm:"not null;index:idx_fx_pair"`
+	Rate      float64   `json:"rate" gorm:"not null"`
+	Source    string    `json:"source" gorm:"not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt;index:idx_fx_pair"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (f *FXRate) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (FXRate) TableName() string {
+	return "FXRate"
+}
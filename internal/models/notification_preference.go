@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationPreference guarda las preferencias de notificación de un usuario
+type NotificationPreference struct {
+	ID            string    `json:"id" gorm:"type:uuid;primary_key"`
+	UserID        string    `json:"userId" gorm:"type:uuid;not null;uniqueIndex;column:userId"`
+	WeeklySummary bool      `json:"weeklySummary" gorm:"not null;default:false;column:weeklySummary"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (n *NotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (NotificationPreference) TableName() string {
+	return "NotificationPreference"
+}
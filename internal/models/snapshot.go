@@ -15,6 +15,9 @@ type Snapshot struct {
 	Holding   Holding   `json:"holding" gorm:"foreignKey:HoldingID"`
 	Quantity  float64   `json:"quantity" gorm:"not null"` // Cantidad de holdings al momento del snapshot
 	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+
+	// Precio del asset del que se tomó Price (nil en snapshots anteriores a AssetPrice)
+	AssetPriceID *string `json:"assetPriceId,omitempty" gorm:"type:uuid;column:assetPriceId"`
 }
 
 // BeforeCreate hook de GORM para generar UUID antes de crear
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SnapshotPnL guarda el resultado de un holding en cada snapshot, conservando el historial
+// que Holding.Earnings pierde al sobrescribirse en cada ejecución
+type SnapshotPnL struct {
+	ID                     string    `json:"id" gorm:"type:uuid;primary_key"`
+	SnapshotID             string    `json:"snapshotId" gorm:"type:uuid;not null;uniqueIndex;column:snapshotId"`
+	HoldingID              string    `json:"holdingId" gorm:"type:uuid;not null;index;column:holdingId"`
+	Price                  float64   `json:"price" gorm:"not null"`
+	Quantity               float64   `json:"quantity" gorm:"not null"`
+	MarketValue            float64   `json:"marketValue" gorm:"not null;column:marketValue"`
+	HasCostBasis           bool      `json:"hasCostBasis" gorm:"not null;default:false;column:hasCostBasis"` // False si el holding no tiene transacciones
+	Method                 string    `json:"method" gorm:"not null"`
+	CostBasis              float64   `json:"costBasis" gorm:"not null;default:0;column:costBasis"`
+	RealizedPnL            float64   `json:"realizedPnL" gorm:"not null;default:0;column:realizedPnL"` // Acumulado hasta el snapshot
+	UnrealizedPnL          float64   `json:"unrealizedPnL" gorm:"not null;default:0;column:unrealizedPnL"`
+	PeriodEarnings         float64   `json:"periodEarnings" gorm:"not null;default:0;column:periodEarnings"`
+	PeriodRelativeEarnings float64   `json:"periodRelativeEarnings" gorm:"not null;default:0;column:periodRelativeEarnings"`
+	CreatedAt              time.Time `json:"createdAt" gorm:"column:createdAt;index"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (s *SnapshotPnL) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (SnapshotPnL) TableName() string {
+	return "SnapshotPnL"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Granularidades de los agregados de snapshots
+const (
+	RollupGranularityMonth   = "month"
+	RollupGranularityQuarter = "quarter"
+)
+
+// SnapshotRollup resume los snapshots de un holding en un mes o trimestre ya fuera del período
+// de resolución completa. De cada período solo se conserva en Snapshot el último (CloseSnapshotID).
+type SnapshotRollup struct {
+	ID              string    `json:"id" gorm:"type:uuid;primary_key"`
+	HoldingID       string    `json:"holdingId" gorm:"type:uuid;not null;uniqueIndex:idx_snapshot_rollup_period;column:holdingId"`
+	Granularity     string    `json:"granularity" gorm:"not null;uniqueIndex:idx_snapshot_rollup_period"`
+	PeriodStart     time.Time `json:"periodStart" gorm:"not null;uniqueIndex:idx_snapshot_rollup_period;column:periodStart"`
+	PeriodEnd       time.Time `json:"periodEnd" gorm:"not null;column:periodEnd"` // Exclusivo
+	OpenValue       float64   `json:"openValue" gorm:"not null;column:openValue"`
+	CloseValue      float64   `json:"closeValue" gorm:"not null;column:closeValue"`
+	MinValue        float64   `json:"minValue" gorm:"not null;column:minValue"`
+	MaxValue        float64   `json:"maxValue" gorm:"not null;column:maxValue"`
+	ClosePrice      float64   `json:"closePrice" gorm:"not null;column:closePrice"`
+	CloseQuantity   float64   `json:"closeQuantity" gorm:"not null;column:closeQuantity"`
+	Snapshots       int       `json:"snapshots" gorm:"not null"` // Snapshots originales resumidos
+	FirstSnapshotAt time.Time `json:"firstSnapshotAt" gorm:"not null;column:firstSnapshotAt"`
+	LastSnapshotAt  time.Time `json:"lastSnapshotAt" gorm:"not null;column:lastSnapshotAt"`
+	CloseSnapshotID *string   `json:"closeSnapshotId,omitempty" gorm:"type:uuid;column:closeSnapshotId"`
+	CreatedAt       time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (r *SnapshotRollup) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (SnapshotRollup) TableName() string {
+	return "SnapshotRollup"
+}
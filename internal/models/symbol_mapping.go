@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SymbolMappingProviderAlias es el proveedor de los mapeos que solo traducen una entrada de usuario
+const SymbolMappingProviderAlias = "alias"
+
+// SymbolMapping relaciona el código canónico de un asset de un tipo de inversión con el símbolo que
+// usa un proveedor (ej. BRK.B -> BRK-B en yahoo) o con un alias que pueden ingresar los usuarios.
+type SymbolMapping struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key"`
+	TypeID    string    `json:"typeId" gorm:"type:uuid;not null;uniqueIndex:idx_symbol_mapping_provider_symbol;column:typeId"`
+	Code      string    `json:"code" gorm:"not null;index"`                                              // Código canónico
+	Provider  string    `json:"provider" gorm:"not null;uniqueIndex:idx_symbol_mapping_provider_symbol"` // yahoo o alias
+	Symbol    string    `json:"symbol" gorm:"not null;uniqueIndex:idx_symbol_mapping_provider_symbol"`   // En mayúsculas
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (sm *SymbolMapping) BeforeCreate(tx *gorm.DB) error {
+	if sm.ID == "" {
+		sm.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (SymbolMapping) TableName() string {
+	return "SymbolMapping"
+}
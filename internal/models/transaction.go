@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de transacción de un holding
+const (
+	TransactionBuy         = "buy"
+	TransactionSell        = "sell"
+	TransactionTransferIn  = "transfer_in"
+	TransactionTransferOut = "transfer_out"
+)
+
+// Transaction representa un movimiento (compra, venta o transferencia) de un holding
+type Transaction struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key"`
+	HoldingID string    `json:"holdingId" gorm:"type:uuid;not null;index;column:holdingId"`
+	Type      string    `json:"type" gorm:"not null"`
+	Date      time.Time `json:"date" gorm:"not null;index"`
+	Quantity  float64   `json:"quantity" gorm:"not null"`
+	Price     float64   `json:"price" gorm:"not null;default:0"` // Precio unitario de la operación
+	Fees      float64   `json:"fees" gorm:"not null;default:0"`
+	Currency  string    `json:"currency" gorm:"not null"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (Transaction) TableName() string {
+	return "Transaction"
+}
+
+// IsInflow indica si la transacción suma unidades al holding
+func (t *Transaction) IsInflow() bool {
+	return t.Type == TransactionBuy || t.Type == TransactionTransferIn
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Estados posibles de una entrega de webhook
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusRetrying  = "retrying"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// WebhookDelivery registra cada envío de un webhook saliente y su resultado
+type WebhookDelivery struct {
+	ID             string     `json:"id" gorm:"type:uuid;primary_key"`
+	Event          string     `json:"event" gorm:"not null;index"`
+	URL            string     `json:"url" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"` // JSON firmado tal cual se envió
+	Status         string     `json:"status" gorm:"not null;default:pending;index"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"responseStatus" gorm:"column:responseStatus"`
+	LastError      string     `json:"lastError" gorm:"column:lastError;type:text"`
+	ReplayOfID     *string    `json:"replayOfId" gorm:"type:uuid;column:replayOfId"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt" gorm:"column:nextAttemptAt"` // Próximo reintento si está en retrying
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt" gorm:"column:deliveredAt"`
+}
+
+// BeforeCreate hook de GORM para generar UUID antes de crear
+func (w *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla
+func (WebhookDelivery) TableName() string {
+	return "WebhookDelivery"
+}
@@ -0,0 +1,139 @@
+package performance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ComparisonPoint es el rendimiento acumulado del portafolio y del benchmark a una fecha, en %
+type ComparisonPoint struct {
+	Date      time.Time `json:"date"`
+	Portfolio float64   `json:"portfolio"`
+	Benchmark float64   `json:"benchmark"`
+}
+
+// Comparison compara los rendimientos de un portafolio contra un benchmark en las mismas fechas.
+// Rendimientos, alpha y tracking error se expresan en porcentaje anual salvo los acumulados.
+type Comparison struct {
+	Periods          int               `json:"periods"`
+	PeriodsPerYear   float64           `json:"periodsPerYear"`
+	PortfolioReturn  float64           `json:"portfolioReturn"` // Acumulado del rango
+	BenchmarkReturn  float64           `json:"benchmarkReturn"` // Acumulado del rango
+	ExcessReturn     float64           `json:"excessReturn"`
+	Alpha            *float64          `json:"alpha"`
+	Beta             *float64          `json:"beta"`
+	TrackingError    float64           `json:"trackingError"`
+	InformationRatio *float64          `json:"informationRatio"`
+	Points           []ComparisonPoint `json:"points"`
+}
+
+// CompareToBenchmark alinea los sub-períodos del portafolio con los precios del benchmark
+// (último precio conocido a cada fecha) y calcula alpha, beta y tracking error.
+// Los períodos sin precio del benchmark al inicio se omiten.
+func CompareToBenchmark(series Series, benchmarkPrices []Valuation, riskFreeRate float64) (*Comparison, error) {
+	prices := sortedValuations(benchmarkPrices)
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("el benchmark no tiene precios registrados")
+	}
+
+	var portfolioReturns, benchmarkReturns []float64
+	var points []ComparisonPoint
+	portfolioGrowth, benchmarkGrowth := 1.0, 1.0
+
+	for _, period := range PeriodReturns(series.Valuations, series.Flows) {
+		startPrice, ok := valueAt(prices, period.Start)
+		if !ok || startPrice <= 0 {
+			continue
+		}
+		endPrice, _ := valueAt(prices, period.End)
+
+		benchmarkReturn := endPrice/startPrice - 1
+
+		if len(points) == 0 {
+			points = append(points, ComparisonPoint{Date: period.Start})
+		}
+
+		portfolioGrowth *= 1 + period.Return
+		benchmarkGrowth *= 1 + benchmarkReturn
+		portfolioReturns = append(portfolioReturns, period.Return)
+		benchmarkReturns = append(benchmarkReturns, benchmarkReturn)
+
+		points = append(points, ComparisonPoint{
+			Date:      period.End,
+			Portfolio: (portfolioGrowth - 1) * 100,
+			Benchmark: (benchmarkGrowth - 1) * 100,
+		})
+	}
+
+	if len(portfolioReturns) < 2 {
+		return nil, fmt.Errorf("no hay suficientes fechas en común con el benchmark para comparar")
+	}
+
+	periodsPerYear := PeriodsPerYear(points[0].Date, points[len(points)-1].Date, len(portfolioReturns))
+	comparison := &Comparison{
+		Periods:         len(portfolioReturns),
+		PeriodsPerYear:  periodsPerYear,
+		PortfolioReturn: (portfolioGrowth - 1) * 100,
+		BenchmarkReturn: (benchmarkGrowth - 1) * 100,
+		Points:          points,
+	}
+	comparison.ExcessReturn = comparison.PortfolioReturn - comparison.BenchmarkReturn
+
+	// Tracking error: desvío de la diferencia de rendimientos
+	differences := make([]float64, len(portfolioReturns))
+	for i := range portfolioReturns {
+		differences[i] = portfolioReturns[i] - benchmarkReturns[i]
+	}
+
+	trackingError := standardDeviation(differences) * math.Sqrt(periodsPerYear)
+	comparison.TrackingError = trackingError * 100
+	if trackingError > 0 {
+		ratio := mean(differences) * periodsPerYear / trackingError
+		comparison.InformationRatio = &ratio
+	}
+
+	// Beta y alpha de Jensen sobre excesos contra la tasa libre de riesgo
+	benchmarkVariance := variance(benchmarkReturns)
+	if benchmarkVariance > 0 {
+		beta := covariance(portfolioReturns, benchmarkReturns) / benchmarkVariance
+		periodRiskFree := math.Pow(1+riskFreeRate/100, 1/periodsPerYear) - 1
+		alpha := ((mean(portfolioReturns) - periodRiskFree) - beta*(mean(benchmarkReturns)-periodRiskFree)) * periodsPerYear * 100
+
+		comparison.Beta = &beta
+		comparison.Alpha = &alpha
+	}
+
+	return comparison, nil
+}
+
+// valueAt retorna el último valor anterior o igual a la fecha
+func valueAt(valuations []Valuation, at time.Time) (float64, bool) {
+	idx := sort.Search(len(valuations), func(i int) bool {
+		return valuations[i].Date.After(at)
+	})
+	if idx == 0 {
+		return 0, false
+	}
+	return valuations[idx-1].Value, true
+}
+
+// variance calcula la varianza muestral
+func variance(values []float64) float64 {
+	std := standardDeviation(values)
+	return std * std
+}
+
+// covariance calcula la covarianza muestral de dos series de igual longitud
+func covariance(a, b []float64) float64 {
+	if len(a) < 2 || len(a) != len(b) {
+		return 0
+	}
+	meanA, meanB := mean(a), mean(b)
+	total := 0.0
+	for i := range a {
+		total += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return total / float64(len(a)-1)
+}
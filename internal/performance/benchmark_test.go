@@ -0,0 +1,119 @@
+package performance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareToBenchmark(t *testing.T) {
+	start := date(2024, 1, 1)
+	periodsPerYear := 365.0 / 7
+	benchmarkReturns := []float64{0.01, -0.02, 0.03, 0.015}
+
+	tests := []struct {
+		name              string
+		portfolioReturns  []float64
+		riskFreeRate      float64
+		wantBeta          float64
+		wantAlpha         float64
+		wantTrackingError float64
+	}{
+		{
+			name:              "igual al benchmark",
+			portfolioReturns:  benchmarkReturns,
+			wantBeta:          1,
+			wantAlpha:         0,
+			wantTrackingError: 0,
+		},
+		{
+			// Rendimientos al doble: beta 2, sin alpha con tasa libre de riesgo cero
+			name:              "apalancado al doble",
+			portfolioReturns:  []float64{0.02, -0.04, 0.06, 0.03},
+			wantBeta:          2,
+			wantAlpha:         0,
+			wantTrackingError: standardDeviation(benchmarkReturns) * math.Sqrt(periodsPerYear) * 100,
+		},
+		{
+			// Un punto básico por período sobre el benchmark: beta 1 y alpha anualizado
+			name:              "exceso constante",
+			portfolioReturns:  []float64{0.0101, -0.0199, 0.0301, 0.0151},
+			wantBeta:          1,
+			wantAlpha:         0.0001 * periodsPerYear * 100,
+			wantTrackingError: 0,
+		},
+		{
+			// Con tasa libre de riesgo, alpha de Jensen = (Rp - rf) - beta (Rb - rf)
+			name:              "apalancado con tasa libre de riesgo",
+			portfolioReturns:  []float64{0.02, -0.04, 0.06, 0.03},
+			riskFreeRate:      5,
+			wantBeta:          2,
+			wantAlpha:         (math.Pow(1.05, 1/periodsPerYear) - 1) * periodsPerYear * 100,
+			wantTrackingError: standardDeviation(benchmarkReturns) * math.Sqrt(periodsPerYear) * 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := Series{Valuations: weekly(start, 1000, tt.portfolioReturns)}
+			prices := weekly(start, 50, benchmarkReturns)
+
+			comparison, err := CompareToBenchmark(series, prices, tt.riskFreeRate)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			if comparison.Periods != len(benchmarkReturns) {
+				t.Errorf("Periods = %d, se esperaban %d", comparison.Periods, len(benchmarkReturns))
+			}
+			if comparison.Beta == nil || comparison.Alpha == nil {
+				t.Fatal("se esperaban beta y alpha")
+			}
+			assertClose(t, "Beta", *comparison.Beta, tt.wantBeta)
+			assertClose(t, "Alpha", *comparison.Alpha, tt.wantAlpha)
+			assertClose(t, "TrackingError", comparison.TrackingError, tt.wantTrackingError)
+			assertClose(t, "ExcessReturn", comparison.ExcessReturn, comparison.PortfolioReturn-comparison.BenchmarkReturn)
+		})
+	}
+}
+
+func TestCompareToBenchmarkAlignment(t *testing.T) {
+	start := date(2024, 1, 1)
+	series := Series{Valuations: weekly(start, 1000, []float64{0.01, 0.02, -0.01, 0.03})}
+
+	// El benchmark empieza una semana después: el primer período se omite
+	prices := weekly(start.AddDate(0, 0, 7), 50, []float64{0.02, -0.01, 0.03})
+
+	comparison, err := CompareToBenchmark(series, prices, 0)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	if comparison.Periods != 3 {
+		t.Errorf("Periods = %d, se esperaban 3", comparison.Periods)
+	}
+	if !comparison.Points[0].Date.Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("el primer punto es %s, se esperaba %s", comparison.Points[0].Date, start.AddDate(0, 0, 7))
+	}
+	assertClose(t, "Beta", *comparison.Beta, 1)
+}
+
+func TestCompareToBenchmarkErrors(t *testing.T) {
+	start := date(2024, 1, 1)
+	series := Series{Valuations: weekly(start, 1000, []float64{0.01, 0.02, -0.01})}
+
+	tests := []struct {
+		name   string
+		prices []Valuation
+	}{
+		{name: "benchmark sin precios"},
+		{name: "sin fechas en común", prices: weekly(start.AddDate(1, 0, 0), 50, []float64{0.01})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompareToBenchmark(series, tt.prices, 0); err == nil {
+				t.Fatal("se esperaba un error")
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package performance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// daysPerYear es la base usada para anualizar rendimientos y descontar flujos en XIRR
+const daysPerYear = 365.0
+
+// Valuation es el valor de mercado de un portafolio o holding en una fecha
+type Valuation struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// CashFlow es un aporte (Amount > 0) o retiro (Amount < 0) de dinero del portafolio
+type CashFlow struct {
+	Date   time.Time `json:"date"`
+	Amount float64   `json:"amount"`
+}
+
+// PeriodReturn es el rendimiento entre dos valuaciones consecutivas, sin el efecto de los flujos
+type PeriodReturn struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Return float64   `json:"return"`
+}
+
+// Series reúne las valuaciones y los flujos de un holding o portafolio
+type Series struct {
+	Valuations []Valuation
+	Flows      []CashFlow
+}
+
+// Result es el rendimiento de una serie en el rango de sus valuaciones.
+// Los rendimientos se expresan en porcentaje, igual que RelativeEarnings.
+type Result struct {
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Valuations    int       `json:"valuations"`
+	StartValue    float64   `json:"startValue"`
+	EndValue      float64   `json:"endValue"`
+	NetCashFlow   float64   `json:"netCashFlow"`
+	Gain          float64   `json:"gain"`          // EndValue - StartValue - NetCashFlow
+	TWR           float64   `json:"twr"`           // Rendimiento ponderado por tiempo del rango
+	AnnualizedTWR float64   `json:"annualizedTwr"` // TWR anualizado (igual a TWR en rangos menores a un año)
+	MWR           *float64  `json:"mwr"`           // XIRR anual; nil si no converge o no hay flujos suficientes
+}
+
+// Compute calcula TWR y MWR de una serie. Los flujos anteriores o iguales a la primera
+// valuación se consideran incluidos en ella y los posteriores a la última se ignoran.
+func Compute(series Series) (*Result, error) {
+	valuations := sortedValuations(series.Valuations)
+	if len(valuations) < 2 {
+		return nil, fmt.Errorf("se necesitan al menos dos valuaciones para calcular rendimientos")
+	}
+
+	first := valuations[0]
+	last := valuations[len(valuations)-1]
+	flows := flowsBetween(series.Flows, first.Date, last.Date)
+
+	result := &Result{
+		From:       first.Date,
+		To:         last.Date,
+		Valuations: len(valuations),
+		StartValue: first.Value,
+		EndValue:   last.Value,
+	}
+
+	for _, flow := range flows {
+		result.NetCashFlow += flow.Amount
+	}
+	result.Gain = result.EndValue - result.StartValue - result.NetCashFlow
+
+	twr, err := TWR(valuations, flows)
+	if err != nil {
+		return nil, err
+	}
+	result.TWR = twr * 100
+	result.AnnualizedTWR = Annualize(twr, first.Date, last.Date) * 100
+
+	if mwr, err := MWR(valuations, flows); err == nil {
+		mwr *= 100
+		result.MWR = &mwr
+	}
+
+	return result, nil
+}
+
+// TWR calcula el rendimiento ponderado por tiempo encadenando los sub-períodos entre valuaciones
+func TWR(valuations []Valuation, flows []CashFlow) (float64, error) {
+	if len(valuations) < 2 {
+		return 0, fmt.Errorf("se necesitan al menos dos valuaciones para calcular TWR")
+	}
+
+	growth := 1.0
+	for _, period := range PeriodReturns(valuations, flows) {
+		growth *= 1 + period.Return
+	}
+
+	if growth < 0 {
+		return 0, fmt.Errorf("los flujos superan el valor del portafolio; TWR indefinido")
+	}
+
+	return growth - 1, nil
+}
+
+// PeriodReturns calcula el rendimiento de cada sub-período entre valuaciones consecutivas.
+// Cada flujo se asume ocurrido al final del sub-período que lo contiene: r = (V_i - F_i) / V_{i-1} - 1.
+// Los sub-períodos que comienzan con valor cero se omiten.
+func PeriodReturns(valuations []Valuation, flows []CashFlow) []PeriodReturn {
+	valuations = sortedValuations(valuations)
+	flows = sortedFlows(flows)
+
+	var returns []PeriodReturn
+	if len(valuations) < 2 {
+		return returns
+	}
+
+	next := 0
+
+	// Saltear flujos incluidos en la valuación inicial
+	for next < len(flows) && !flows[next].Date.After(valuations[0].Date) {
+		next++
+	}
+
+	for i := 1; i < len(valuations); i++ {
+		periodFlow := 0.0
+		for next < len(flows) && !flows[next].Date.After(valuations[i].Date) {
+			periodFlow += flows[next].Amount
+			next++
+		}
+
+		start := valuations[i-1].Value
+		if start <= 0 {
+			continue
+		}
+
+		returns = append(returns, PeriodReturn{
+			Start:  valuations[i-1].Date,
+			End:    valuations[i].Date,
+			Return: (valuations[i].Value-periodFlow)/start - 1,
+		})
+	}
+
+	return returns
+}
+
+// MWR calcula el rendimiento ponderado por dinero (XIRR anual) desde el punto de vista del inversor:
+// el valor inicial y los aportes son salidas, los retiros y el valor final son entradas.
+func MWR(valuations []Valuation, flows []CashFlow) (float64, error) {
+	valuations = sortedValuations(valuations)
+	if len(valuations) < 2 {
+		return 0, fmt.Errorf("se necesitan al menos dos valuaciones para calcular MWR")
+	}
+
+	first := valuations[0]
+	last := valuations[len(valuations)-1]
+
+	investor := []CashFlow{{Date: first.Date, Amount: -first.Value}}
+	for _, flow := range flowsBetween(flows, first.Date, last.Date) {
+		investor = append(investor, CashFlow{Date: flow.Date, Amount: -flow.Amount})
+	}
+	investor = append(investor, CashFlow{Date: last.Date, Amount: last.Value})
+
+	return XIRR(investor)
+}
+
+// XIRR calcula la tasa anual que hace cero el valor presente de flujos con fechas irregulares.
+// Usa Newton-Raphson y, si no converge, bisección.
+func XIRR(flows []CashFlow) (float64, error) {
+	flows = sortedFlows(flows)
+
+	hasPositive, hasNegative := false, false
+	for _, flow := range flows {
+		if flow.Amount > 0 {
+			hasPositive = true
+		}
+		if flow.Amount < 0 {
+			hasNegative = true
+		}
+	}
+	if !hasPositive || !hasNegative {
+		return 0, fmt.Errorf("XIRR requiere al menos un flujo positivo y uno negativo")
+	}
+
+	start := flows[0].Date
+	years := make([]float64, len(flows))
+	for i, flow := range flows {
+		years[i] = flow.Date.Sub(start).Hours() / 24 / daysPerYear
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for i, flow := range flows {
+			total += flow.Amount / math.Pow(1+rate, years[i])
+		}
+		return total
+	}
+
+	derivative := func(rate float64) float64 {
+		total := 0.0
+		for i, flow := range flows {
+			total -= years[i] * flow.Amount / math.Pow(1+rate, years[i]+1)
+		}
+		return total
+	}
+
+	// Newton-Raphson
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value := npv(rate)
+		if math.Abs(value) < 1e-7 {
+			return rate, nil
+		}
+
+		slope := derivative(rate)
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			break
+		}
+
+		nextRate := rate - value/slope
+		if nextRate <= -1 || math.IsNaN(nextRate) || math.IsInf(nextRate, 0) {
+			break
+		}
+
+		if math.Abs(nextRate-rate) < 1e-10 {
+			return nextRate, nil
+		}
+		rate = nextRate
+	}
+
+	// Bisección sobre un intervalo que se amplía hasta encontrar un cambio de signo
+	low, high := -0.9999, 1.0
+	for npv(low)*npv(high) > 0 {
+		high *= 2
+		if high > 1e6 {
+			return 0, fmt.Errorf("XIRR no converge")
+		}
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		value := npv(mid)
+		if math.Abs(value) < 1e-7 || (high-low)/2 < 1e-10 {
+			return mid, nil
+		}
+		if npv(low)*value < 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return (low + high) / 2, nil
+}
+
+// Annualize convierte un rendimiento del período en anual. Para rangos menores a un año
+// retorna el rendimiento sin modificar, ya que anualizarlo exagera movimientos cortos.
+func Annualize(ret float64, from, to time.Time) float64 {
+	days := to.Sub(from).Hours() / 24
+	if days < daysPerYear || ret <= -1 {
+		return ret
+	}
+	return math.Pow(1+ret, daysPerYear/days) - 1
+}
+
+// Combine suma varias series en una sola. En cada fecha el valor de cada serie es su última
+// valuación conocida (cero antes de la primera); los flujos se concatenan.
+func Combine(series []Series) Series {
+	dateSet := make(map[time.Time]bool)
+	for _, s := range series {
+		for _, valuation := range s.Valuations {
+			dateSet[valuation.Date] = true
+		}
+	}
+
+	dates := make([]time.Time, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	combined := Series{Valuations: make([]Valuation, len(dates))}
+	for i, date := range dates {
+		combined.Valuations[i].Date = date
+	}
+
+	for _, s := range series {
+		valuations := sortedValuations(s.Valuations)
+		idx := 0
+		current := 0.0
+		for i, date := range dates {
+			for idx < len(valuations) && !valuations[idx].Date.After(date) {
+				current = valuations[idx].Value
+				idx++
+			}
+			combined.Valuations[i].Value += current
+		}
+
+		combined.Flows = append(combined.Flows, s.Flows...)
+	}
+
+	combined.Flows = sortedFlows(combined.Flows)
+	return combined
+}
+
+// flowsBetween retorna los flujos en el intervalo (from, to]
+func flowsBetween(flows []CashFlow, from, to time.Time) []CashFlow {
+	var result []CashFlow
+	for _, flow := range sortedFlows(flows) {
+		if flow.Date.After(from) && !flow.Date.After(to) {
+			result = append(result, flow)
+		}
+	}
+	return result
+}
+
+func sortedValuations(valuations []Valuation) []Valuation {
+	sorted := make([]Valuation, len(valuations))
+	copy(sorted, valuations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return sorted
+}
+
+func sortedFlows(flows []CashFlow) []CashFlow {
+	sorted := make([]CashFlow, len(flows))
+	copy(sorted, flows)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return sorted
+}
@@ -0,0 +1,304 @@
+package performance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const tolerance = 1e-6
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// weekly arma valuaciones semanales desde start aplicando los rendimientos en orden
+func weekly(start time.Time, value float64, returns []float64) []Valuation {
+	valuations := []Valuation{{Date: start, Value: value}}
+	for i, ret := range returns {
+		value *= 1 + ret
+		valuations = append(valuations, Valuation{Date: start.AddDate(0, 0, 7*(i+1)), Value: value})
+	}
+	return valuations
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.10f, se esperaba %.10f", name, got, want)
+	}
+}
+
+func TestXIRR(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{
+			// Ejemplo de la documentación de XIRR de Excel
+			name: "referencia Excel",
+			flows: []CashFlow{
+				{Date: date(2008, 1, 1), Amount: -10000},
+				{Date: date(2008, 3, 1), Amount: 2750},
+				{Date: date(2008, 10, 30), Amount: 4250},
+				{Date: date(2009, 2, 15), Amount: 3250},
+				{Date: date(2009, 4, 1), Amount: 2750},
+			},
+			want: 0.373362535,
+		},
+		{
+			name: "un año exacto",
+			flows: []CashFlow{
+				{Date: date(2021, 1, 1), Amount: -1000},
+				{Date: date(2022, 1, 1), Amount: 1100},
+			},
+			want: 0.10,
+		},
+		{
+			name: "pérdida",
+			flows: []CashFlow{
+				{Date: date(2021, 1, 1), Amount: -1000},
+				{Date: date(2022, 1, 1), Amount: 900},
+			},
+			want: -0.10,
+		},
+		{
+			name: "dos aportes",
+			flows: []CashFlow{
+				{Date: date(2021, 1, 1), Amount: -1000},
+				{Date: date(2022, 1, 1), Amount: -1000},
+				{Date: date(2023, 1, 1), Amount: 2310},
+			},
+			want: 0.10,
+		},
+		{
+			name: "flujos desordenados",
+			flows: []CashFlow{
+				{Date: date(2022, 1, 1), Amount: 1100},
+				{Date: date(2021, 1, 1), Amount: -1000},
+			},
+			want: 0.10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := XIRR(tt.flows)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			assertClose(t, "XIRR", got, tt.want)
+		})
+	}
+}
+
+func TestXIRRErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+	}{
+		{name: "sin flujos", flows: nil},
+		{
+			name: "solo negativos",
+			flows: []CashFlow{
+				{Date: date(2021, 1, 1), Amount: -1000},
+				{Date: date(2022, 1, 1), Amount: -100},
+			},
+		},
+		{
+			name: "solo positivos",
+			flows: []CashFlow{
+				{Date: date(2021, 1, 1), Amount: 1000},
+				{Date: date(2022, 1, 1), Amount: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := XIRR(tt.flows); err == nil {
+				t.Fatal("se esperaba un error sin cambio de signo")
+			}
+		})
+	}
+}
+
+func TestTWR(t *testing.T) {
+	tests := []struct {
+		name       string
+		valuations []Valuation
+		flows      []CashFlow
+		want       float64
+	}{
+		{
+			name: "sin flujos",
+			valuations: []Valuation{
+				{Date: date(2024, 1, 1), Value: 1000},
+				{Date: date(2024, 2, 1), Value: 1100},
+				{Date: date(2024, 3, 1), Value: 990},
+			},
+			want: -0.01,
+		},
+		{
+			// Aporte de 100 a mitad del primer período y retiro de 300 en el segundo:
+			// (1200-100)/1000 = 1.10 y (1000+300)/1200 = 1.0833...
+			name: "aporte y retiro intermedios",
+			valuations: []Valuation{
+				{Date: date(2024, 1, 1), Value: 1000},
+				{Date: date(2024, 2, 1), Value: 1200},
+				{Date: date(2024, 3, 1), Value: 1000},
+			},
+			flows: []CashFlow{
+				{Date: date(2023, 12, 15), Amount: 500}, // Incluido en la valuación inicial
+				{Date: date(2024, 1, 15), Amount: 100},
+				{Date: date(2024, 2, 20), Amount: -300},
+				{Date: date(2024, 4, 1), Amount: 1000}, // Posterior a la última valuación
+			},
+			want: 1.10*(1300.0/1200.0) - 1,
+		},
+		{
+			name: "período que empieza en cero",
+			valuations: []Valuation{
+				{Date: date(2024, 1, 1), Value: 0},
+				{Date: date(2024, 2, 1), Value: 500},
+				{Date: date(2024, 3, 1), Value: 550},
+			},
+			flows: []CashFlow{
+				{Date: date(2024, 1, 10), Amount: 500},
+			},
+			want: 0.10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TWR(tt.valuations, tt.flows)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			assertClose(t, "TWR", got, tt.want)
+		})
+	}
+}
+
+func TestTWRErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		valuations []Valuation
+		flows      []CashFlow
+	}{
+		{name: "sin valuaciones"},
+		{
+			name:       "una sola valuación",
+			valuations: []Valuation{{Date: date(2024, 1, 1), Value: 1000}},
+		},
+		{
+			name: "retiros que superan el valor",
+			valuations: []Valuation{
+				{Date: date(2024, 1, 1), Value: 100},
+				{Date: date(2024, 2, 1), Value: 10},
+			},
+			flows: []CashFlow{{Date: date(2024, 1, 15), Amount: 200}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TWR(tt.valuations, tt.flows); err == nil {
+				t.Fatal("se esperaba un error")
+			}
+		})
+	}
+}
+
+func TestMWR(t *testing.T) {
+	valuations := []Valuation{
+		{Date: date(2021, 1, 1), Value: 1000},
+		{Date: date(2023, 1, 1), Value: 2310},
+	}
+	flows := []CashFlow{{Date: date(2022, 1, 1), Amount: 1000}}
+
+	got, err := MWR(valuations, flows)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	assertClose(t, "MWR", got, 0.10)
+
+	if _, err := MWR(valuations[:1], flows); err == nil {
+		t.Fatal("se esperaba un error con una sola valuación")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	series := Series{
+		Valuations: []Valuation{
+			{Date: date(2021, 1, 1), Value: 1000},
+			{Date: date(2023, 1, 1), Value: 2310},
+		},
+		Flows: []CashFlow{{Date: date(2022, 1, 1), Amount: 1000}},
+	}
+
+	result, err := Compute(series)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	assertClose(t, "NetCashFlow", result.NetCashFlow, 1000)
+	assertClose(t, "Gain", result.Gain, 310)
+	assertClose(t, "TWR", result.TWR, 31) // (2310 - 1000) / 1000 - 1
+	assertClose(t, "AnnualizedTWR", result.AnnualizedTWR, (math.Pow(1.31, 365.0/730)-1)*100)
+	if result.MWR == nil {
+		t.Fatal("se esperaba MWR")
+	}
+	assertClose(t, "MWR", *result.MWR, 10)
+
+	if _, err := Compute(Series{Valuations: series.Valuations[:1]}); err == nil {
+		t.Fatal("se esperaba un error con menos de dos valuaciones")
+	}
+}
+
+func TestAnnualize(t *testing.T) {
+	tests := []struct {
+		name     string
+		ret      float64
+		from, to time.Time
+		want     float64
+	}{
+		{name: "menos de un año", ret: 0.05, from: date(2024, 1, 1), to: date(2024, 6, 1), want: 0.05},
+		{name: "dos años", ret: 0.21, from: date(2021, 1, 1), to: date(2023, 1, 1), want: 0.10},
+		{name: "pérdida total", ret: -1, from: date(2021, 1, 1), to: date(2023, 1, 1), want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "Annualize", Annualize(tt.ret, tt.from, tt.to), tt.want)
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	a := Series{
+		Valuations: []Valuation{
+			{Date: date(2024, 1, 1), Value: 100},
+			{Date: date(2024, 1, 15), Value: 110},
+		},
+		Flows: []CashFlow{{Date: date(2024, 1, 10), Amount: 5}},
+	}
+	b := Series{
+		Valuations: []Valuation{{Date: date(2024, 1, 8), Value: 50}},
+	}
+
+	combined := Combine([]Series{a, b})
+
+	want := []float64{100, 150, 160}
+	if len(combined.Valuations) != len(want) {
+		t.Fatalf("valuaciones = %d, se esperaban %d", len(combined.Valuations), len(want))
+	}
+	for i, value := range want {
+		assertClose(t, combined.Valuations[i].Date.Format("2006-01-02"), combined.Valuations[i].Value, value)
+	}
+	if len(combined.Flows) != 1 {
+		t.Fatalf("flujos = %d, se esperaba 1", len(combined.Flows))
+	}
+}
@@ -0,0 +1,175 @@
+package performance
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RiskMetrics son las métricas de riesgo de una serie. Rendimientos, volatilidad y drawdown
+// se expresan en porcentaje; Sharpe y Sortino son nil si no hay dispersión para calcularlos.
+type RiskMetrics struct {
+	From                 time.Time  `json:"from"`
+	To                   time.Time  `json:"to"`
+	Periods              int        `json:"periods"`
+	PeriodsPerYear       float64    `json:"periodsPerYear"`
+	AnnualizedReturn     float64    `json:"annualizedReturn"`
+	AnnualizedVolatility float64    `json:"annualizedVolatility"`
+	MaxDrawdown          float64    `json:"maxDrawdown"` // Caída máxima desde un pico, como valor negativo
+	PeakDate             *time.Time `json:"peakDate"`
+	TroughDate           *time.Time `json:"troughDate"`
+	RecoveryDate         *time.Time `json:"recoveryDate"` // nil si todavía no se recuperó el pico
+	RiskFreeRate         float64    `json:"riskFreeRate"`
+	Sharpe               *float64   `json:"sharpe"`
+	Sortino              *float64   `json:"sortino"`
+}
+
+// Drawdown es la mayor caída de un índice de riqueza desde un pico previo
+type Drawdown struct {
+	Depth    float64
+	Peak     time.Time
+	Trough   time.Time
+	Recovery *time.Time
+}
+
+// ComputeRisk calcula volatilidad, drawdown máximo y Sharpe/Sortino a partir de los rendimientos
+// de cada sub-período. riskFreeRate es la tasa libre de riesgo anual en porcentaje.
+func ComputeRisk(series Series, riskFreeRate float64) (*RiskMetrics, error) {
+	returns := PeriodReturns(series.Valuations, series.Flows)
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("se necesitan al menos tres valuaciones para calcular métricas de riesgo")
+	}
+
+	from := returns[0].Start
+	to := returns[len(returns)-1].End
+	periodsPerYear := PeriodsPerYear(from, to, len(returns))
+
+	metrics := &RiskMetrics{
+		From:           from,
+		To:             to,
+		Periods:        len(returns),
+		PeriodsPerYear: periodsPerYear,
+		RiskFreeRate:   riskFreeRate,
+	}
+
+	values := make([]float64, len(returns))
+	growth := 1.0
+	for i, period := range returns {
+		values[i] = period.Return
+		growth *= 1 + period.Return
+	}
+
+	if growth > 0 {
+		metrics.AnnualizedReturn = (math.Pow(growth, periodsPerYear/float64(len(returns))) - 1) * 100
+	} else {
+		metrics.AnnualizedReturn = -100
+	}
+
+	std := standardDeviation(values)
+	metrics.AnnualizedVolatility = std * math.Sqrt(periodsPerYear) * 100
+
+	drawdown := MaxDrawdown(returns)
+	metrics.MaxDrawdown = drawdown.Depth * 100
+	if drawdown.Depth < 0 {
+		metrics.PeakDate = &drawdown.Peak
+		metrics.TroughDate = &drawdown.Trough
+		metrics.RecoveryDate = drawdown.Recovery
+	}
+
+	// Excesos de rendimiento sobre la tasa libre de riesgo del sub-período
+	periodRiskFree := math.Pow(1+riskFreeRate/100, 1/periodsPerYear) - 1
+	excess := make([]float64, len(values))
+	downside := 0.0
+	for i, value := range values {
+		excess[i] = value - periodRiskFree
+		if excess[i] < 0 {
+			downside += excess[i] * excess[i]
+		}
+	}
+
+	meanExcess := mean(excess)
+	if excessStd := standardDeviation(excess); excessStd > 0 {
+		sharpe := meanExcess / excessStd * math.Sqrt(periodsPerYear)
+		metrics.Sharpe = &sharpe
+	}
+
+	if downsideDeviation := math.Sqrt(downside / float64(len(excess))); downsideDeviation > 0 {
+		sortino := meanExcess / downsideDeviation * math.Sqrt(periodsPerYear)
+		metrics.Sortino = &sortino
+	}
+
+	return metrics, nil
+}
+
+// PeriodsPerYear estima la frecuencia de la serie a partir del espaciado promedio entre valuaciones
+// (aproximadamente 52 para snapshots semanales)
+func PeriodsPerYear(from, to time.Time, periods int) float64 {
+	days := to.Sub(from).Hours() / 24
+	if days <= 0 || periods == 0 {
+		return 1
+	}
+	return daysPerYear / (days / float64(periods))
+}
+
+// MaxDrawdown busca la mayor caída del índice de riqueza construido con los rendimientos encadenados
+func MaxDrawdown(returns []PeriodReturn) Drawdown {
+	var result Drawdown
+	if len(returns) == 0 {
+		return result
+	}
+
+	wealth := 1.0
+	peak := 1.0
+	peakDate := returns[0].Start
+	var candidatePeak float64
+
+	for _, period := range returns {
+		wealth *= 1 + period.Return
+
+		if wealth >= peak {
+			// Primera recuperación del pico del drawdown máximo
+			if result.Depth < 0 && result.Recovery == nil && wealth >= candidatePeak {
+				recovery := period.End
+				result.Recovery = &recovery
+			}
+			peak = wealth
+			peakDate = period.End
+			continue
+		}
+
+		depth := wealth/peak - 1
+		if depth < result.Depth {
+			result.Depth = depth
+			result.Peak = peakDate
+			result.Trough = period.End
+			result.Recovery = nil
+			candidatePeak = peak
+		}
+	}
+
+	return result
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+// standardDeviation calcula el desvío estándar muestral
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	avg := mean(values)
+	total := 0.0
+	for _, value := range values {
+		total += (value - avg) * (value - avg)
+	}
+	return math.Sqrt(total / float64(len(values)-1))
+}
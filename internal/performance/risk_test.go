@@ -0,0 +1,174 @@
+package performance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMaxDrawdown(t *testing.T) {
+	start := date(2024, 1, 1)
+	week := func(n int) time.Time { return start.AddDate(0, 0, 7*n) }
+
+	tests := []struct {
+		name         string
+		values       []float64
+		wantDepth    float64
+		wantPeak     time.Time
+		wantTrough   time.Time
+		wantRecovery *time.Time
+	}{
+		{
+			name:         "con recuperación",
+			values:       []float64{100, 110, 88, 99, 121},
+			wantDepth:    -0.20,
+			wantPeak:     week(1),
+			wantTrough:   week(2),
+			wantRecovery: timePtr(week(4)),
+		},
+		{
+			name:       "sin recuperación",
+			values:     []float64{100, 120, 90, 100},
+			wantDepth:  -0.25,
+			wantPeak:   week(1),
+			wantTrough: week(2),
+		},
+		{
+			name:       "caída mayor después de recuperarse",
+			values:     []float64{100, 90, 100, 70, 80},
+			wantDepth:  -0.30,
+			wantPeak:   week(2),
+			wantTrough: week(3),
+		},
+		{
+			name:      "siempre en alza",
+			values:    []float64{100, 101, 105, 110},
+			wantDepth: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valuations := make([]Valuation, len(tt.values))
+			for i, value := range tt.values {
+				valuations[i] = Valuation{Date: week(i), Value: value}
+			}
+
+			drawdown := MaxDrawdown(PeriodReturns(valuations, nil))
+
+			assertClose(t, "Depth", drawdown.Depth, tt.wantDepth)
+			if tt.wantDepth == 0 {
+				return
+			}
+			if !drawdown.Peak.Equal(tt.wantPeak) {
+				t.Errorf("Peak = %s, se esperaba %s", drawdown.Peak, tt.wantPeak)
+			}
+			if !drawdown.Trough.Equal(tt.wantTrough) {
+				t.Errorf("Trough = %s, se esperaba %s", drawdown.Trough, tt.wantTrough)
+			}
+			switch {
+			case tt.wantRecovery == nil && drawdown.Recovery != nil:
+				t.Errorf("Recovery = %s, se esperaba nil", drawdown.Recovery)
+			case tt.wantRecovery != nil && (drawdown.Recovery == nil || !drawdown.Recovery.Equal(*tt.wantRecovery)):
+				t.Errorf("Recovery = %v, se esperaba %s", drawdown.Recovery, tt.wantRecovery)
+			}
+		})
+	}
+}
+
+func TestComputeRisk(t *testing.T) {
+	periodsPerYear := 365.0 / 7
+
+	tests := []struct {
+		name         string
+		returns      []float64
+		riskFreeRate float64
+		wantSharpe   *float64
+		wantSortino  *float64
+	}{
+		{
+			// Media 0.005, desvío muestral sqrt(0.0003), desvío a la baja sqrt(0.0002/4)
+			name:         "alternando subas y bajas",
+			returns:      []float64{0.02, -0.01, 0.02, -0.01},
+			riskFreeRate: 0,
+			wantSharpe:   floatPtr(0.005 / math.Sqrt(0.0003) * math.Sqrt(periodsPerYear)),
+			wantSortino:  floatPtr(0.005 / math.Sqrt(0.00005) * math.Sqrt(periodsPerYear)),
+		},
+		{
+			name:         "sin rendimientos bajo la tasa libre de riesgo",
+			returns:      []float64{0.01, 0.03, 0.02},
+			riskFreeRate: 0,
+			wantSharpe:   floatPtr(0.02 / 0.01 * math.Sqrt(periodsPerYear)),
+			wantSortino:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := ComputeRisk(Series{Valuations: weekly(date(2024, 1, 1), 100, tt.returns)}, tt.riskFreeRate)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			assertClose(t, "PeriodsPerYear", metrics.PeriodsPerYear, periodsPerYear)
+			if metrics.Periods != len(tt.returns) {
+				t.Errorf("Periods = %d, se esperaban %d", metrics.Periods, len(tt.returns))
+			}
+			assertOptional(t, "Sharpe", metrics.Sharpe, tt.wantSharpe)
+			assertOptional(t, "Sortino", metrics.Sortino, tt.wantSortino)
+		})
+	}
+}
+
+func TestComputeRiskDrawdownDates(t *testing.T) {
+	start := date(2024, 1, 1)
+	valuations := []Valuation{
+		{Date: start, Value: 100},
+		{Date: start.AddDate(0, 0, 7), Value: 120},
+		{Date: start.AddDate(0, 0, 14), Value: 90},
+		{Date: start.AddDate(0, 0, 21), Value: 100},
+	}
+
+	metrics, err := ComputeRisk(Series{Valuations: valuations}, 0)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	assertClose(t, "MaxDrawdown", metrics.MaxDrawdown, -25)
+	if metrics.PeakDate == nil || !metrics.PeakDate.Equal(valuations[1].Date) {
+		t.Errorf("PeakDate = %v, se esperaba %s", metrics.PeakDate, valuations[1].Date)
+	}
+	if metrics.TroughDate == nil || !metrics.TroughDate.Equal(valuations[2].Date) {
+		t.Errorf("TroughDate = %v, se esperaba %s", metrics.TroughDate, valuations[2].Date)
+	}
+	if metrics.RecoveryDate != nil {
+		t.Errorf("RecoveryDate = %s, se esperaba nil", metrics.RecoveryDate)
+	}
+}
+
+func TestComputeRiskErrors(t *testing.T) {
+	valuations := weekly(date(2024, 1, 1), 100, []float64{0.01})
+	if _, err := ComputeRisk(Series{Valuations: valuations}, 0); err == nil {
+		t.Fatal("se esperaba un error con un solo sub-período")
+	}
+}
+
+func assertOptional(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("%s = %.10f, se esperaba nil", name, *got)
+	case want != nil && got == nil:
+		t.Errorf("%s = nil, se esperaba %.10f", name, *want)
+	case want != nil:
+		assertClose(t, name, *got, *want)
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func timePtr(value time.Time) *time.Time {
+	return &value
+}
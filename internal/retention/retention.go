@@ -0,0 +1,223 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+// Policy define hasta cuándo se conservan todos los snapshots y a partir de cuándo
+// se resumen por mes o por trimestre
+type Policy struct {
+	FullResolutionDays int `json:"fullResolutionDays"` // Snapshots más recientes que esto no se tocan
+	MonthlyDays        int `json:"monthlyDays"`        // Entre FullResolutionDays y esto se resumen por mes; más antiguos, por trimestre
+}
+
+// Validate verifica que los plazos de la política sean coherentes
+func (p Policy) Validate() error {
+	if p.FullResolutionDays <= 0 {
+		return fmt.Errorf("los días de resolución completa deben ser mayores a 0")
+	}
+	if p.MonthlyDays < p.FullResolutionDays {
+		return fmt.Errorf("los días de resolución mensual (%d) no pueden ser menores a los de resolución completa (%d)",
+			p.MonthlyDays, p.FullResolutionDays)
+	}
+	return nil
+}
+
+// Cutoffs retorna desde qué fecha se resume por mes y desde cuál por trimestre. Se alinean al
+// inicio del mes y del trimestre para resumir solo períodos completos.
+func (p Policy) Cutoffs(now time.Time) (monthly, quarterly time.Time) {
+	monthly = StartOfMonth(now.AddDate(0, 0, -p.FullResolutionDays))
+	quarterly = StartOfQuarter(now.AddDate(0, 0, -p.MonthlyDays))
+	if quarterly.After(monthly) {
+		quarterly = monthly
+	}
+	return monthly, quarterly
+}
+
+// Bucket es un período a resumir de un holding: el agregado resultante, el snapshot que se
+// conserva y lo que se reemplaza
+type Bucket struct {
+	Rollup            models.SnapshotRollup
+	KeepSnapshotID    string
+	DeleteSnapshotIDs []string
+	ReplaceRollupIDs  []string // Agregados previos incluidos en el nuevo (por ejemplo meses de un trimestre)
+}
+
+// bucketKey identifica un período de resumen
+type bucketKey struct {
+	Granularity string
+	Start       time.Time
+}
+
+// bucketContent son los snapshots y agregados previos que caen en un período
+type bucketContent struct {
+	snapshots []models.Snapshot
+	rollups   []models.SnapshotRollup
+}
+
+// Plan calcula los períodos a resumir de un holding. Recibe los snapshots anteriores al corte
+// mensual y los agregados existentes del holding. Es idempotente: un período ya resumido sin
+// snapshots nuevos no genera cambios.
+func Plan(holdingID string, snapshots []models.Snapshot, rollups []models.SnapshotRollup, monthlyCutoff, quarterlyCutoff time.Time) []Bucket {
+	contents := make(map[bucketKey]*bucketContent)
+	contentFor := func(t time.Time) *bucketContent {
+		key, ok := keyFor(t, monthlyCutoff, quarterlyCutoff)
+		if !ok {
+			return nil
+		}
+		content, exists := contents[key]
+		if !exists {
+			content = &bucketContent{}
+			contents[key] = content
+		}
+		return content
+	}
+
+	for _, snapshot := range snapshots {
+		if content := contentFor(snapshot.CreatedAt); content != nil {
+			content.snapshots = append(content.snapshots, snapshot)
+		}
+	}
+	for _, rollup := range rollups {
+		if content := contentFor(rollup.PeriodStart); content != nil {
+			content.rollups = append(content.rollups, rollup)
+		}
+	}
+
+	keys := make([]bucketKey, 0, len(contents))
+	for key := range contents {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Start.Before(keys[j].Start) })
+
+	var buckets []Bucket
+	for _, key := range keys {
+		if bucket, ok := planBucket(holdingID, key, contents[key]); ok {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+// planBucket combina los snapshots no resumidos y los agregados previos de un período
+func planBucket(holdingID string, key bucketKey, content *bucketContent) (Bucket, bool) {
+	counted := make(map[string]bool, len(content.rollups))
+	for _, rollup := range content.rollups {
+		if rollup.CloseSnapshotID != nil {
+			counted[*rollup.CloseSnapshotID] = true
+		}
+	}
+
+	var pending []models.Snapshot
+	for _, snapshot := range content.snapshots {
+		if !counted[snapshot.ID] {
+			pending = append(pending, snapshot)
+		}
+	}
+
+	// Período ya resumido con la granularidad que le corresponde
+	if len(pending) == 0 && (len(content.rollups) == 0 ||
+		(len(content.rollups) == 1 && content.rollups[0].Granularity == key.Granularity)) {
+		return Bucket{}, false
+	}
+
+	rollup := models.SnapshotRollup{
+		HoldingID:   holdingID,
+		Granularity: key.Granularity,
+		PeriodStart: key.Start,
+		PeriodEnd:   periodEnd(key),
+	}
+	first := true
+	merge := func(openAt, closeAt time.Time, open, close, min, max, closePrice, closeQuantity float64, count int) {
+		if first || openAt.Before(rollup.FirstSnapshotAt) {
+			rollup.FirstSnapshotAt = openAt
+			rollup.OpenValue = open
+		}
+		if first || !closeAt.Before(rollup.LastSnapshotAt) {
+			rollup.LastSnapshotAt = closeAt
+			rollup.CloseValue = close
+			rollup.ClosePrice = closePrice
+			rollup.CloseQuantity = closeQuantity
+		}
+		if first || min < rollup.MinValue {
+			rollup.MinValue = min
+		}
+		if first || max > rollup.MaxValue {
+			rollup.MaxValue = max
+		}
+		rollup.Snapshots += count
+		first = false
+	}
+
+	var bucket Bucket
+	for _, previous := range content.rollups {
+		merge(previous.FirstSnapshotAt, previous.LastSnapshotAt, previous.OpenValue, previous.CloseValue,
+			previous.MinValue, previous.MaxValue, previous.ClosePrice, previous.CloseQuantity, previous.Snapshots)
+		bucket.ReplaceRollupIDs = append(bucket.ReplaceRollupIDs, previous.ID)
+	}
+	for _, snapshot := range pending {
+		value := snapshot.Price * snapshot.Quantity
+		merge(snapshot.CreatedAt, snapshot.CreatedAt, value, value, value, value, snapshot.Price, snapshot.Quantity, 1)
+	}
+
+	// Se conserva el último snapshot del período para que las series sigan teniendo un punto
+	var keep *models.Snapshot
+	for i := range content.snapshots {
+		snapshot := &content.snapshots[i]
+		if keep == nil || snapshot.CreatedAt.After(keep.CreatedAt) ||
+			(snapshot.CreatedAt.Equal(keep.CreatedAt) && snapshot.ID > keep.ID) {
+			keep = snapshot
+		}
+	}
+	for _, snapshot := range content.snapshots {
+		if keep != nil && snapshot.ID == keep.ID {
+			continue
+		}
+		bucket.DeleteSnapshotIDs = append(bucket.DeleteSnapshotIDs, snapshot.ID)
+	}
+	if keep != nil {
+		keepID := keep.ID
+		bucket.KeepSnapshotID = keepID
+		rollup.CloseSnapshotID = &keepID
+	}
+
+	bucket.Rollup = rollup
+	return bucket, true
+}
+
+// keyFor retorna el período de resumen de una fecha; false si está dentro de la resolución completa
+func keyFor(t, monthlyCutoff, quarterlyCutoff time.Time) (bucketKey, bool) {
+	switch {
+	case t.Before(quarterlyCutoff):
+		return bucketKey{Granularity: models.RollupGranularityQuarter, Start: StartOfQuarter(t)}, true
+	case t.Before(monthlyCutoff):
+		return bucketKey{Granularity: models.RollupGranularityMonth, Start: StartOfMonth(t)}, true
+	default:
+		return bucketKey{}, false
+	}
+}
+
+// periodEnd retorna el inicio del período siguiente
+func periodEnd(key bucketKey) time.Time {
+	if key.Granularity == models.RollupGranularityQuarter {
+		return key.Start.AddDate(0, 3, 0)
+	}
+	return key.Start.AddDate(0, 1, 0)
+}
+
+// StartOfMonth retorna el primer instante del mes en UTC
+func StartOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfQuarter retorna el primer instante del trimestre en UTC
+func StartOfQuarter(t time.Time) time.Time {
+	t = t.UTC()
+	month := time.Month((int(t.Month())-1)/3*3 + 1)
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
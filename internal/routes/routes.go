@@ -19,6 +19,26 @@ func SetupRoutes(app *fiber.App, cronService *services.CronService) {
 	// Controladores
 	validationController := controllers.NewValidationController()
 	cronController := controllers.NewCronController(cronService)
+	webhookController := controllers.NewWebhookController()
+	summaryController := controllers.NewSummaryController()
+	alertController := controllers.NewAlertController()
+	snapshotController := controllers.NewSnapshotController()
+	portfolioController := controllers.NewPortfolioController()
+	transactionController := controllers.NewTransactionController()
+	pnlController := controllers.NewPnLController()
+	fxController := controllers.NewFXController()
+	performanceController := controllers.NewPerformanceController()
+	analyticsController := controllers.NewAnalyticsController()
+	benchmarkController := controllers.NewBenchmarkController()
+	assetController := controllers.NewAssetController()
+	backfillController := controllers.NewBackfillController()
+	earningsController := controllers.NewEarningsController()
+	retentionController := controllers.NewRetentionController()
+	exportController := controllers.NewExportController()
+	importController := controllers.NewImportController()
+	quoteController := controllers.NewQuoteController()
+	symbolController := controllers.NewSymbolController()
+	corporateActionController := controllers.NewCorporateActionController()
 
 	// Rutas públicas (sin autenticación)
 	api.Get("/health", validationController.HealthCheck)
@@ -26,10 +46,63 @@ func SetupRoutes(app *fiber.App, cronService *services.CronService) {
 	// Rutas protegidas (con autenticación API Key)
 	protected := api.Group("", middleware.APIKeyAuth())
 	protected.Post("/validate", validationController.ValidateHolding)
+	protected.Post("/validate/batch", validationController.ValidateBatch)
+
+	// Cotizaciones actuales
+	setupQuoteRoutes(protected, quoteController)
+
+	// Búsqueda y normalización de símbolos
+	protected.Get("/symbols/search", symbolController.SearchSymbols)
+	protected.Get("/symbols/normalize", symbolController.NormalizeSymbol)
+
+	// Preferencias de notificación por usuario
+	protected.Get("/users/:id/preferences", summaryController.GetPreferences)
+	protected.Put("/users/:id/preferences", summaryController.UpdatePreferences)
+
+	// Reglas de alerta de precio
+	setupAlertRoutes(protected, alertController)
+
+	// Lectura de snapshots
+	setupSnapshotRoutes(protected, snapshotController)
+	protected.Get("/holdings/:id/rollups", retentionController.GetHoldingRollups)
+	protected.Get("/holdings/:id/dividends", corporateActionController.GetHoldingDividends)
+
+	// Historial de precios de assets
+	protected.Get("/assets/:id/prices", assetController.GetAssetPrices)
+
+	// Series de valor de portafolios
+	setupPortfolioRoutes(protected, portfolioController, pnlController)
+
+	// Transacciones y costo de holdings
+	setupTransactionRoutes(protected, transactionController)
+
+	// Exportación de snapshots y transacciones
+	setupExportRoutes(protected, exportController)
+
+	// Importación de CSV de brokers
+	setupImportRoutes(protected, importController)
+
+	// Rendimientos TWR y MWR
+	setupPerformanceRoutes(protected, performanceController)
+
+	// Benchmarks de grupos y usuarios
+	setupBenchmarkRoutes(protected, benchmarkController)
+
+	// Métricas de riesgo y comparación contra benchmark
+	setupAnalyticsRoutes(protected.Group("/analytics"), analyticsController)
 
 	// Rutas de administración del cron
 	admin := protected.Group("/admin")
 	setupCronRoutes(admin, cronController)
+	setupWebhookRoutes(admin, webhookController)
+	setupSummaryRoutes(admin, summaryController)
+	setupFXRoutes(admin, fxController)
+	setupBackfillRoutes(admin, backfillController)
+	setupEarningsRoutes(admin, earningsController)
+	setupRetentionRoutes(admin, retentionController)
+	setupValidationCacheRoutes(admin, validationController)
+	setupSymbolMappingRoutes(admin, symbolController)
+	setupCorporateActionRoutes(admin, corporateActionController)
 }
 
 // setupCronRoutes configura las rutas relacionadas con el servicio de cron
@@ -46,3 +119,194 @@ func setupCronRoutes(router fiber.Router, cronController *controllers.CronContro
 	// Obtener información general del servicio de cron
 	router.Get("/cron/info", cronController.GetCronInfo)
 }
+
+// setupWebhookRoutes configura las rutas de administración de webhooks
+func setupWebhookRoutes(router fiber.Router, webhookController *controllers.WebhookController) {
+	// Listar entregas de webhooks
+	router.Get("/webhooks/deliveries", webhookController.GetDeliveries)
+
+	// Reenviar una entrega
+	router.Post("/webhooks/deliveries/:id/replay", webhookController.ReplayDelivery)
+}
+
+// setupSummaryRoutes configura las rutas de administración de resúmenes semanales
+func setupSummaryRoutes(router fiber.Router, summaryController *controllers.SummaryController) {
+	// Previsualizar el resumen de un usuario
+	router.Get("/summaries/users/:id", summaryController.PreviewSummary)
+
+	// Enviar el resumen a un usuario
+	router.Post("/summaries/users/:id/send", summaryController.SendUserSummary)
+
+	// Enviar el resumen a todos los usuarios suscriptos
+	router.Post("/summaries/send", summaryController.SendAllSummaries)
+}
+
+// setupFXRoutes configura las rutas de administración de tipos de cambio
+func setupFXRoutes(router fiber.Router, fxController *controllers.FXController) {
+	// Listar y registrar cotizaciones
+	router.Get("/fx/rates", fxController.GetRates)
+	router.Post("/fx/rates", fxController.CreateRate)
+
+	// Obtener cotizaciones del proveedor en el momento
+	router.Post("/fx/ingest", fxController.IngestRates)
+}
+
+// setupBackfillRoutes configura las rutas de carga de precios históricos
+func setupBackfillRoutes(router fiber.Router, backfillController *controllers.BackfillController) {
+	// Iniciar un backfill
+	router.Post("/backfill", backfillController.CreateBackfill)
+
+	// Consultar progreso
+	router.Get("/backfill", backfillController.GetBackfills)
+	router.Get("/backfill/:id", backfillController.GetBackfill)
+}
+
+// setupEarningsRoutes configura las rutas de recálculo de earnings
+func setupEarningsRoutes(router fiber.Router, earningsController *controllers.EarningsController) {
+	// Recalcular earnings desde el historial de snapshots (dry-run por defecto)
+	router.Post("/earnings/recompute", earningsController.RecomputeEarnings)
+}
+
+// setupRetentionRoutes configura las rutas de retención de snapshots
+func setupRetentionRoutes(router fiber.Router, retentionController *controllers.RetentionController) {
+	// Resumir snapshots antiguos (dry-run por defecto)
+	router.Post("/retention/run", retentionController.RunRetention)
+}
+
+// setupValidationCacheRoutes configura las rutas de administración del cache de validaciones
+func setupValidationCacheRoutes(router fiber.Router, validationController *controllers.ValidationController) {
+	// Inspeccionar y purgar entradas
+	router.Get("/validation-cache", validationController.GetValidationCache)
+	router.Delete("/validation-cache", validationController.PurgeValidationCache)
+
+	// Hits y misses
+	router.Get("/validation-cache/stats", validationController.GetValidationCacheStats)
+}
+
+// setupQuoteRoutes configura las rutas de cotizaciones
+func setupQuoteRoutes(router fiber.Router, quoteController *controllers.QuoteController) {
+	// Varios códigos de un tipo de inversión
+	router.Get("/quotes/:typeId", quoteController.GetQuotes)
+
+	// Un código
+	router.Get("/quotes/:typeId/:code", quoteController.GetQuote)
+}
+
+// setupSymbolMappingRoutes configura las rutas de administración de mapeos de símbolos
+func setupSymbolMappingRoutes(router fiber.Router, symbolController *controllers.SymbolController) {
+	router.Get("/symbol-mappings", symbolController.GetSymbolMappings)
+	router.Post("/symbol-mappings", symbolController.CreateSymbolMapping)
+	router.Delete("/symbol-mappings/:id", symbolController.DeleteSymbolMapping)
+}
+
+// setupCorporateActionRoutes configura las rutas de administración de splits y dividendos
+func setupCorporateActionRoutes(router fiber.Router, corporateActionController *controllers.CorporateActionController) {
+	router.Get("/corporate-actions", corporateActionController.GetCorporateActions)
+	router.Post("/corporate-actions", corporateActionController.CreateCorporateAction)
+
+	// Importar eventos del proveedor
+	router.Post("/corporate-actions/sync", corporateActionController.SyncCorporateActions)
+
+	// Aplicar o descartar un evento pendiente
+	router.Post("/corporate-actions/:id/apply", corporateActionController.ApplyCorporateAction)
+	router.Delete("/corporate-actions/:id", corporateActionController.DeleteCorporateAction)
+}
+
+// setupAlertRoutes configura las rutas de reglas de alerta
+func setupAlertRoutes(router fiber.Router, alertController *controllers.AlertController) {
+	// Reglas de un usuario
+	router.Get("/users/:id/alerts", alertController.GetUserAlerts)
+	router.Post("/users/:id/alerts", alertController.CreateUserAlert)
+
+	// Alertas disparadas de un usuario
+	router.Get("/users/:id/alerts/triggered", alertController.GetUserTriggeredAlerts)
+
+	// Activar/desactivar y eliminar reglas
+	router.Patch("/alerts/:id", alertController.UpdateAlert)
+	router.Delete("/alerts/:id", alertController.DeleteAlert)
+}
+
+// setupSnapshotRoutes configura las rutas de lectura de snapshots
+func setupSnapshotRoutes(router fiber.Router, snapshotController *controllers.SnapshotController) {
+	// Snapshots de un holding
+	router.Get("/holdings/:id/snapshots", snapshotController.GetHoldingSnapshots)
+
+	// Snapshots filtrados por asset, grupo, usuario y fechas
+	router.Get("/snapshots", snapshotController.GetSnapshots)
+
+	// Totales de grupos y usuarios por ejecución del cron
+	router.Get("/groups/:id/snapshots", snapshotController.GetGroupSnapshots)
+	router.Get("/users/:id/snapshots", snapshotController.GetUserSnapshots)
+}
+
+// setupPortfolioRoutes configura las rutas de agregación de portafolios
+func setupPortfolioRoutes(router fiber.Router, portfolioController *controllers.PortfolioController, pnlController *controllers.PnLController) {
+	// Serie de valor de un grupo
+	router.Get("/groups/:id/value-series", portfolioController.GetGroupValueSeries)
+
+	// Serie de valor de todos los grupos de un usuario
+	router.Get("/users/:id/value-series", portfolioController.GetUserValueSeries)
+
+	// Resultados realizados y no realizados por holding, grupo y usuario
+	router.Get("/holdings/:id/pnl", pnlController.GetHoldingPnL)
+	router.Get("/groups/:id/pnl", pnlController.GetGroupPnL)
+	router.Get("/users/:id/pnl", pnlController.GetUserPnL)
+}
+
+// setupTransactionRoutes configura las rutas del registro de transacciones
+func setupTransactionRoutes(router fiber.Router, transactionController *controllers.TransactionController) {
+	// Transacciones de un holding
+	router.Get("/holdings/:id/transactions", transactionController.GetHoldingTransactions)
+	router.Post("/holdings/:id/transactions", transactionController.CreateHoldingTransaction)
+
+	// Eliminar una transacción
+	router.Delete("/transactions/:id", transactionController.DeleteTransaction)
+
+	// Costo y ganancias desde el inicio
+	router.Get("/holdings/:id/cost-basis", transactionController.GetHoldingCostBasis)
+}
+
+// setupExportRoutes configura las rutas de exportación en CSV, XLSX y JSON Lines
+func setupExportRoutes(router fiber.Router, exportController *controllers.ExportController) {
+	router.Get("/holdings/:id/export", exportController.ExportHolding)
+	router.Get("/groups/:id/export", exportController.ExportGroup)
+	router.Get("/users/:id/export", exportController.ExportUser)
+}
+
+// setupImportRoutes configura las rutas de importación de CSV de brokers
+func setupImportRoutes(router fiber.Router, importController *controllers.ImportController) {
+	// Formatos predefinidos
+	router.Get("/imports/formats", importController.GetImportFormats)
+
+	// Vista previa y confirmación de la importación en un grupo
+	router.Post("/groups/:id/imports/preview", importController.PreviewImport)
+	router.Post("/groups/:id/imports", importController.CommitImport)
+}
+
+// setupPerformanceRoutes configura las rutas de rendimientos por holding, grupo y usuario
+func setupPerformanceRoutes(router fiber.Router, performanceController *controllers.PerformanceController) {
+	router.Get("/holdings/:id/performance", performanceController.GetHoldingPerformance)
+	router.Get("/groups/:id/performance", performanceController.GetGroupPerformance)
+	router.Get("/users/:id/performance", performanceController.GetUserPerformance)
+}
+
+// setupAnalyticsRoutes configura las rutas de métricas de riesgo y comparación contra benchmark
+func setupAnalyticsRoutes(router fiber.Router, analyticsController *controllers.AnalyticsController) {
+	router.Get("/holdings/:id/risk", analyticsController.GetHoldingRisk)
+	router.Get("/groups/:id/risk", analyticsController.GetGroupRisk)
+	router.Get("/users/:id/risk", analyticsController.GetUserRisk)
+
+	router.Get("/groups/:id/benchmark", analyticsController.GetGroupBenchmark)
+	router.Get("/users/:id/benchmark", analyticsController.GetUserBenchmark)
+}
+
+// setupBenchmarkRoutes configura las rutas de asignación de benchmarks
+func setupBenchmarkRoutes(router fiber.Router, benchmarkController *controllers.BenchmarkController) {
+	router.Get("/groups/:id/benchmark", benchmarkController.GetGroupBenchmark)
+	router.Put("/groups/:id/benchmark", benchmarkController.SetGroupBenchmark)
+	router.Delete("/groups/:id/benchmark", benchmarkController.DeleteGroupBenchmark)
+
+	router.Get("/users/:id/benchmark", benchmarkController.GetUserBenchmark)
+	router.Put("/users/:id/benchmark", benchmarkController.SetUserBenchmark)
+	router.Delete("/users/:id/benchmark", benchmarkController.DeleteUserBenchmark)
+}
@@ -68,7 +68,7 @@ func (s *StockStrategy) FetchPrice(typeInvestment *models.TypeInvestment, code s
 	// Verificar si se encontró el precio
 	if !found || price == "" {
 		log.Printf("⚠️ [StockStrategy] No se encontró precio válido")
-		return 0, fmt.Errorf("no se pudo encontrar el precio para el código %s en la URL %s", code, url)
+		return 0, fmt.Errorf("%w para el código %s en la URL %s", ErrPriceNotFound, code, url)
 	}
 
 	// Limpiar y convertir el precio a float64
@@ -0,0 +1,123 @@
+package scraping
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Tipos de evento corporativo informados por el proveedor
+const (
+	EventSplit    = "split"
+	EventDividend = "dividend"
+)
+
+// CorporateEvent es un split o dividendo informado por el proveedor
+type CorporateEvent struct {
+	Type   string
+	Date   time.Time
+	Ratio  float64 // Split: numerador / denominador
+	Amount float64 // Dividendo: importe por acción
+}
+
+// CorporateActionProvider define la interfaz para las fuentes de splits y dividendos
+type CorporateActionProvider interface {
+	// FetchCorporateActions obtiene los splits y dividendos de un símbolo entre dos fechas
+	FetchCorporateActions(symbol string, from, to time.Time) ([]CorporateEvent, error)
+}
+
+// yahooEventsResponse es la parte de /v8/finance/chart?events=div|split que usamos
+type yahooEventsResponse struct {
+	Chart struct {
+		Result []struct {
+			Events struct {
+				Dividends map[string]struct {
+					Amount float64 `json:"amount"`
+					Date   int64   `json:"date"`
+				} `json:"dividends"`
+				Splits map[string]struct {
+					Date        int64   `json:"date"`
+					Numerator   float64 `json:"numerator"`
+					Denominator float64 `json:"denominator"`
+				} `json:"splits"`
+			} `json:"events"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// FetchCorporateActions obtiene los splits y dividendos del símbolo, ordenados por fecha
+func (p *YahooChartProvider) FetchCorporateActions(symbol string, from, to time.Time) ([]CorporateEvent, error) {
+	params := url.Values{}
+	params.Set("period1", fmt.Sprintf("%d", from.Unix()))
+	params.Set("period2", fmt.Sprintf("%d", to.Unix()))
+	params.Set("interval", "1d")
+	params.Set("events", "div|split")
+	requestURL := fmt.Sprintf("%s/%s?%s", p.BaseURL, url.PathEscape(symbol), params.Encode())
+
+	log.Printf("🌐 [YahooChartProvider] Consultando eventos corporativos: %s", requestURL)
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; holding-snapshots)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando %s: %w", requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	var body yahooEventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decodificando eventos (status %d): %w", resp.StatusCode, err)
+	}
+
+	if body.Chart.Error != nil {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrPriceNotFound, body.Chart.Error.Description, body.Chart.Error.Code)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta no exitosa del proveedor de eventos: %d", resp.StatusCode)
+	}
+
+	events := []CorporateEvent{}
+	if len(body.Chart.Result) == 0 {
+		return events, nil
+	}
+
+	result := body.Chart.Result[0]
+	for _, split := range result.Events.Splits {
+		if split.Numerator <= 0 || split.Denominator <= 0 {
+			continue
+		}
+		events = append(events, CorporateEvent{
+			Type:  EventSplit,
+			Date:  time.Unix(split.Date, 0).UTC(),
+			Ratio: split.Numerator / split.Denominator,
+		})
+	}
+	for _, dividend := range result.Events.Dividends {
+		if dividend.Amount <= 0 {
+			continue
+		}
+		events = append(events, CorporateEvent{
+			Type:   EventDividend,
+			Date:   time.Unix(dividend.Date, 0).UTC(),
+			Amount: dividend.Amount,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+
+	log.Printf("✅ [YahooChartProvider] %d eventos corporativos obtenidos para %s", len(events), symbol)
+	return events, nil
+}
@@ -0,0 +1,130 @@
+package scraping
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Intervalos de historial soportados
+const (
+	HistoryIntervalDay  = "day"
+	HistoryIntervalWeek = "week"
+)
+
+// HistoricalPrice es el precio de cierre de un símbolo en una fecha
+type HistoricalPrice struct {
+	Date  time.Time
+	Close float64
+}
+
+// HistoryProvider define la interfaz para las fuentes de precios históricos
+type HistoryProvider interface {
+	// FetchHistory obtiene los cierres de un símbolo entre dos fechas con el intervalo indicado
+	FetchHistory(symbol string, from, to time.Time, interval string) ([]HistoricalPrice, error)
+}
+
+// YahooChartProvider obtiene precios históricos de un endpoint con el formato de /v8/finance/chart de Yahoo
+type YahooChartProvider struct {
+	BaseURL string
+	client  *http.Client
+}
+
+// yahooChartResponse es la parte de la respuesta de /v8/finance/chart que usamos
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Close []*float64 `json:"close"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// yahooIntervals traduce los intervalos del servicio a los de Yahoo
+var yahooIntervals = map[string]string{
+	HistoryIntervalDay:  "1d",
+	HistoryIntervalWeek: "1wk",
+}
+
+// NewYahooChartProvider crea un proveedor para la URL base indicada (sin el símbolo)
+func NewYahooChartProvider(baseURL string) *YahooChartProvider {
+	return &YahooChartProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// FetchHistory obtiene los cierres del símbolo. Los puntos sin cierre (feriados, datos faltantes) se omiten.
+func (p *YahooChartProvider) FetchHistory(symbol string, from, to time.Time, interval string) ([]HistoricalPrice, error) {
+	yahooInterval, ok := yahooIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("intervalo de historial inválido: %s (day o week)", interval)
+	}
+
+	params := url.Values{}
+	params.Set("period1", fmt.Sprintf("%d", from.Unix()))
+	params.Set("period2", fmt.Sprintf("%d", to.Unix()))
+	params.Set("interval", yahooInterval)
+	requestURL := fmt.Sprintf("%s/%s?%s", p.BaseURL, url.PathEscape(symbol), params.Encode())
+
+	log.Printf("🌐 [YahooChartProvider] Consultando historial: %s", requestURL)
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; holding-snapshots)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando %s: %w", requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	var body yahooChartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decodificando historial (status %d): %w", resp.StatusCode, err)
+	}
+
+	if body.Chart.Error != nil {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrPriceNotFound, body.Chart.Error.Description, body.Chart.Error.Code)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta no exitosa del proveedor de historial: %d", resp.StatusCode)
+	}
+
+	if len(body.Chart.Result) == 0 || len(body.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("%w: el historial de %s está vacío", ErrPriceNotFound, symbol)
+	}
+
+	result := body.Chart.Result[0]
+	closes := result.Indicators.Quote[0].Close
+
+	prices := make([]HistoricalPrice, 0, len(result.Timestamp))
+	for i, ts := range result.Timestamp {
+		if i >= len(closes) || closes[i] == nil || *closes[i] <= 0 {
+			continue
+		}
+
+		prices = append(prices, HistoricalPrice{
+			Date:  time.Unix(ts, 0).UTC(),
+			Close: *closes[i],
+		})
+	}
+
+	log.Printf("✅ [YahooChartProvider] %d precios históricos obtenidos para %s", len(prices), symbol)
+	return prices, nil
+}
@@ -0,0 +1,134 @@
+package scraping
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SymbolCandidate es un ticker encontrado por un proveedor de búsqueda
+type SymbolCandidate struct {
+	Symbol    string `json:"symbol"`
+	Name      string `json:"name"`
+	Exchange  string `json:"exchange,omitempty"`
+	QuoteType string `json:"quoteType,omitempty"` // EQUITY, ETF, CRYPTOCURRENCY, ...
+	Currency  string `json:"currency,omitempty"`
+}
+
+// SymbolSearchProvider define la interfaz para las fuentes de búsqueda de símbolos
+type SymbolSearchProvider interface {
+	// Search busca tickers por código o nombre
+	Search(query string, limit int) ([]SymbolCandidate, error)
+}
+
+// YahooSearchProvider busca símbolos en un endpoint con el formato de /v1/finance/search de Yahoo
+type YahooSearchProvider struct {
+	BaseURL string
+	client  *http.Client
+}
+
+// yahooSearchResponse es la parte de la respuesta de /v1/finance/search que usamos
+type yahooSearchResponse struct {
+	Quotes []struct {
+		Symbol    string `json:"symbol"`
+		ShortName string `json:"shortname"`
+		LongName  string `json:"longname"`
+		Exchange  string `json:"exchange"`
+		ExchDisp  string `json:"exchDisp"`
+		QuoteType string `json:"quoteType"`
+	} `json:"quotes"`
+}
+
+// exchangeCurrencies es la moneda de cotización de los mercados más comunes, ya que la búsqueda no la informa
+var exchangeCurrencies = map[string]string{
+	"BUE": "ARS",
+	"NYQ": "USD",
+	"NMS": "USD",
+	"NGM": "USD",
+	"NCM": "USD",
+	"ASE": "USD",
+	"PCX": "USD",
+	"BTS": "USD",
+	"PNK": "USD",
+}
+
+// NewYahooSearchProvider crea un proveedor para la URL base indicada
+func NewYahooSearchProvider(baseURL string) *YahooSearchProvider {
+	return &YahooSearchProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Search busca tickers que coincidan con el texto
+func (p *YahooSearchProvider) Search(query string, limit int) ([]SymbolCandidate, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("quotesCount", fmt.Sprintf("%d", limit))
+	params.Set("newsCount", "0")
+	requestURL := fmt.Sprintf("%s?%s", p.BaseURL, params.Encode())
+
+	log.Printf("🌐 [YahooSearchProvider] Buscando símbolos: %s", requestURL)
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; holding-snapshots)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando %s: %w", requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta no exitosa del proveedor de búsqueda: %d", resp.StatusCode)
+	}
+
+	var body yahooSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decodificando búsqueda: %w", err)
+	}
+
+	candidates := make([]SymbolCandidate, 0, len(body.Quotes))
+	for _, quote := range body.Quotes {
+		if quote.Symbol == "" {
+			continue
+		}
+
+		name := quote.LongName
+		if name == "" {
+			name = quote.ShortName
+		}
+
+		exchange := quote.ExchDisp
+		if exchange == "" {
+			exchange = quote.Exchange
+		}
+
+		candidates = append(candidates, SymbolCandidate{
+			Symbol:    quote.Symbol,
+			Name:      name,
+			Exchange:  exchange,
+			QuoteType: quote.QuoteType,
+			Currency:  candidateCurrency(quote.Symbol, quote.Exchange, quote.QuoteType),
+		})
+	}
+
+	return candidates, nil
+}
+
+// candidateCurrency deduce la moneda del mercado o, en criptomonedas, del par (BTC-USD)
+func candidateCurrency(symbol, exchange, quoteType string) string {
+	if quoteType == "CRYPTOCURRENCY" {
+		if i := strings.LastIndex(symbol, "-"); i >= 0 {
+			return symbol[i+1:]
+		}
+	}
+	return exchangeCurrencies[exchange]
+}
@@ -1,6 +1,10 @@
 package scraping
 
-import "holding-snapshots/internal/models"
+import (
+	"errors"
+
+	"holding-snapshots/internal/models"
+)
 
 // ScrapingStrategy define la interfaz para las estrategias de scraping
 type ScrapingStrategy interface {
@@ -16,3 +20,7 @@ const (
 	CryptoStrategyEnum  = "Criptomonedas"
 	StockStrategyEnum   = "Acciones"
 )
+
+// ErrPriceNotFound indica que la página del proveedor no contiene un precio para el código,
+// a diferencia de errores transitorios de red
+var ErrPriceNotFound = errors.New("no se pudo encontrar el precio")
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type AggregateSnapshotService struct{}
+
+// NewAggregateSnapshotService crea una nueva instancia del servicio de snapshots de grupos y usuarios
+func NewAggregateSnapshotService() *AggregateSnapshotService {
+	return &AggregateSnapshotService{}
+}
+
+// groupTotals son los totales de un grupo calculados a partir del último snapshot de cada holding
+type groupTotals struct {
+	GroupID              string
+	TotalValue           float64
+	CostBasis            float64
+	Earnings             float64
+	Holdings             int
+	HoldingsWithoutBasis int
+}
+
+// userKey identifica los totales de un usuario en una moneda
+type userKey struct {
+	UserID   string
+	Currency string
+}
+
+// RecordRun guarda un GroupSnapshot por grupo y un UserSnapshot por usuario y moneda con el estado
+// del portafolio al final de una ejecución. Los holdings que no se actualizaron en la ejecución
+// aportan su último valor conocido pero no su variación.
+func (ass *AggregateSnapshotService) RecordRun(runID string, runStartedAt time.Time) error {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (s."holdingId") s.id, s."holdingId", s.price, s.quantity, s."createdAt"
+			FROM "Snapshot" s
+			ORDER BY s."holdingId", s."createdAt" DESC
+		)
+		SELECT
+			h."groupId" AS group_id,
+			SUM(l.price * l.quantity) AS total_value,
+			SUM(CASE WHEN p."hasCostBasis" THEN p."costBasis" ELSE 0 END) AS cost_basis,
+			SUM(CASE WHEN l."createdAt" >= ? THEN COALESCE(p."periodEarnings", 0) ELSE 0 END) AS earnings,
+			COUNT(*) AS holdings,
+			SUM(CASE WHEN p."hasCostBasis" THEN 0 ELSE 1 END) AS holdings_without_basis
+		FROM latest l
+		JOIN "Holding" h ON h.id = l."holdingId"
+		LEFT JOIN "SnapshotPnL" p ON p."snapshotId" = l.id
+		GROUP BY h."groupId"`
+
+	var totals []groupTotals
+	if err := database.DB.Raw(query, runStartedAt).Scan(&totals).Error; err != nil {
+		return fmt.Errorf("error calculando totales de grupos: %w", err)
+	}
+
+	if len(totals) == 0 {
+		log.Println("ℹ️ No hay grupos con snapshots para agregar")
+		return nil
+	}
+
+	groupIDs := make([]string, 0, len(totals))
+	for _, total := range totals {
+		groupIDs = append(groupIDs, total.GroupID)
+	}
+
+	var groups []models.Group
+	if err := database.DB.Preload("Type").Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+		return fmt.Errorf("error obteniendo grupos: %w", err)
+	}
+
+	groupsByID := make(map[string]models.Group, len(groups))
+	for _, group := range groups {
+		groupsByID[group.ID] = group
+	}
+
+	createdAt := time.Now()
+	groupSnapshots := make([]models.GroupSnapshot, 0, len(totals))
+	userSnapshots := make(map[userKey]*models.UserSnapshot)
+	var userOrder []userKey
+
+	for _, total := range totals {
+		group, ok := groupsByID[total.GroupID]
+		if !ok {
+			continue
+		}
+
+		groupSnapshots = append(groupSnapshots, models.GroupSnapshot{
+			GroupID:              group.ID,
+			UserID:               group.UserID,
+			RunID:                runID,
+			Currency:             group.Type.Currency,
+			TotalValue:           total.TotalValue,
+			CostBasis:            total.CostBasis,
+			Earnings:             total.Earnings,
+			Holdings:             total.Holdings,
+			HoldingsWithoutBasis: total.HoldingsWithoutBasis,
+			CreatedAt:            createdAt,
+		})
+
+		key := userKey{UserID: group.UserID, Currency: group.Type.Currency}
+		userSnapshot, ok := userSnapshots[key]
+		if !ok {
+			userSnapshot = &models.UserSnapshot{
+				UserID:    group.UserID,
+				RunID:     runID,
+				Currency:  group.Type.Currency,
+				CreatedAt: createdAt,
+			}
+			userSnapshots[key] = userSnapshot
+			userOrder = append(userOrder, key)
+		}
+
+		userSnapshot.TotalValue += total.TotalValue
+		userSnapshot.CostBasis += total.CostBasis
+		userSnapshot.Earnings += total.Earnings
+		userSnapshot.Groups++
+		userSnapshot.Holdings += total.Holdings
+		userSnapshot.HoldingsWithoutBasis += total.HoldingsWithoutBasis
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(groupSnapshots) > 0 {
+			if err := tx.CreateInBatches(&groupSnapshots, 100).Error; err != nil {
+				return fmt.Errorf("error guardando snapshots de grupos: %w", err)
+			}
+		}
+
+		for _, key := range userOrder {
+			if err := tx.Create(userSnapshots[key]).Error; err != nil {
+				return fmt.Errorf("error guardando snapshot del usuario %s: %w", key.UserID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📸 Snapshots agregados guardados: %d grupos, %d usuarios/moneda", len(groupSnapshots), len(userOrder))
+	return nil
+}
+
+// GetGroupSnapshots obtiene los snapshots agregados de un grupo en orden cronológico
+func (ass *AggregateSnapshotService) GetGroupSnapshots(groupID string, from, to *time.Time) ([]models.GroupSnapshot, error) {
+	var group models.Group
+	if err := database.DB.First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	query := database.DB.Where("\"groupId\" = ?", group.ID)
+	query = applyCreatedAtRange(query, from, to)
+
+	snapshots := []models.GroupSnapshot{}
+	if err := query.Order("\"createdAt\" ASC").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots del grupo: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetUserSnapshots obtiene los snapshots agregados de un usuario, opcionalmente de una sola moneda
+func (ass *AggregateSnapshotService) GetUserSnapshots(userID, currency string, from, to *time.Time) ([]models.UserSnapshot, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	query := database.DB.Where("\"userId\" = ?", user.ID)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	query = applyCreatedAtRange(query, from, to)
+
+	snapshots := []models.UserSnapshot{}
+	if err := query.Order("\"createdAt\" ASC, currency ASC").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots del usuario: %w", err)
+	}
+	return snapshots, nil
+}
+
+// applyCreatedAtRange filtra una consulta por el rango de createdAt
+func applyCreatedAtRange(query *gorm.DB, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		query = query.Where("\"createdAt\" >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("\"createdAt\" <= ?", *to)
+	}
+	return query
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+)
+
+type AlertService struct {
+	notifiers map[string]AlertNotifier
+}
+
+// NewAlertService crea una nueva instancia del servicio de alertas con los canales por defecto
+func NewAlertService() *AlertService {
+	as := &AlertService{
+		notifiers: make(map[string]AlertNotifier),
+	}
+
+	as.RegisterNotifier(NewWebhookNotifier(NewWebhookService()))
+	as.RegisterNotifier(NewEmailNotifier())
+
+	return as
+}
+
+// RegisterNotifier agrega (o reemplaza) el notificador de un canal
+func (as *AlertService) RegisterNotifier(notifier AlertNotifier) {
+	as.notifiers[notifier.Channel()] = notifier
+}
+
+// CreateRule valida y crea una regla de alerta para un usuario
+func (as *AlertService) CreateRule(rule *models.AlertRule) error {
+	if err := as.validateRule(rule); err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", rule.UserID).Error; err != nil {
+		return fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	// Las reglas sobre holdings también guardan el asset para evaluarlas al scrapearlo. Solo se
+	// aceptan holdings propios: la alerta envía al usuario el valor del holding.
+	if rule.HoldingID != nil {
+		var holding models.Holding
+		if err := database.DB.Preload("Group").First(&holding, "id = ?", *rule.HoldingID).Error; err != nil {
+			return fmt.Errorf("holding no encontrado: %w", err)
+		}
+		if holding.Group.UserID != rule.UserID {
+			return fmt.Errorf("el holding no pertenece al usuario")
+		}
+		rule.AssetID = &holding.AssetID
+	} else {
+		var asset models.Asset
+		if err := database.DB.First(&asset, "id = ?", *rule.AssetID).Error; err != nil {
+			return fmt.Errorf("asset no encontrado: %w", err)
+		}
+	}
+
+	rule.IsActive = true
+	rule.Triggered = false
+	rule.CreatedAt = time.Now()
+
+	if err := database.DB.Create(rule).Error; err != nil {
+		return fmt.Errorf("error creando regla de alerta: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRules obtiene las reglas de alerta de un usuario
+func (as *AlertService) GetUserRules(userID string) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := database.DB.Where("\"userId\" = ?", userID).
+		Order("\"createdAt\" DESC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo reglas de alerta: %w", err)
+	}
+	return rules, nil
+}
+
+// SetRuleActive activa o desactiva una regla de alerta
+func (as *AlertService) SetRuleActive(ruleID string, active bool) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := database.DB.First(&rule, "id = ?", ruleID).Error; err != nil {
+		return nil, fmt.Errorf("regla de alerta no encontrada: %w", err)
+	}
+
+	rule.IsActive = active
+	rule.Triggered = false
+
+	if err := database.DB.Save(&rule).Error; err != nil {
+		return nil, fmt.Errorf("error actualizando regla de alerta: %w", err)
+	}
+	return &rule, nil
+}
+
+// DeleteRule elimina una regla de alerta
+func (as *AlertService) DeleteRule(ruleID string) error {
+	result := database.DB.Delete(&models.AlertRule{}, "id = ?", ruleID)
+	if result.Error != nil {
+		return fmt.Errorf("error eliminando regla de alerta: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("regla de alerta no encontrada")
+	}
+	return nil
+}
+
+// GetUserTriggers obtiene las alertas disparadas más recientes de un usuario
+func (as *AlertService) GetUserTriggers(userID string, limit int) ([]models.AlertTrigger, error) {
+	var triggers []models.AlertTrigger
+	err := database.DB.Where("\"userId\" = ?", userID).
+		Order("\"createdAt\" DESC").
+		Limit(limit).
+		Find(&triggers).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo alertas disparadas: %w", err)
+	}
+	return triggers, nil
+}
+
+// EvaluateAsset evalúa las reglas activas de un asset luego de scrapear su precio
+func (as *AlertService) EvaluateAsset(asset *models.Asset, currentPrice, previousPrice float64) {
+	var rules []models.AlertRule
+	err := database.DB.Where("\"assetId\" = ? AND \"isActive\" = ?", asset.ID, true).Find(&rules).Error
+	if err != nil {
+		log.Printf("⚠️ Error obteniendo reglas de alerta para asset %s: %v", asset.Code, err)
+		return
+	}
+
+	for i := range rules {
+		as.evaluateRule(&rules[i], asset, currentPrice, previousPrice)
+	}
+}
+
+// evaluateRule evalúa una regla y dispara la alerta solo cuando la condición pasa a cumplirse
+func (as *AlertService) evaluateRule(rule *models.AlertRule, asset *models.Asset, currentPrice, previousPrice float64) {
+	holdingValue := 0.0
+	if rule.HoldingID != nil {
+		var holding models.Holding
+		if err := database.DB.First(&holding, "id = ?", *rule.HoldingID).Error; err != nil {
+			log.Printf("⚠️ Holding %s de la regla %s no encontrado: %v", *rule.HoldingID, rule.ID, err)
+			return
+		}
+		holdingValue = currentPrice * holding.Quantity
+	}
+
+	matches := rule.Evaluate(currentPrice, previousPrice, holdingValue)
+
+	// Las reglas de variación porcentual se evalúan por período y pueden repetirse
+	if rule.Type != models.AlertPercentMove && matches == rule.Triggered {
+		return
+	}
+
+	if !matches {
+		if rule.Triggered {
+			rule.Triggered = false
+			as.saveRule(rule)
+		}
+		return
+	}
+
+	now := time.Now()
+	rule.Triggered = true
+	rule.LastTriggeredAt = &now
+	as.saveRule(rule)
+
+	trigger := &models.AlertTrigger{
+		RuleID:        rule.ID,
+		UserID:        rule.UserID,
+		AssetID:       asset.ID,
+		HoldingID:     rule.HoldingID,
+		Type:          rule.Type,
+		Threshold:     rule.Threshold,
+		Price:         currentPrice,
+		PreviousPrice: previousPrice,
+		HoldingValue:  holdingValue,
+		Message:       as.buildMessage(rule, asset, currentPrice, previousPrice, holdingValue),
+		Channel:       rule.Channel,
+		CreatedAt:     now,
+	}
+
+	if err := database.DB.Create(trigger).Error; err != nil {
+		log.Printf("⚠️ Error registrando alerta disparada para regla %s: %v", rule.ID, err)
+		return
+	}
+
+	log.Printf("🔔 Alerta disparada: %s", trigger.Message)
+
+	as.dispatch(trigger)
+
+	if err := database.DB.Save(trigger).Error; err != nil {
+		log.Printf("⚠️ Error actualizando estado de la alerta %s: %v", trigger.ID, err)
+	}
+}
+
+// dispatch envía la alerta por el canal de la regla y registra el resultado
+func (as *AlertService) dispatch(trigger *models.AlertTrigger) {
+	notifier, ok := as.notifiers[trigger.Channel]
+	if !ok {
+		trigger.NotifyError = fmt.Sprintf("canal de notificación no soportado: %s", trigger.Channel)
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", trigger.UserID).Error; err != nil {
+		trigger.NotifyError = fmt.Sprintf("usuario no encontrado: %v", err)
+		return
+	}
+
+	if err := notifier.Notify(&user, trigger); err != nil {
+		trigger.NotifyError = err.Error()
+		log.Printf("⚠️ Error notificando alerta por %s a %s: %v", trigger.Channel, user.Email, err)
+		return
+	}
+
+	trigger.Notified = true
+}
+
+// buildMessage genera el texto descriptivo de la alerta
+func (as *AlertService) buildMessage(rule *models.AlertRule, asset *models.Asset, currentPrice, previousPrice, holdingValue float64) string {
+	switch rule.Type {
+	case models.AlertPriceAbove:
+		return fmt.Sprintf("%s superó %.2f (precio actual %.2f)", asset.Code, rule.Threshold, currentPrice)
+	case models.AlertPriceBelow:
+		return fmt.Sprintf("%s cayó por debajo de %.2f (precio actual %.2f)", asset.Code, rule.Threshold, currentPrice)
+	case models.AlertPercentMove:
+		change := ((currentPrice - previousPrice) / previousPrice) * 100
+		return fmt.Sprintf("%s varió %.2f%% desde el último snapshot (%.2f -> %.2f)", asset.Code, change, previousPrice, currentPrice)
+	case models.AlertHoldingValueAbove:
+		return fmt.Sprintf("Tu holding de %s superó un valor de %.2f (valor actual %.2f)", asset.Code, rule.Threshold, holdingValue)
+	case models.AlertHoldingValueBelow:
+		return fmt.Sprintf("Tu holding de %s cayó por debajo de un valor de %.2f (valor actual %.2f)", asset.Code, rule.Threshold, holdingValue)
+	default:
+		return fmt.Sprintf("Alerta %s para %s", rule.Type, asset.Code)
+	}
+}
+
+// validateRule valida tipo, canal y destino de la regla
+func (as *AlertService) validateRule(rule *models.AlertRule) error {
+	switch rule.Type {
+	case models.AlertPriceAbove, models.AlertPriceBelow, models.AlertPercentMove,
+		models.AlertHoldingValueAbove, models.AlertHoldingValueBelow:
+	default:
+		return fmt.Errorf("tipo de alerta inválido: %s", rule.Type)
+	}
+
+	if rule.Channel == "" {
+		rule.Channel = models.AlertChannelWebhook
+	}
+	if _, ok := as.notifiers[rule.Channel]; !ok {
+		return fmt.Errorf("canal de notificación inválido: %s", rule.Channel)
+	}
+
+	if rule.Threshold <= 0 {
+		return fmt.Errorf("el umbral debe ser mayor a 0")
+	}
+
+	if rule.AssetID == nil && rule.HoldingID == nil {
+		return fmt.Errorf("se requiere assetId o holdingId")
+	}
+	if rule.AssetID != nil && rule.HoldingID != nil {
+		return fmt.Errorf("se debe indicar assetId o holdingId, no ambos")
+	}
+
+	if rule.IsHoldingRule() && rule.HoldingID == nil {
+		return fmt.Errorf("las alertas de valor de holding requieren holdingId")
+	}
+
+	return nil
+}
+
+// saveRule persiste el estado de la regla
+func (as *AlertService) saveRule(rule *models.AlertRule) {
+	if err := database.DB.Save(rule).Error; err != nil {
+		log.Printf("⚠️ Error actualizando regla de alerta %s: %v", rule.ID, err)
+	}
+}
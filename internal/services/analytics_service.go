@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/performance"
+	"holding-snapshots/pkg/cache"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// HoldingRisk son las métricas de riesgo de un holding
+type HoldingRisk struct {
+	HoldingID string `json:"holdingId"`
+	Code      string `json:"code"`
+	Currency  string `json:"currency"`
+	*performance.RiskMetrics
+}
+
+// CurrencyRisk son las métricas de riesgo de un conjunto de holdings expresado en una moneda
+type CurrencyRisk struct {
+	Currency string `json:"currency"`
+	*performance.RiskMetrics
+}
+
+// GroupRisk son las métricas de riesgo de un grupo
+type GroupRisk struct {
+	GroupID   string `json:"groupId"`
+	Name      string `json:"name"`
+	FXVariant string `json:"fxVariant,omitempty"`
+	CurrencyRisk
+}
+
+// UserRisk son las métricas de riesgo de los grupos de un usuario, separadas por moneda
+// o en un único resultado si se pidió una moneda base
+type UserRisk struct {
+	UserID    string         `json:"userId"`
+	FXVariant string         `json:"fxVariant,omitempty"`
+	Results   []CurrencyRisk `json:"results"`
+}
+
+// BenchmarkComparison es el rendimiento de un portafolio comparado con su benchmark
+type BenchmarkComparison struct {
+	Currency          string `json:"currency"`
+	BenchmarkAssetID  string `json:"benchmarkAssetId"`
+	BenchmarkCode     string `json:"benchmarkCode"`
+	BenchmarkCurrency string `json:"benchmarkCurrency"`
+	*performance.Comparison
+}
+
+// GroupBenchmarkComparison es la comparación de un grupo contra su benchmark
+type GroupBenchmarkComparison struct {
+	GroupID   string `json:"groupId"`
+	Name      string `json:"name"`
+	FXVariant string `json:"fxVariant,omitempty"`
+	BenchmarkComparison
+}
+
+// UserBenchmarkComparison es la comparación de los grupos de un usuario contra su benchmark,
+// separada por moneda o en un único resultado si se pidió una moneda base
+type UserBenchmarkComparison struct {
+	UserID    string                `json:"userId"`
+	FXVariant string                `json:"fxVariant,omitempty"`
+	Results   []BenchmarkComparison `json:"results"`
+}
+
+type AnalyticsService struct {
+	performanceService *PerformanceService
+	benchmarkService   *BenchmarkService
+	assetPriceService  *AssetPriceService
+	fxService          *FXService
+}
+
+// NewAnalyticsService crea una nueva instancia del servicio de analytics
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{
+		performanceService: NewPerformanceService(),
+		benchmarkService:   NewBenchmarkService(),
+		assetPriceService:  NewAssetPriceService(),
+		fxService:          NewFXService(),
+	}
+}
+
+// riskFreeRate retorna la tasa libre de riesgo configurada
+func (as *AnalyticsService) riskFreeRate() float64 {
+	if config.AppConfig != nil {
+		return config.AppConfig.RiskFreeRate
+	}
+	return 0
+}
+
+// cacheTTL retorna el tiempo de vida de los resultados cacheados
+func (as *AnalyticsService) cacheTTL() time.Duration {
+	if config.AppConfig != nil && config.AppConfig.AnalyticsCacheTTLHours > 0 {
+		return time.Duration(config.AppConfig.AnalyticsCacheTTLHours) * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// GetHoldingRisk calcula las métricas de riesgo de un holding
+func (as *AnalyticsService) GetHoldingRisk(holdingID string, from, to *time.Time) (*HoldingRisk, error) {
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("id = ?", holdingID)
+	key, err := as.cacheKey("risk:holding:"+holdingID, holdings, from, to, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached HoldingRisk
+	if as.getCached(key, &cached) {
+		return &cached, nil
+	}
+
+	holding, series, err := as.performanceService.loadHoldingSeries(holdingID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := performance.ComputeRisk(series.Series, as.riskFreeRate())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HoldingRisk{
+		HoldingID:   holding.ID,
+		Code:        holding.Asset.Code,
+		Currency:    series.Currency,
+		RiskMetrics: metrics,
+	}
+
+	as.setCached(key, result)
+	return result, nil
+}
+
+// GetGroupRisk calcula las métricas de riesgo de un grupo, convertido a la moneda base si opts no es nil
+func (as *AnalyticsService) GetGroupRisk(groupID string, from, to *time.Time, opts *CurrencyOptions) (*GroupRisk, error) {
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("\"groupId\" = ?", groupID)
+	key, err := as.cacheKey("risk:group:"+groupID, holdings, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached GroupRisk
+	if as.getCached(key, &cached) {
+		return &cached, nil
+	}
+
+	group, series, err := as.performanceService.loadGroupSeries(groupID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := performance.ComputeRisk(series.Series, as.riskFreeRate())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupRisk{
+		GroupID:      group.ID,
+		Name:         group.Name,
+		CurrencyRisk: CurrencyRisk{Currency: series.Currency, RiskMetrics: metrics},
+	}
+	if opts != nil {
+		result.FXVariant = opts.Variant
+	}
+
+	as.setCached(key, result)
+	return result, nil
+}
+
+// GetUserRisk calcula las métricas de riesgo de todos los grupos de un usuario
+func (as *AnalyticsService) GetUserRisk(userID string, from, to *time.Time, opts *CurrencyOptions) (*UserRisk, error) {
+	groups := database.DB.Model(&models.Group{}).Select("id").Where(&models.Group{UserID: userID})
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("\"groupId\" IN (?)", groups)
+	key, err := as.cacheKey("risk:user:"+userID, holdings, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached UserRisk
+	if as.getCached(key, &cached) {
+		return &cached, nil
+	}
+
+	seriesByCurrency, err := as.performanceService.loadUserSeries(userID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserRisk{UserID: userID, Results: []CurrencyRisk{}}
+	if opts != nil {
+		result.FXVariant = opts.Variant
+	}
+
+	for _, series := range seriesByCurrency {
+		metrics, err := performance.ComputeRisk(series.Series, as.riskFreeRate())
+		if err != nil {
+			return nil, fmt.Errorf("moneda %s: %w", series.Currency, err)
+		}
+		result.Results = append(result.Results, CurrencyRisk{Currency: series.Currency, RiskMetrics: metrics})
+	}
+
+	as.setCached(key, result)
+	return result, nil
+}
+
+// GetGroupBenchmarkComparison compara el rendimiento de un grupo con su benchmark.
+// assetID permite comparar contra otro asset sin cambiar el benchmark asignado.
+func (as *AnalyticsService) GetGroupBenchmarkComparison(groupID, assetID string, from, to *time.Time, opts *CurrencyOptions) (*GroupBenchmarkComparison, error) {
+	benchmark, err := as.resolveBenchmark(assetID, func() (*BenchmarkAssignment, error) {
+		return as.benchmarkService.GetGroupBenchmark(groupID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	group, series, err := as.performanceService.loadGroupSeries(groupID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := as.compare(series, benchmark, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupBenchmarkComparison{
+		GroupID:             group.ID,
+		Name:                group.Name,
+		BenchmarkComparison: *comparison,
+	}
+	if opts != nil {
+		result.FXVariant = opts.Variant
+	}
+
+	return result, nil
+}
+
+// GetUserBenchmarkComparison compara el rendimiento de los grupos de un usuario con su benchmark
+func (as *AnalyticsService) GetUserBenchmarkComparison(userID, assetID string, from, to *time.Time, opts *CurrencyOptions) (*UserBenchmarkComparison, error) {
+	benchmark, err := as.resolveBenchmark(assetID, func() (*BenchmarkAssignment, error) {
+		return as.benchmarkService.GetUserBenchmark(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seriesByCurrency, err := as.performanceService.loadUserSeries(userID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserBenchmarkComparison{UserID: userID, Results: []BenchmarkComparison{}}
+	if opts != nil {
+		result.FXVariant = opts.Variant
+	}
+
+	for i := range seriesByCurrency {
+		comparison, err := as.compare(&seriesByCurrency[i], benchmark, from, to, opts)
+		if err != nil {
+			return nil, fmt.Errorf("moneda %s: %w", seriesByCurrency[i].Currency, err)
+		}
+		result.Results = append(result.Results, *comparison)
+	}
+
+	return result, nil
+}
+
+// resolveBenchmark usa el asset indicado o, si está vacío, el benchmark asignado
+func (as *AnalyticsService) resolveBenchmark(assetID string, assigned func() (*BenchmarkAssignment, error)) (*models.Asset, error) {
+	if assetID == "" {
+		assignment, err := assigned()
+		if err != nil {
+			return nil, err
+		}
+		return &assignment.Asset, nil
+	}
+
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", assetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+	return &asset, nil
+}
+
+// compare calcula la comparación de una serie contra los precios del benchmark,
+// convirtiéndolos a la moneda de la serie si es distinta
+func (as *AnalyticsService) compare(series *currencySeries, benchmark *models.Asset, from, to *time.Time, opts *CurrencyOptions) (*BenchmarkComparison, error) {
+	prices, err := as.assetPriceService.GetDailyPrices(benchmark.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	benchmarkCurrency := benchmark.Type.Currency
+	if benchmarkCurrency != "" && benchmarkCurrency != series.Currency {
+		variant := ""
+		if opts != nil {
+			variant = opts.Variant
+		}
+
+		fxOpts, err := ParseCurrencyOptions(series.Currency, variant)
+		if err != nil {
+			return nil, err
+		}
+
+		converter, err := as.fxService.NewConverter(fxOpts.Variant, []string{benchmarkCurrency, series.Currency}, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range prices {
+			prices[i].Value, err = converter.Convert(prices[i].Value, benchmarkCurrency, series.Currency, prices[i].Date)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	comparison, err := performance.CompareToBenchmark(series.Series, prices, as.riskFreeRate())
+	if err != nil {
+		return nil, err
+	}
+
+	return &BenchmarkComparison{
+		Currency:          series.Currency,
+		BenchmarkAssetID:  benchmark.ID,
+		BenchmarkCode:     benchmark.Code,
+		BenchmarkCurrency: benchmarkCurrency,
+		Comparison:        comparison,
+	}, nil
+}
+
+// cacheKey arma la clave de cache de un cálculo con la fecha del último snapshot de los holdings
+// involucrados, de modo que cada scraping invalide los resultados anteriores, y la del último evento
+// corporativo aplicado a sus assets, porque un split reescribe el historial de snapshots. Con
+// conversión de moneda incluye también la fecha de la última cotización de las monedas involucradas.
+func (as *AnalyticsService) cacheKey(scope string, holdings *gorm.DB, from, to *time.Time, opts *CurrencyOptions) (string, error) {
+	assets := database.DB.Model(&models.Holding{}).Select("\"assetId\"").Where("id IN (?)", holdings)
+
+	var snapshotAt, appliedAt *time.Time
+	err := database.DB.Raw(`SELECT
+			(SELECT MAX("createdAt") FROM "Snapshot" WHERE "holdingId" IN (?)),
+			(SELECT MAX("appliedAt") FROM "CorporateAction" WHERE "assetId" IN (?))`,
+		holdings, assets).
+		Row().Scan(&snapshotAt, &appliedAt)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo versión de los datos: %w", err)
+	}
+
+	key := fmt.Sprintf("analytics:%s:s@%s:ca@%s:%s:%s:rf=%g", scope, formatKeyDate(snapshotAt), formatKeyDate(appliedAt),
+		formatKeyDate(from), formatKeyDate(to), as.riskFreeRate())
+	if opts == nil {
+		return key, nil
+	}
+
+	fxAt, err := as.latestFXRate(holdings, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return key + fmt.Sprintf(":%s:%s:fx@%s", opts.BaseCurrency, opts.Variant, formatKeyDate(fxAt)), nil
+}
+
+// latestFXRate retorna la fecha de la última cotización de la variante entre la moneda base y las
+// monedas de los grupos de los holdings, que son las que usa la conversión
+func (as *AnalyticsService) latestFXRate(holdings *gorm.DB, opts *CurrencyOptions) (*time.Time, error) {
+	groups := database.DB.Model(&models.Holding{}).Select("\"groupId\"").Where("id IN (?)", holdings)
+	types := database.DB.Model(&models.Group{}).Select("\"typeId\"").Where("id IN (?)", groups)
+
+	var currencies []string
+	err := database.DB.Model(&models.TypeInvestment{}).
+		Where("id IN (?)", types).
+		Distinct().
+		Pluck("currency", &currencies).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo monedas de los holdings: %w", err)
+	}
+
+	codes := []string{strings.ToUpper(opts.BaseCurrency)}
+	for _, currency := range currencies {
+		codes = append(codes, strings.ToUpper(currency))
+	}
+
+	var fxAt *time.Time
+	err = database.DB.Model(&models.FXRate{}).
+		Select("MAX(\"createdAt\")").
+		Where("variant = ? AND base IN ? AND quote IN ?", opts.Variant, codes, codes).
+		Row().Scan(&fxAt)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo última cotización: %w", err)
+	}
+
+	return fxAt, nil
+}
+
+// getCached carga un resultado cacheado; cualquier error de Redis se trata como cache miss
+func (as *AnalyticsService) getCached(key string, target interface{}) bool {
+	if cache.GetClient() == nil {
+		return false
+	}
+
+	data, err := cache.Get(context.Background(), key)
+	if err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(data), target); err != nil {
+		return false
+	}
+
+	log.Printf("📦 Resultado de analytics encontrado en cache: %s", key)
+	return true
+}
+
+// setCached guarda un resultado en cache; los errores solo se registran
+func (as *AnalyticsService) setCached(key string, value interface{}) {
+	if cache.GetClient() == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	if err := cache.Set(context.Background(), key, string(data), as.cacheTTL()); err != nil {
+		log.Printf("⚠️ Error guardando analytics en cache: %v", err)
+	}
+}
+
+// formatKeyDate formatea una fecha opcional para usarla en una clave de cache
+func formatKeyDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
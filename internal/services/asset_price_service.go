@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/performance"
+	"holding-snapshots/pkg/database"
+)
+
+// PricePoint es el precio de cierre de un asset en un día
+type PricePoint struct {
+	Date  time.Time `json:"date"`
+	Price float64   `json:"price"`
+}
+
+// AssetPriceHistory es la serie de precios diarios de un asset
+type AssetPriceHistory struct {
+	AssetID  string       `json:"assetId"`
+	Code     string       `json:"code"`
+	Name     string       `json:"name"`
+	Currency string       `json:"currency"`
+	Points   []PricePoint `json:"points"`
+}
+
+type AssetPriceService struct{}
+
+// NewAssetPriceService crea una nueva instancia del servicio de precios de assets
+func NewAssetPriceService() *AssetPriceService {
+	return &AssetPriceService{}
+}
+
+// RecordPrice guarda el precio de un asset obtenido por scraping
+func (aps *AssetPriceService) RecordPrice(assetID string, price float64, runID string) (*models.AssetPrice, error) {
+	assetPrice := &models.AssetPrice{
+		AssetID:   assetID,
+		Price:     price,
+		Source:    models.AssetPriceSourceScraper,
+		RunID:     runID,
+		CreatedAt: time.Now(),
+	}
+
+	if err := database.DB.Create(assetPrice).Error; err != nil {
+		return nil, fmt.Errorf("error guardando precio del asset: %w", err)
+	}
+
+	return assetPrice, nil
+}
+
+// GetHistory obtiene la serie de precios diarios de un asset con sus datos
+func (aps *AssetPriceService) GetHistory(assetID string, from, to *time.Time) (*AssetPriceHistory, error) {
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", assetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+
+	prices, err := aps.GetDailyPrices(asset.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &AssetPriceHistory{
+		AssetID:  asset.ID,
+		Code:     asset.Code,
+		Name:     asset.Name,
+		Currency: asset.Type.Currency,
+		Points:   make([]PricePoint, 0, len(prices)),
+	}
+	for _, price := range prices {
+		history.Points = append(history.Points, PricePoint{Date: price.Date, Price: price.Value})
+	}
+
+	return history, nil
+}
+
+// GetDailyPrices obtiene el último precio de cada día de un asset. Usa AssetPrice y, para los días
+// anteriores a esa tabla, los precios copiados en los snapshots de los holdings del asset.
+func (aps *AssetPriceService) GetDailyPrices(assetID string, from, to *time.Time) ([]performance.Valuation, error) {
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("\"assetId\" = ?", assetID)
+
+	priceConditions := "\"assetId\" = ?"
+	snapshotConditions := "\"holdingId\" IN (?) AND \"assetPriceId\" IS NULL"
+	priceArgs := []interface{}{assetID}
+	snapshotArgs := []interface{}{holdings}
+
+	if from != nil {
+		priceConditions += " AND \"createdAt\" >= ?"
+		snapshotConditions += " AND \"createdAt\" >= ?"
+		priceArgs = append(priceArgs, *from)
+		snapshotArgs = append(snapshotArgs, *from)
+	}
+	if to != nil {
+		priceConditions += " AND \"createdAt\" <= ?"
+		snapshotConditions += " AND \"createdAt\" <= ?"
+		priceArgs = append(priceArgs, *to)
+		snapshotArgs = append(snapshotArgs, *to)
+	}
+
+	// En un mismo día se prefiere AssetPrice (priority 0) sobre los snapshots
+	query := `
+		SELECT DISTINCT ON (day) day, price
+		FROM (
+			SELECT date_trunc('day', "createdAt") AS day, price, "createdAt", 0 AS priority
+			FROM "AssetPrice"
+			WHERE ` + priceConditions + `
+			UNION ALL
+			SELECT date_trunc('day', "createdAt") AS day, price, "createdAt", 1 AS priority
+			FROM "Snapshot"
+			WHERE ` + snapshotConditions + `
+		) AS daily
+		ORDER BY day, priority, "createdAt" DESC`
+
+	var rows []struct {
+		Day   time.Time
+		Price float64
+	}
+	if err := database.DB.Raw(query, append(priceArgs, snapshotArgs...)...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo historial de precios: %w", err)
+	}
+
+	prices := make([]performance.Valuation, 0, len(rows))
+	for _, row := range rows {
+		prices = append(prices, performance.Valuation{Date: truncateDay(row.Day), Value: row.Price})
+	}
+	return prices, nil
+}
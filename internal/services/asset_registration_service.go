@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/symbols"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm/clause"
+)
+
+type AssetRegistrationService struct {
+	symbolSearchService  *SymbolSearchService
+	symbolMappingService *SymbolMappingService
+}
+
+// NewAssetRegistrationService crea una nueva instancia del servicio de registro de assets validados
+func NewAssetRegistrationService() *AssetRegistrationService {
+	return &AssetRegistrationService{
+		symbolSearchService:  NewSymbolSearchService(),
+		symbolMappingService: NewSymbolMappingService(),
+	}
+}
+
+// Register crea o actualiza el asset de un código ya validado. El código se guarda normalizado al
+// canónico del tipo de inversión y el nombre se toma del proveedor de búsqueda (o el código si no lo encuentra). Si el asset ya existe
+// se marca como válido y, con refreshPrice, se actualiza su lastPrice; un precio servido desde el
+// cache de validaciones puede ser más viejo que el del cron y no se usa para pisarlo.
+// Retorna el asset y si fue creado.
+func (ars *AssetRegistrationService) Register(typeID, code string, price float64, refreshPrice bool) (*models.Asset, bool, error) {
+	var typeInvestment models.TypeInvestment
+	if err := database.DB.First(&typeInvestment, "id = ?", typeID).Error; err != nil {
+		return nil, false, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+	}
+
+	code = ars.symbolMappingService.Normalize(&typeInvestment, code)
+	if code == "" {
+		return nil, false, fmt.Errorf("código vacío")
+	}
+	if price <= 0 {
+		return nil, false, fmt.Errorf("no se puede registrar un asset sin precio")
+	}
+
+	// Los assets previos a la normalización pueden tener el código guardado como GGAL.BA o BRK-B
+	asset, err := ars.symbolMappingService.FindAsset(&typeInvestment, code)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if asset == nil {
+		asset = &models.Asset{
+			Name:      ars.symbolSearchService.LookupName(ars.symbolMappingService.ProviderSymbol(&typeInvestment, code, symbols.ProviderYahoo)),
+			Code:      code,
+			LastPrice: price,
+			TypeID:    typeID,
+			IsValid:   true,
+		}
+
+		// El índice único de tipo y código resuelve la carrera entre dos validaciones del mismo código; sin
+		// columnas de conflicto sirve tanto con idx_asset_type_code como con el índice previo sobre code
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(asset)
+		if result.Error != nil {
+			return nil, false, fmt.Errorf("error creando asset: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			log.Printf("🆕 Asset registrado desde validación: %s (%s)", asset.Name, asset.Code)
+			return asset, true, nil
+		}
+
+		if asset, err = ars.symbolMappingService.FindAsset(&typeInvestment, code); err != nil {
+			return nil, false, err
+		}
+		if asset == nil {
+			return nil, false, fmt.Errorf("no se pudo registrar el asset %s", code)
+		}
+	}
+
+	updates := map[string]interface{}{"is_valid": true}
+	if refreshPrice || asset.LastPrice <= 0 {
+		updates["lastPrice"] = price
+	}
+	if err := database.DB.Model(asset).Updates(updates).Error; err != nil {
+		return nil, false, fmt.Errorf("error actualizando asset: %w", err)
+	}
+
+	return asset, false, nil
+}
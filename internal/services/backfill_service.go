@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/internal/symbols"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm/clause"
+)
+
+// backfillBatchSize es la cantidad de precios insertados por lote; el progreso se actualiza por lote
+const backfillBatchSize = 100
+
+// BackfillRequest son los parámetros de una carga de precios históricos
+type BackfillRequest struct {
+	AssetID  string
+	Symbol   string // Símbolo en el proveedor; por defecto el código del asset
+	Interval string // day o week
+	From     time.Time
+	To       time.Time
+}
+
+type BackfillService struct {
+	provider scraping.HistoryProvider
+}
+
+// NewBackfillService crea una nueva instancia del servicio de backfill de precios
+func NewBackfillService() *BackfillService {
+	url := "https://query1.finance.yahoo.com/v8/finance/chart"
+	if config.AppConfig != nil && config.AppConfig.BackfillProviderURL != "" {
+		url = config.AppConfig.BackfillProviderURL
+	}
+
+	return &BackfillService{
+		provider: scraping.NewYahooChartProvider(url),
+	}
+}
+
+// CreateJob valida la request y registra un backfill pendiente
+func (bs *BackfillService) CreateJob(req BackfillRequest) (*models.BackfillJob, error) {
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", req.AssetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+
+	if req.Symbol == "" {
+		req.Symbol = NewSymbolMappingService().ProviderSymbol(&asset.Type, asset.Code, symbols.ProviderYahoo)
+	}
+	if req.Interval == "" {
+		req.Interval = scraping.HistoryIntervalDay
+	}
+	if req.Interval != scraping.HistoryIntervalDay && req.Interval != scraping.HistoryIntervalWeek {
+		return nil, fmt.Errorf("intervalo inválido: %s (day o week)", req.Interval)
+	}
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		return nil, fmt.Errorf("la fecha de inicio es requerida")
+	}
+	if !req.From.Before(req.To) {
+		return nil, fmt.Errorf("la fecha de inicio debe ser anterior a la de fin")
+	}
+
+	job := &models.BackfillJob{
+		AssetID:   asset.ID,
+		Symbol:    req.Symbol,
+		Interval:  req.Interval,
+		From:      req.From,
+		To:        req.To,
+		Status:    models.BackfillStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	if err := database.DB.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("error registrando backfill: %w", err)
+	}
+
+	return job, nil
+}
+
+// StartJob ejecuta el backfill en segundo plano
+func (bs *BackfillService) StartJob(job *models.BackfillJob) {
+	go func() {
+		if err := bs.RunJob(job); err != nil {
+			log.Printf("❌ Backfill %s fallido: %v", job.ID, err)
+		}
+	}()
+}
+
+// FailInterrupted marca como fallidos los backfills que quedaron pendientes o en ejecución antes de
+// un reinicio: se ejecutaban en una goroutine del proceso anterior y nadie los va a terminar
+func (bs *BackfillService) FailInterrupted() (int64, error) {
+	result := database.DB.Model(&models.BackfillJob{}).
+		Where("status IN ?", []string{models.BackfillStatusPending, models.BackfillStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":     models.BackfillStatusFailed,
+			"error":      "interrumpido por un reinicio del servidor",
+			"finishedAt": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error marcando backfills interrumpidos: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("⚠️ %d backfills interrumpidos marcados como fallidos", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+// RunJob obtiene los precios históricos y los guarda en AssetPrice. Es idempotente:
+// los precios ya cargados para el mismo asset y fecha se cuentan como omitidos.
+func (bs *BackfillService) RunJob(job *models.BackfillJob) error {
+	startedAt := time.Now()
+	job.Status = models.BackfillStatusRunning
+	job.StartedAt = &startedAt
+	job.Error = ""
+	bs.saveProgress(job)
+
+	log.Printf("⏳ Backfill %s: %s desde %s hasta %s (%s)",
+		job.ID, job.Symbol, job.From.Format("2006-01-02"), job.To.Format("2006-01-02"), job.Interval)
+
+	history, err := bs.provider.FetchHistory(job.Symbol, job.From, job.To, job.Interval)
+	if err != nil {
+		return bs.fail(job, err)
+	}
+
+	job.TotalPoints = len(history)
+	job.InsertedPoints = 0
+	job.SkippedPoints = 0
+	bs.saveProgress(job)
+
+	for start := 0; start < len(history); start += backfillBatchSize {
+		end := start + backfillBatchSize
+		if end > len(history) {
+			end = len(history)
+		}
+
+		prices := make([]models.AssetPrice, 0, end-start)
+		for _, point := range history[start:end] {
+			prices = append(prices, models.AssetPrice{
+				AssetID:   job.AssetID,
+				Price:     point.Close,
+				Source:    models.AssetPriceSourceBackfill,
+				RunID:     job.ID,
+				CreatedAt: truncateDay(point.Date),
+			})
+		}
+
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&prices)
+		if result.Error != nil {
+			return bs.fail(job, fmt.Errorf("error guardando precios: %w", result.Error))
+		}
+
+		job.InsertedPoints += int(result.RowsAffected)
+		job.SkippedPoints += len(prices) - int(result.RowsAffected)
+		bs.saveProgress(job)
+	}
+
+	finishedAt := time.Now()
+	job.Status = models.BackfillStatusCompleted
+	job.FinishedAt = &finishedAt
+	bs.saveProgress(job)
+
+	log.Printf("✅ Backfill %s completado: %d precios, %d nuevos, %d ya existentes",
+		job.ID, job.TotalPoints, job.InsertedPoints, job.SkippedPoints)
+	return nil
+}
+
+// GetJob obtiene un backfill por ID
+func (bs *BackfillService) GetJob(id string) (*models.BackfillJob, error) {
+	var job models.BackfillJob
+	if err := database.DB.First(&job, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("backfill no encontrado: %w", err)
+	}
+	return &job, nil
+}
+
+// GetJobs lista los backfills más recientes, opcionalmente filtrados por asset y estado
+func (bs *BackfillService) GetJobs(assetID, status string, limit int) ([]models.BackfillJob, error) {
+	query := database.DB.Order("\"createdAt\" DESC").Limit(limit)
+	if assetID != "" {
+		query = query.Where("\"assetId\" = ?", assetID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var jobs []models.BackfillJob
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo backfills: %w", err)
+	}
+	return jobs, nil
+}
+
+// fail marca el backfill como fallido y retorna el error
+func (bs *BackfillService) fail(job *models.BackfillJob, err error) error {
+	finishedAt := time.Now()
+	job.Status = models.BackfillStatusFailed
+	job.Error = err.Error()
+	job.FinishedAt = &finishedAt
+	bs.saveProgress(job)
+	return err
+}
+
+// saveProgress guarda el estado del backfill; los errores solo se registran
+func (bs *BackfillService) saveProgress(job *models.BackfillJob) {
+	if err := database.DB.Save(job).Error; err != nil {
+		log.Printf("⚠️ Error guardando progreso del backfill %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+)
+
+// BenchmarkAssignment es el benchmark asignado a un grupo o usuario junto con su asset
+type BenchmarkAssignment struct {
+	Scope     string       `json:"scope"`
+	OwnerID   string       `json:"ownerId"`
+	Inherited bool         `json:"inherited"` // true si el grupo usa el benchmark de su usuario
+	Asset     models.Asset `json:"asset"`
+}
+
+type BenchmarkService struct{}
+
+// NewBenchmarkService crea una nueva instancia del servicio de benchmarks
+func NewBenchmarkService() *BenchmarkService {
+	return &BenchmarkService{}
+}
+
+// SetBenchmark asigna (o reemplaza) el asset de referencia de un grupo o usuario
+func (bs *BenchmarkService) SetBenchmark(scope, ownerID, assetID string) (*BenchmarkAssignment, error) {
+	if err := bs.ensureOwnerExists(scope, ownerID); err != nil {
+		return nil, err
+	}
+
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", assetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+
+	var benchmark models.Benchmark
+	err := database.DB.Where("scope = ? AND \"ownerId\" = ?", scope, ownerID).Limit(1).Find(&benchmark).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo benchmark: %w", err)
+	}
+
+	benchmark.Scope = scope
+	benchmark.OwnerID = ownerID
+	benchmark.AssetID = asset.ID
+	benchmark.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(&benchmark).Error; err != nil {
+		return nil, fmt.Errorf("error guardando benchmark: %w", err)
+	}
+
+	return &BenchmarkAssignment{Scope: scope, OwnerID: ownerID, Asset: asset}, nil
+}
+
+// DeleteBenchmark quita el benchmark de un grupo o usuario
+func (bs *BenchmarkService) DeleteBenchmark(scope, ownerID string) error {
+	result := database.DB.Where("scope = ? AND \"ownerId\" = ?", scope, ownerID).Delete(&models.Benchmark{})
+	if result.Error != nil {
+		return fmt.Errorf("error eliminando benchmark: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no hay benchmark asignado")
+	}
+	return nil
+}
+
+// GetGroupBenchmark obtiene el benchmark de un grupo; si no tiene uno propio usa el de su usuario
+func (bs *BenchmarkService) GetGroupBenchmark(groupID string) (*BenchmarkAssignment, error) {
+	var group models.Group
+	if err := database.DB.First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	assignment, err := bs.findAssignment(models.BenchmarkScopeGroup, group.ID)
+	if err != nil || assignment != nil {
+		return assignment, err
+	}
+
+	assignment, err = bs.findAssignment(models.BenchmarkScopeUser, group.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil {
+		return nil, fmt.Errorf("el grupo no tiene benchmark asignado")
+	}
+
+	assignment.Inherited = true
+	return assignment, nil
+}
+
+// GetUserBenchmark obtiene el benchmark de un usuario
+func (bs *BenchmarkService) GetUserBenchmark(userID string) (*BenchmarkAssignment, error) {
+	if err := bs.ensureOwnerExists(models.BenchmarkScopeUser, userID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := bs.findAssignment(models.BenchmarkScopeUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil {
+		return nil, fmt.Errorf("el usuario no tiene benchmark asignado")
+	}
+	return assignment, nil
+}
+
+// findAssignment busca el benchmark de un dueño; retorna nil si no tiene
+func (bs *BenchmarkService) findAssignment(scope, ownerID string) (*BenchmarkAssignment, error) {
+	var benchmark models.Benchmark
+	err := database.DB.Where("scope = ? AND \"ownerId\" = ?", scope, ownerID).Limit(1).Find(&benchmark).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo benchmark: %w", err)
+	}
+	if benchmark.ID == "" {
+		return nil, nil
+	}
+
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", benchmark.AssetID).Error; err != nil {
+		return nil, fmt.Errorf("asset del benchmark no encontrado: %w", err)
+	}
+
+	return &BenchmarkAssignment{Scope: scope, OwnerID: ownerID, Asset: asset}, nil
+}
+
+// ensureOwnerExists verifica que el grupo o usuario exista
+func (bs *BenchmarkService) ensureOwnerExists(scope, ownerID string) error {
+	switch scope {
+	case models.BenchmarkScopeGroup:
+		var group models.Group
+		if err := database.DB.First(&group, "id = ?", ownerID).Error; err != nil {
+			return fmt.Errorf("grupo no encontrado: %w", err)
+		}
+	case models.BenchmarkScopeUser:
+		var user models.User
+		if err := database.DB.First(&user, "id = ?", ownerID).Error; err != nil {
+			return fmt.Errorf("usuario no encontrado: %w", err)
+		}
+	default:
+		return fmt.Errorf("alcance de benchmark inválido: %s", scope)
+	}
+	return nil
+}
@@ -0,0 +1,727 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/ledger"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/internal/symbols"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CorporateActionRequest representa la carga manual de un split o dividendo
+type CorporateActionRequest struct {
+	AssetID  string
+	Type     string
+	ExDate   time.Time
+	Ratio    float64
+	Amount   float64
+	Currency string
+	Apply    bool // Aplicarlo en el momento
+}
+
+// CorporateActionSyncRequest representa la importación de eventos desde el proveedor
+type CorporateActionSyncRequest struct {
+	AssetID string
+	From    time.Time
+	To      time.Time
+	Apply   bool // Aplicar los eventos pendientes del asset, en orden de fecha
+}
+
+// SplitAdjustment resume las filas ajustadas por un split
+type SplitAdjustment struct {
+	Holdings      int  `json:"holdings"`
+	Transactions  int  `json:"transactions"`
+	Snapshots     int  `json:"snapshots"`
+	SnapshotPnL   int  `json:"snapshotPnL"`
+	AggregateRuns int  `json:"aggregateRuns"` // Ejecuciones con GroupSnapshot/UserSnapshot recalculados
+	Rollups       int  `json:"rollups"`
+	AssetPrices   int  `json:"assetPrices"`
+	AlertRules    int  `json:"alertRules"`
+	LastPrice     bool `json:"lastPriceAdjusted"`
+}
+
+// pnlDelta es lo que cambió el aporte de un snapshot a los snapshots agregados de su grupo al ajustarlo
+// por un split. El valor cuenta en todas las ejecuciones hasta el siguiente snapshot del holding; la
+// variación solo en la primera, que es la ejecución que lo creó.
+type pnlDelta struct {
+	GroupID   string
+	From      time.Time
+	Until     *time.Time
+	Value     float64
+	CostBasis float64
+	Earnings  float64
+}
+
+// CorporateActionResult es un evento con el efecto de haberlo aplicado
+type CorporateActionResult struct {
+	Action    models.CorporateAction  `json:"action"`
+	Split     *SplitAdjustment        `json:"split,omitempty"`
+	Dividends []models.DividendIncome `json:"dividends,omitempty"`
+}
+
+// CorporateActionSyncReport es el resultado de importar eventos del proveedor
+type CorporateActionSyncReport struct {
+	AssetID string                  `json:"assetId"`
+	Symbol  string                  `json:"symbol"`
+	Found   int                     `json:"found"`
+	Created int                     `json:"created"`
+	Applied []CorporateActionResult `json:"applied"`
+}
+
+// HoldingDividends son los dividendos cobrados por un holding
+type HoldingDividends struct {
+	HoldingID string                  `json:"holdingId"`
+	Currency  string                  `json:"currency"`
+	Total     float64                 `json:"total"`
+	Dividends []models.DividendIncome `json:"dividends"`
+}
+
+type CorporateActionService struct {
+	provider             scraping.CorporateActionProvider
+	symbolMappingService *SymbolMappingService
+	earningsService      *EarningsService
+	pnlService           *PnLService
+}
+
+// NewCorporateActionService crea una nueva instancia del servicio de eventos corporativos
+func NewCorporateActionService() *CorporateActionService {
+	url := "https://query1.finance.yahoo.com/v8/finance/chart"
+	if config.AppConfig != nil && config.AppConfig.BackfillProviderURL != "" {
+		url = config.AppConfig.BackfillProviderURL
+	}
+
+	return &CorporateActionService{
+		provider:             scraping.NewYahooChartProvider(url),
+		symbolMappingService: NewSymbolMappingService(),
+		earningsService:      NewEarningsService(),
+		pnlService:           NewPnLService(),
+	}
+}
+
+// Create registra un split o dividendo cargado a mano y, si se pide, lo aplica
+func (cas *CorporateActionService) Create(req CorporateActionRequest) (*CorporateActionResult, error) {
+	if req.ExDate.IsZero() {
+		return nil, fmt.Errorf("la fecha ex es requerida")
+	}
+
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", req.AssetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+
+	action := &models.CorporateAction{
+		AssetID:   asset.ID,
+		Type:      req.Type,
+		ExDate:    truncateDay(req.ExDate),
+		Currency:  req.Currency,
+		Source:    models.CorporateActionSourceManual,
+		CreatedAt: time.Now(),
+	}
+
+	switch req.Type {
+	case models.CorporateActionSplit:
+		if req.Ratio <= 0 || math.Abs(req.Ratio-1) < 1e-9 {
+			return nil, fmt.Errorf("el ratio del split debe ser mayor a 0 y distinto de 1")
+		}
+		action.Ratio = req.Ratio
+	case models.CorporateActionDividend:
+		if req.Amount <= 0 {
+			return nil, fmt.Errorf("el importe por acción del dividendo debe ser mayor a 0")
+		}
+		action.Amount = req.Amount
+		if action.Currency == "" {
+			action.Currency = asset.Type.Currency
+		}
+	default:
+		return nil, fmt.Errorf("tipo de evento inválido: %s (split o dividend)", req.Type)
+	}
+
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(action)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error creando evento corporativo: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("ya existe un %s del asset con fecha ex %s", action.Type, action.ExDate.Format("2006-01-02"))
+	}
+
+	log.Printf("🏷️ Evento corporativo registrado: %s de %s (%s)", action.Type, asset.Code, action.ExDate.Format("2006-01-02"))
+
+	if !req.Apply {
+		return &CorporateActionResult{Action: *action}, nil
+	}
+	return cas.Apply(action.ID)
+}
+
+// Sync importa los splits y dividendos del proveedor; los que ya existen se omiten
+func (cas *CorporateActionService) Sync(req CorporateActionSyncRequest) (*CorporateActionSyncReport, error) {
+	var asset models.Asset
+	if err := database.DB.Preload("Type").First(&asset, "id = ?", req.AssetID).Error; err != nil {
+		return nil, fmt.Errorf("asset no encontrado: %w", err)
+	}
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.AddDate(-1, 0, 0)
+	}
+	if !req.From.Before(req.To) {
+		return nil, fmt.Errorf("la fecha de inicio debe ser anterior a la de fin")
+	}
+
+	symbol := cas.symbolMappingService.ProviderSymbol(&asset.Type, asset.Code, symbols.ProviderYahoo)
+	events, err := cas.provider.FetchCorporateActions(symbol, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CorporateActionSyncReport{
+		AssetID: asset.ID,
+		Symbol:  symbol,
+		Found:   len(events),
+		Applied: []CorporateActionResult{},
+	}
+
+	for _, event := range events {
+		action := &models.CorporateAction{
+			AssetID:   asset.ID,
+			Type:      models.CorporateActionSplit,
+			ExDate:    truncateDay(event.Date),
+			Ratio:     event.Ratio,
+			Source:    models.CorporateActionSourceProvider,
+			CreatedAt: time.Now(),
+		}
+		if event.Type == scraping.EventDividend {
+			action.Type = models.CorporateActionDividend
+			action.Ratio = 0
+			action.Amount = event.Amount
+			action.Currency = asset.Type.Currency
+		}
+
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(action)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error guardando evento corporativo: %w", result.Error)
+		}
+		report.Created += int(result.RowsAffected)
+	}
+
+	log.Printf("🏷️ Eventos corporativos de %s: %d encontrados, %d nuevos", asset.Code, report.Found, report.Created)
+
+	if req.Apply {
+		var pending []models.CorporateAction
+		err := database.DB.Where("\"assetId\" = ? AND \"appliedAt\" IS NULL AND \"exDate\" <= ?", asset.ID, time.Now()).
+			Order("\"exDate\" ASC").Find(&pending).Error
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo eventos pendientes: %w", err)
+		}
+
+		for _, action := range pending {
+			applied, err := cas.Apply(action.ID)
+			if err != nil {
+				return report, err
+			}
+			report.Applied = append(report.Applied, *applied)
+		}
+	}
+
+	return report, nil
+}
+
+// Apply aplica un evento pendiente cuya fecha ex ya pasó. Un split ajusta cantidades y precios
+// anteriores a la fecha ex y recalcula earnings; un dividendo registra el cobro de cada holding.
+func (cas *CorporateActionService) Apply(id string) (*CorporateActionResult, error) {
+	var action models.CorporateAction
+	if err := database.DB.First(&action, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("evento corporativo no encontrado: %w", err)
+	}
+
+	if action.AppliedAt != nil {
+		return nil, fmt.Errorf("el evento corporativo ya fue aplicado el %s", action.AppliedAt.Format(time.RFC3339))
+	}
+	if action.ExDate.After(time.Now()) {
+		return nil, fmt.Errorf("el evento corporativo no se puede aplicar antes de su fecha ex")
+	}
+
+	var holdings []models.Holding
+	result := &CorporateActionResult{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Se reclama el evento antes de tocar nada: de dos aplicaciones concurrentes solo una lo marca
+		now := time.Now()
+		claim := tx.Model(&models.CorporateAction{}).
+			Where("id = ? AND \"appliedAt\" IS NULL", action.ID).
+			Update("appliedAt", now)
+		if claim.Error != nil {
+			return fmt.Errorf("error marcando evento como aplicado: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return fmt.Errorf("el evento corporativo ya fue aplicado")
+		}
+		action.AppliedAt = &now
+
+		if err := tx.Where("\"assetId\" = ?", action.AssetID).Find(&holdings).Error; err != nil {
+			return fmt.Errorf("error obteniendo holdings del asset: %w", err)
+		}
+
+		var err error
+		switch action.Type {
+		case models.CorporateActionSplit:
+			result.Split, err = cas.applySplit(tx, &action, holdings)
+		case models.CorporateActionDividend:
+			result.Dividends, err = cas.applyDividend(tx, &action, holdings)
+		default:
+			err = fmt.Errorf("tipo de evento inválido: %s", action.Type)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Action = action
+
+	// Con los snapshots ajustados, los earnings del último período dejan de reflejar el split. Los
+	// analytics cacheados se invalidan solos porque su clave incluye la fecha del último evento aplicado.
+	if action.Type == models.CorporateActionSplit {
+		for _, holding := range holdings {
+			if _, err := cas.earningsService.Recompute(holding.ID, false); err != nil {
+				log.Printf("⚠️ Error recalculando earnings del holding %s tras el split: %v", holding.ID, err)
+			}
+		}
+	}
+
+	log.Printf("✅ Evento corporativo aplicado: %s del asset %s (%s)", action.Type, action.AssetID, action.ExDate.Format("2006-01-02"))
+	return result, nil
+}
+
+// ApplyPendingSplits aplica los splits del asset cuya fecha ex ya pasó y refresca su lastPrice.
+// El cron lo llama antes de scrapear para que el split no se compare como una caída de precio.
+func (cas *CorporateActionService) ApplyPendingSplits(asset *models.Asset) (int, error) {
+	var pending []models.CorporateAction
+	err := database.DB.Where("\"assetId\" = ? AND type = ? AND \"appliedAt\" IS NULL AND \"exDate\" <= ?",
+		asset.ID, models.CorporateActionSplit, time.Now()).
+		Order("\"exDate\" ASC").Find(&pending).Error
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo splits pendientes: %w", err)
+	}
+
+	for _, action := range pending {
+		if _, err := cas.Apply(action.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(pending) > 0 {
+		var refreshed models.Asset
+		if err := database.DB.Select("\"lastPrice\"").First(&refreshed, "id = ?", asset.ID).Error; err != nil {
+			return len(pending), fmt.Errorf("error refrescando lastPrice del asset: %w", err)
+		}
+		asset.LastPrice = refreshed.LastPrice
+	}
+
+	return len(pending), nil
+}
+
+// applySplit ajusta al split todo lo registrado antes de la fecha ex. Los precios se dividen y las
+// cantidades se multiplican por el ratio, así el valor no cambia. Los snapshots desde la fecha ex ya
+// tienen precio posterior al split pero la cantidad anterior, porque el holding aún no estaba ajustado.
+func (cas *CorporateActionService) applySplit(tx *gorm.DB, action *models.CorporateAction, holdings []models.Holding) (*SplitAdjustment, error) {
+	ratio := action.Ratio
+	adjustment := &SplitAdjustment{Holdings: len(holdings)}
+
+	holdingIDs := make([]string, len(holdings))
+	for i, holding := range holdings {
+		holdingIDs[i] = holding.ID
+	}
+
+	if len(holdingIDs) > 0 {
+		result := tx.Exec(`UPDATE "Transaction" SET quantity = quantity * ?, price = price / ?
+			WHERE "holdingId" IN ? AND date < ?`, ratio, ratio, holdingIDs, action.ExDate)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error ajustando transacciones: %w", result.Error)
+		}
+		adjustment.Transactions = int(result.RowsAffected)
+
+		result = tx.Exec(`UPDATE "Snapshot" SET
+				price = CASE WHEN "createdAt" < ? THEN price / ? ELSE price END,
+				quantity = quantity * ?
+			WHERE "holdingId" IN ?`, action.ExDate, ratio, ratio, holdingIDs)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error ajustando snapshots: %w", result.Error)
+		}
+		adjustment.Snapshots = int(result.RowsAffected)
+
+		result = tx.Exec(`UPDATE "SnapshotRollup" SET
+				"closePrice" = CASE WHEN "lastSnapshotAt" < ? THEN "closePrice" / ? ELSE "closePrice" END,
+				"closeQuantity" = "closeQuantity" * ?
+			WHERE "holdingId" IN ?`, action.ExDate, ratio, ratio, holdingIDs)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error ajustando rollups: %w", result.Error)
+		}
+		adjustment.Rollups = int(result.RowsAffected)
+	}
+
+	// El lastPrice es anterior al split si no hay precios registrados desde la fecha ex
+	var pricesSinceExDate int64
+	err := tx.Model(&models.AssetPrice{}).
+		Where("\"assetId\" = ? AND \"createdAt\" >= ?", action.AssetID, action.ExDate).
+		Count(&pricesSinceExDate).Error
+	if err != nil {
+		return nil, fmt.Errorf("error verificando precios del asset: %w", err)
+	}
+	if pricesSinceExDate == 0 {
+		if err := tx.Exec(`UPDATE "Asset" SET "lastPrice" = "lastPrice" / ? WHERE id = ?`, ratio, action.AssetID).Error; err != nil {
+			return nil, fmt.Errorf("error ajustando lastPrice: %w", err)
+		}
+		adjustment.LastPrice = true
+	}
+
+	// Los backfills ejecutados después de la fecha ex ya traen precios ajustados por el proveedor
+	result := tx.Exec(`UPDATE "AssetPrice" SET price = price / ?
+		WHERE "assetId" = ? AND "createdAt" < ?
+		AND NOT (source = ? AND "runId" IN (SELECT id::text FROM "BackfillJob" WHERE "createdAt" >= ?))`,
+		ratio, action.AssetID, action.ExDate, models.AssetPriceSourceBackfill, action.ExDate)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error ajustando historial de precios: %w", result.Error)
+	}
+	adjustment.AssetPrices = int(result.RowsAffected)
+
+	alertQuery := tx.Model(&models.AlertRule{}).
+		Where("type IN ?", []string{models.AlertPriceAbove, models.AlertPriceBelow})
+	if len(holdingIDs) > 0 {
+		alertQuery = alertQuery.Where("\"assetId\" = ? OR \"holdingId\" IN ?", action.AssetID, holdingIDs)
+	} else {
+		alertQuery = alertQuery.Where("\"assetId\" = ?", action.AssetID)
+	}
+	result = alertQuery.Update("threshold", gorm.Expr("threshold / ?", ratio))
+	if result.Error != nil {
+		return nil, fmt.Errorf("error ajustando alertas de precio: %w", result.Error)
+	}
+	adjustment.AlertRules = int(result.RowsAffected)
+
+	// Holdings con transacciones toman la cantidad del historial ajustado; el resto se multiplica
+	for _, holding := range holdings {
+		var transactions []models.Transaction
+		if err := tx.Where("\"holdingId\" = ?", holding.ID).Find(&transactions).Error; err != nil {
+			return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+		}
+
+		quantity := holding.Quantity * ratio
+		if len(transactions) > 0 {
+			position, err := ledger.BuildPosition(transactions)
+			if err != nil {
+				return nil, fmt.Errorf("el historial del holding %s no es válido tras el split: %w", holding.ID, err)
+			}
+			quantity = position.Quantity
+		}
+
+		if err := tx.Model(&models.Holding{}).Where("id = ?", holding.ID).Update("quantity", quantity).Error; err != nil {
+			return nil, fmt.Errorf("error actualizando cantidad del holding: %w", err)
+		}
+	}
+
+	deltas, rebuilt, err := cas.rebuildSnapshotPnL(tx, action, holdings)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.SnapshotPnL = rebuilt
+
+	if adjustment.AggregateRuns, err = applyAggregateDeltas(tx, deltas); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✂️ Split %g aplicado al asset %s: %d holdings, %d transacciones, %d snapshots, %d precios, %d ejecuciones agregadas",
+		ratio, action.AssetID, adjustment.Holdings, adjustment.Transactions, adjustment.Snapshots, adjustment.AssetPrices, adjustment.AggregateRuns)
+
+	return adjustment, nil
+}
+
+// rebuildSnapshotPnL recalcula el resultado de cada snapshot de los holdings con los snapshots y las
+// transacciones ya ajustados, y retorna cuánto cambió el aporte de cada uno a los snapshots agregados.
+// Los snapshots desde la fecha ex valían 1/ratio de su valor actual porque tenían la cantidad anterior.
+func (cas *CorporateActionService) rebuildSnapshotPnL(tx *gorm.DB, action *models.CorporateAction, holdings []models.Holding) ([]pnlDelta, int, error) {
+	var deltas []pnlDelta
+	rebuilt := 0
+
+	for _, holding := range holdings {
+		var snapshots []models.Snapshot
+		if err := tx.Where("\"holdingId\" = ?", holding.ID).Order("\"createdAt\" ASC").Find(&snapshots).Error; err != nil {
+			return nil, 0, fmt.Errorf("error obteniendo snapshots del holding %s: %w", holding.ID, err)
+		}
+
+		var existing []models.SnapshotPnL
+		if err := tx.Where("\"holdingId\" = ?", holding.ID).Find(&existing).Error; err != nil {
+			return nil, 0, fmt.Errorf("error obteniendo resultados del holding %s: %w", holding.ID, err)
+		}
+		previousBySnapshot := make(map[string]models.SnapshotPnL, len(existing))
+		for _, pnl := range existing {
+			previousBySnapshot[pnl.SnapshotID] = pnl
+		}
+
+		for i := range snapshots {
+			snapshot := &snapshots[i]
+			pnl, err := cas.pnlService.BuildSnapshotPnL(tx, snapshot)
+			if err != nil {
+				return nil, 0, fmt.Errorf("error recalculando resultado del snapshot %s: %w", snapshot.ID, err)
+			}
+
+			previous, ok := previousBySnapshot[snapshot.ID]
+			if ok {
+				pnl.ID = previous.ID
+				err = tx.Save(pnl).Error
+			} else {
+				err = tx.Create(pnl).Error
+			}
+			if err != nil {
+				return nil, 0, fmt.Errorf("error guardando resultado del snapshot %s: %w", snapshot.ID, err)
+			}
+			rebuilt++
+
+			delta := pnlDelta{GroupID: holding.GroupID, From: snapshot.CreatedAt, Earnings: pnl.PeriodEarnings}
+			if i+1 < len(snapshots) {
+				delta.Until = &snapshots[i+1].CreatedAt
+			}
+			if !snapshot.CreatedAt.Before(action.ExDate) {
+				delta.Value = pnl.MarketValue * (1 - 1/action.Ratio)
+			}
+			if pnl.HasCostBasis {
+				delta.CostBasis = pnl.CostBasis
+			}
+			if ok {
+				delta.Earnings -= previous.PeriodEarnings
+				if previous.HasCostBasis {
+					delta.CostBasis -= previous.CostBasis
+				}
+			}
+
+			if math.Abs(delta.Value) > 1e-9 || math.Abs(delta.CostBasis) > 1e-9 || math.Abs(delta.Earnings) > 1e-9 {
+				deltas = append(deltas, delta)
+			}
+		}
+	}
+
+	return deltas, rebuilt, nil
+}
+
+// applyAggregateDeltas corrige los GroupSnapshot afectados por los deltas y recalcula los UserSnapshot
+// de esas ejecuciones como la suma de sus grupos. Retorna la cantidad de ejecuciones corregidas.
+func applyAggregateDeltas(tx *gorm.DB, deltas []pnlDelta) (int, error) {
+	if len(deltas) == 0 {
+		return 0, nil
+	}
+
+	groupIDs := make([]string, 0, len(deltas))
+	seen := make(map[string]bool)
+	since := deltas[0].From
+	for _, delta := range deltas {
+		if !seen[delta.GroupID] {
+			seen[delta.GroupID] = true
+			groupIDs = append(groupIDs, delta.GroupID)
+		}
+		if delta.From.Before(since) {
+			since = delta.From
+		}
+
+		span := tx.Model(&models.GroupSnapshot{}).Where("\"groupId\" = ? AND \"createdAt\" >= ?", delta.GroupID, delta.From)
+		if delta.Until != nil {
+			span = span.Where("\"createdAt\" < ?", *delta.Until)
+		}
+
+		if math.Abs(delta.Value) > 1e-9 || math.Abs(delta.CostBasis) > 1e-9 {
+			err := span.Session(&gorm.Session{}).Updates(map[string]interface{}{
+				"totalValue": gorm.Expr("\"totalValue\" + ?", delta.Value),
+				"costBasis":  gorm.Expr("\"costBasis\" + ?", delta.CostBasis),
+			}).Error
+			if err != nil {
+				return 0, fmt.Errorf("error ajustando snapshots del grupo %s: %w", delta.GroupID, err)
+			}
+		}
+
+		if math.Abs(delta.Earnings) > 1e-9 {
+			first := span.Session(&gorm.Session{}).Select("id").Order("\"createdAt\" ASC").Limit(1)
+			err := tx.Model(&models.GroupSnapshot{}).Where("id = (?)", first).
+				Update("earnings", gorm.Expr("earnings + ?", delta.Earnings)).Error
+			if err != nil {
+				return 0, fmt.Errorf("error ajustando earnings del grupo %s: %w", delta.GroupID, err)
+			}
+		}
+	}
+
+	var runIDs []string
+	err := tx.Model(&models.GroupSnapshot{}).Distinct("\"runId\"").
+		Where("\"groupId\" IN ? AND \"createdAt\" >= ?", groupIDs, since).
+		Pluck("runId", &runIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo ejecuciones afectadas: %w", err)
+	}
+	if len(runIDs) == 0 {
+		return 0, nil
+	}
+
+	err = tx.Exec(`UPDATE "UserSnapshot" u SET
+			"totalValue" = g.total_value,
+			"costBasis" = g.cost_basis,
+			earnings = g.earnings
+		FROM (
+			SELECT "runId", "userId", currency,
+				SUM("totalValue") AS total_value, SUM("costBasis") AS cost_basis, SUM(earnings) AS earnings
+			FROM "GroupSnapshot"
+			WHERE "runId" IN ?
+			GROUP BY "runId", "userId", currency
+		) g
+		WHERE u."runId" = g."runId" AND u."userId" = g."userId" AND u.currency = g.currency`, runIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("error recalculando snapshots de usuarios: %w", err)
+	}
+
+	return len(runIDs), nil
+}
+
+// applyDividend registra el dividendo de cada holding según las acciones que tenía al cierre anterior a la fecha ex
+func (cas *CorporateActionService) applyDividend(tx *gorm.DB, action *models.CorporateAction, holdings []models.Holding) ([]models.DividendIncome, error) {
+	incomes := []models.DividendIncome{}
+
+	for _, holding := range holdings {
+		quantity, err := quantityBefore(tx, holding.ID, action.ExDate)
+		if err != nil {
+			return nil, err
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		income := models.DividendIncome{
+			HoldingID:         holding.ID,
+			CorporateActionID: action.ID,
+			ExDate:            action.ExDate,
+			Quantity:          quantity,
+			AmountPerShare:    action.Amount,
+			Amount:            quantity * action.Amount,
+			Currency:          action.Currency,
+			CreatedAt:         time.Now(),
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&income)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error registrando dividendo del holding %s: %w", holding.ID, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			incomes = append(incomes, income)
+		}
+	}
+
+	log.Printf("💵 Dividendo de %g aplicado al asset %s: %d holdings", action.Amount, action.AssetID, len(incomes))
+	return incomes, nil
+}
+
+// quantityBefore obtiene las acciones de un holding antes de una fecha: de sus transacciones si las tiene,
+// o del último snapshot anterior. Sin ninguno de los dos el holding no existía y retorna 0.
+func quantityBefore(tx *gorm.DB, holdingID string, date time.Time) (float64, error) {
+	var transactions []models.Transaction
+	if err := tx.Where("\"holdingId\" = ?", holdingID).Find(&transactions).Error; err != nil {
+		return 0, fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+
+	if len(transactions) > 0 {
+		var before []models.Transaction
+		for _, transaction := range transactions {
+			if transaction.Date.Before(date) {
+				before = append(before, transaction)
+			}
+		}
+		position, err := ledger.BuildPosition(before)
+		if err != nil {
+			return 0, err
+		}
+		return position.Quantity, nil
+	}
+
+	var snapshot models.Snapshot
+	err := tx.Where("\"holdingId\" = ? AND \"createdAt\" < ?", holdingID, date).
+		Order("\"createdAt\" DESC").First(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo snapshot: %w", err)
+	}
+	return snapshot.Quantity, nil
+}
+
+// GetActions obtiene los eventos corporativos, opcionalmente de un asset y por estado (pending o applied)
+func (cas *CorporateActionService) GetActions(assetID, status string, limit int) ([]models.CorporateAction, error) {
+	query := database.DB.Order("\"exDate\" DESC").Limit(limit)
+	if assetID != "" {
+		query = query.Where("\"assetId\" = ?", assetID)
+	}
+
+	switch status {
+	case "":
+	case "pending":
+		query = query.Where("\"appliedAt\" IS NULL")
+	case "applied":
+		query = query.Where("\"appliedAt\" IS NOT NULL")
+	default:
+		return nil, fmt.Errorf("estado inválido: %s (pending o applied)", status)
+	}
+
+	var actions []models.CorporateAction
+	if err := query.Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo eventos corporativos: %w", err)
+	}
+	return actions, nil
+}
+
+// Delete elimina un evento que todavía no se aplicó
+func (cas *CorporateActionService) Delete(id string) error {
+	var action models.CorporateAction
+	if err := database.DB.First(&action, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("evento corporativo no encontrado: %w", err)
+	}
+	if action.AppliedAt != nil {
+		return fmt.Errorf("no se puede eliminar un evento corporativo ya aplicado")
+	}
+	if err := database.DB.Delete(&action).Error; err != nil {
+		return fmt.Errorf("error eliminando evento corporativo: %w", err)
+	}
+	return nil
+}
+
+// GetHoldingDividends obtiene los dividendos cobrados por un holding en un rango de fechas ex
+func (cas *CorporateActionService) GetHoldingDividends(holdingID string, from, to *time.Time) (*HoldingDividends, error) {
+	var holding models.Holding
+	if err := database.DB.Preload("Group.Type").First(&holding, "id = ?", holdingID).Error; err != nil {
+		return nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+
+	query := database.DB.Where("\"holdingId\" = ?", holding.ID).Order("\"exDate\" ASC")
+	if from != nil {
+		query = query.Where("\"exDate\" >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("\"exDate\" <= ?", *to)
+	}
+
+	report := &HoldingDividends{
+		HoldingID: holding.ID,
+		Currency:  holding.Group.Type.Currency,
+		Dividends: []models.DividendIncome{},
+	}
+	if err := query.Find(&report.Dividends).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo dividendos: %w", err)
+	}
+
+	for _, dividend := range report.Dividends {
+		report.Total += dividend.Amount
+	}
+
+	return report, nil
+}
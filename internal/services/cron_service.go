@@ -1,20 +1,36 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
+	"holding-snapshots/internal/config"
 	"holding-snapshots/internal/models"
 	"holding-snapshots/internal/scraping"
+	"holding-snapshots/internal/symbols"
 	"holding-snapshots/pkg/database"
 
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 )
 
 type CronService struct {
-	cron            *cron.Cron
-	scrapingService *ScrapingService
+	cron              *cron.Cron
+	scrapingEntryID   cron.EntryID
+	retentionEntryID  cron.EntryID // Cero si la retención no está habilitada
+	scrapingService   *ScrapingService
+	webhookService    *WebhookService
+	summaryService    *SummaryService
+	alertService      *AlertService
+	pnlService        *PnLService
+	fxService         *FXService
+	assetPriceService *AssetPriceService
+	aggregateService  *AggregateSnapshotService
+	retentionService  *RetentionService
+	corporateActions  *CorporateActionService
 }
 
 // NewCronService crea una nueva instancia del servicio de cron
@@ -23,8 +39,17 @@ func NewCronService() *CronService {
 	c := cron.New(cron.WithLocation(time.UTC))
 
 	return &CronService{
-		cron:            c,
-		scrapingService: NewScrapingService(),
+		cron:              c,
+		scrapingService:   NewScrapingService(),
+		webhookService:    NewWebhookService(),
+		summaryService:    NewSummaryService(),
+		alertService:      NewAlertService(),
+		pnlService:        NewPnLService(),
+		fxService:         NewFXService(),
+		assetPriceService: NewAssetPriceService(),
+		aggregateService:  NewAggregateSnapshotService(),
+		retentionService:  NewRetentionService(),
+		corporateActions:  NewCorporateActionService(),
 	}
 }
 
@@ -34,13 +59,24 @@ func (cs *CronService) Start() error {
 
 	// Programar cronjob para ejecutarse los domingos a las 3:00 AM UTC
 	// Cron expression: "0 3 * * 0" (minuto 0, hora 3, cualquier día del mes, cualquier mes, domingo)
-	_, err := cs.cron.AddFunc("0 3 * * 0", cs.ExecuteWeeklyScraping)
+	scrapingEntryID, err := cs.cron.AddFunc("0 3 * * 0", cs.executeScheduledRun)
 	if err != nil {
 		return fmt.Errorf("error programando cronjob semanal: %w", err)
 	}
+	cs.scrapingEntryID = scrapingEntryID
 
 	log.Println("📅 Cronjob programado para ejecutarse los domingos a las 3:00 AM UTC")
 
+	// Programar la retención de snapshots si está habilitada
+	if config.AppConfig != nil && config.AppConfig.RetentionEnabled {
+		retentionEntryID, err := cs.cron.AddFunc(config.AppConfig.RetentionCronSchedule, cs.executeRetention)
+		if err != nil {
+			return fmt.Errorf("error programando retención de snapshots: %w", err)
+		}
+		cs.retentionEntryID = retentionEntryID
+		log.Printf("📅 Retención de snapshots programada: %s", config.AppConfig.RetentionCronSchedule)
+	}
+
 	// Iniciar el cron
 	cs.cron.Start()
 	log.Println("✅ Servicio de cron iniciado correctamente")
@@ -55,20 +91,57 @@ func (cs *CronService) Stop() {
 	log.Println("✅ Servicio de cron detenido")
 }
 
+// executeScheduledRun ejecuta el scraping semanal y luego envía los resúmenes por email
+func (cs *CronService) executeScheduledRun() {
+	cs.ExecuteWeeklyScraping()
+	cs.summaryService.SendWeeklySummaries()
+}
+
+// executeRetention aplica la política de retención de snapshots
+func (cs *CronService) executeRetention() {
+	if _, err := cs.retentionService.Run(false); err != nil {
+		log.Printf("❌ Error aplicando retención de snapshots: %v", err)
+	}
+}
+
 // ExecuteWeeklyScraping ejecuta el scraping semanal de todos los assets
 func (cs *CronService) ExecuteWeeklyScraping() {
 	log.Println("🚀 Iniciando scraping semanal de assets...")
 	startTime := time.Now()
+	runID := uuid.New().String()
+
+	cs.webhookService.Dispatch(EventRunStarted, map[string]interface{}{
+		"runId":     runID,
+		"startedAt": startTime.UTC(),
+	})
+
+	// Snapshot de tipos de cambio con la misma frecuencia que los precios
+	if _, err := cs.fxService.IngestRates(); err != nil {
+		log.Printf("⚠️ Error obteniendo tipos de cambio: %v", err)
+	}
 
 	// Obtener todos los assets válidos con su tipo de inversión
 	assets, err := cs.getAllValidAssets()
 	if err != nil {
 		log.Printf("❌ Error obteniendo assets: %v", err)
+		cs.webhookService.Dispatch(EventRunFailed, map[string]interface{}{
+			"runId":     runID,
+			"startedAt": startTime.UTC(),
+			"error":     err.Error(),
+		})
 		return
 	}
 
 	if len(assets) == 0 {
 		log.Println("ℹ️ No hay assets válidos para procesar")
+		cs.webhookService.Dispatch(EventRunCompleted, map[string]interface{}{
+			"runId":        runID,
+			"startedAt":    startTime.UTC(),
+			"durationMs":   time.Since(startTime).Milliseconds(),
+			"totalAssets":  0,
+			"successCount": 0,
+			"errorCount":   0,
+		})
 		return
 	}
 
@@ -79,7 +152,7 @@ func (cs *CronService) ExecuteWeeklyScraping() {
 
 	// Procesar cada asset
 	for _, asset := range assets {
-		err := cs.processAsset(&asset)
+		err := cs.processAsset(runID, &asset)
 		if err != nil {
 			log.Printf("❌ Error procesando asset %s (%s): %v", asset.Name, asset.Code, err)
 			errorCount++
@@ -88,14 +161,33 @@ func (cs *CronService) ExecuteWeeklyScraping() {
 			log.Printf("✅ Asset procesado exitosamente: %s (%s) - Precio: %.2f",
 				asset.Name, asset.Code, asset.LastPrice)
 		}
+	}
 
-		// Pausa pequeña entre requests para ser respetuosos
-		time.Sleep(200 * time.Millisecond)
+	// Totales por grupo y usuario una vez actualizados todos los holdings
+	if successCount > 0 {
+		if err := cs.aggregateService.RecordRun(runID, startTime); err != nil {
+			log.Printf("⚠️ Error guardando snapshots de grupos y usuarios: %v", err)
+		}
 	}
 
 	duration := time.Since(startTime)
 	log.Printf("🏁 Scraping semanal completado en %v - Éxitos: %d, Errores: %d",
 		duration, successCount, errorCount)
+
+	// Si ningún asset pudo procesarse se considera que la ejecución falló
+	event := EventRunCompleted
+	if successCount == 0 {
+		event = EventRunFailed
+	}
+
+	cs.webhookService.Dispatch(event, map[string]interface{}{
+		"runId":        runID,
+		"startedAt":    startTime.UTC(),
+		"durationMs":   duration.Milliseconds(),
+		"totalAssets":  len(assets),
+		"successCount": successCount,
+		"errorCount":   errorCount,
+	})
 }
 
 // getAllValidAssets obtiene todos los assets válidos con su tipo de inversión
@@ -161,27 +253,48 @@ func (cs *CronService) loadTypesManually(assets []models.Asset) error {
 }
 
 // processAsset procesa un asset individual: scrapea precio y crea snapshots
-func (cs *CronService) processAsset(asset *models.Asset) error {
+func (cs *CronService) processAsset(runID string, asset *models.Asset) error {
 	log.Printf("🔍 Procesando asset: %s (%s)", asset.Name, asset.Code)
 
+	// Ajustar los splits vigentes antes de comparar el nuevo precio con el anterior
+	if _, err := cs.corporateActions.ApplyPendingSplits(asset); err != nil {
+		log.Printf("⚠️ Error aplicando splits pendientes del asset %s: %v", asset.Code, err)
+	}
+
 	// Scrapear el precio actual del asset
 	price, err := cs.scrapeAssetPrice(asset)
 	if err != nil {
+		if errors.Is(err, scraping.ErrPriceNotFound) {
+			cs.notifyAssetInvalidated(runID, asset, err)
+		}
 		return fmt.Errorf("error scrapeando precio: %w", err)
 	}
 
+	// Detectar variaciones de precio anómalas respecto al último precio conocido
+	cs.checkPriceAnomaly(runID, asset, price)
+	previousPrice := asset.LastPrice
+
 	// Actualizar el lastPrice del asset para optimización futura
 	err = cs.updateAssetLastPrice(asset, price)
 	if err != nil {
 		return fmt.Errorf("error actualizando precio del asset: %w", err)
 	}
 
+	// Guardar el precio en el historial del asset, tenga o no holdings
+	assetPrice, err := cs.assetPriceService.RecordPrice(asset.ID, price, runID)
+	if err != nil {
+		return err
+	}
+
 	// Crear snapshots para todos los holdings de este asset
-	err = cs.createSnapshotsForAsset(asset, price)
+	err = cs.createSnapshotsForAsset(asset, assetPrice)
 	if err != nil {
 		return fmt.Errorf("error creando snapshots: %w", err)
 	}
 
+	// Evaluar las reglas de alerta de los usuarios sobre este asset
+	cs.alertService.EvaluateAsset(asset, price, previousPrice)
+
 	return nil
 }
 
@@ -201,8 +314,9 @@ func (cs *CronService) scrapeAssetPrice(asset *models.Asset) (float64, error) {
 		return 0, fmt.Errorf("error obteniendo estrategia para tipo '%s': %w", asset.Type.Name, err)
 	}
 
-	// Scrapear el precio
-	price, err := strategy.FetchPrice(&asset.Type, asset.Code)
+	// Scrapear el precio respetando la pausa mínima compartida con validaciones, cotizaciones y búsquedas
+	waitScrapingTurn()
+	price, err := strategy.FetchPrice(&asset.Type, cs.scrapingService.symbolMappingService.ProviderSymbol(&asset.Type, asset.Code, symbols.ProviderYahoo))
 	if err != nil {
 		return 0, fmt.Errorf("error fetching price con estrategia '%s': %w", asset.Type.Name, err)
 	}
@@ -213,6 +327,50 @@ func (cs *CronService) scrapeAssetPrice(asset *models.Asset) (float64, error) {
 	return price, nil
 }
 
+// notifyAssetInvalidated avisa por webhook que el proveedor no publicó el precio del asset. El asset
+// no se marca como inválido: el servicio principal decide, y un error transitorio del proveedor
+// no lo saca del scraping de las próximas ejecuciones.
+func (cs *CronService) notifyAssetInvalidated(runID string, asset *models.Asset, cause error) {
+	log.Printf("🚫 El proveedor no publicó precio para el asset %s (%s)", asset.Name, asset.Code)
+
+	cs.webhookService.Dispatch(EventAssetInvalidated, map[string]interface{}{
+		"runId":   runID,
+		"assetId": asset.ID,
+		"code":    asset.Code,
+		"name":    asset.Name,
+		"typeId":  asset.TypeID,
+		"reason":  cause.Error(),
+	})
+}
+
+// checkPriceAnomaly notifica si el precio varió más que WEBHOOK_PRICE_ANOMALY_PCT
+func (cs *CronService) checkPriceAnomaly(runID string, asset *models.Asset, price float64) {
+	threshold := config.AppConfig.WebhookPriceAnomalyPct
+	if threshold <= 0 || asset.LastPrice <= 0 {
+		return
+	}
+
+	changePct := ((price - asset.LastPrice) / asset.LastPrice) * 100
+	if math.Abs(changePct) < threshold {
+		return
+	}
+
+	log.Printf("⚠️ Variación anómala de precio para %s (%s): %.2f -> %.2f (%.2f%%)",
+		asset.Name, asset.Code, asset.LastPrice, price, changePct)
+
+	cs.webhookService.Dispatch(EventPriceAnomaly, map[string]interface{}{
+		"runId":         runID,
+		"assetId":       asset.ID,
+		"code":          asset.Code,
+		"name":          asset.Name,
+		"currency":      asset.Type.Currency,
+		"previousPrice": asset.LastPrice,
+		"currentPrice":  price,
+		"changePct":     changePct,
+		"thresholdPct":  threshold,
+	})
+}
+
 // updateAssetLastPrice actualiza el lastPrice del asset en la base de datos
 func (cs *CronService) updateAssetLastPrice(asset *models.Asset, price float64) error {
 	asset.LastPrice = price
@@ -226,7 +384,9 @@ func (cs *CronService) updateAssetLastPrice(asset *models.Asset, price float64)
 }
 
 // createSnapshotsForAsset crea snapshots para todos los holdings de un asset
-func (cs *CronService) createSnapshotsForAsset(asset *models.Asset, currentPrice float64) error {
+func (cs *CronService) createSnapshotsForAsset(asset *models.Asset, assetPrice *models.AssetPrice) error {
+	currentPrice := assetPrice.Price
+
 	// Obtener todos los holdings de este asset
 	var holdings []models.Holding
 	err := database.DB.Where("\"assetId\" = ?", asset.ID).Find(&holdings).Error
@@ -244,10 +404,11 @@ func (cs *CronService) createSnapshotsForAsset(asset *models.Asset, currentPrice
 	// Crear snapshot para cada holding
 	for _, holding := range holdings {
 		snapshot := models.Snapshot{
-			Price:     currentPrice,
-			HoldingID: holding.ID,
-			Quantity:  holding.Quantity,
-			CreatedAt: time.Now(),
+			Price:        currentPrice,
+			HoldingID:    holding.ID,
+			Quantity:     holding.Quantity,
+			CreatedAt:    time.Now(),
+			AssetPriceID: &assetPrice.ID,
 		}
 
 		err := database.DB.Create(&snapshot).Error
@@ -256,8 +417,15 @@ func (cs *CronService) createSnapshotsForAsset(asset *models.Asset, currentPrice
 			continue
 		}
 
-		// Actualizar earnings del holding
-		err = cs.updateHoldingEarnings(&holding, currentPrice)
+		// Guardar el resultado realizado/no realizado del período
+		pnl, err := cs.pnlService.RecordSnapshotPnL(&snapshot)
+		if err != nil {
+			log.Printf("⚠️ Error registrando P&L para holding %s: %v", holding.ID, err)
+			continue
+		}
+
+		// Actualizar earnings del holding con el resultado del snapshot
+		err = cs.updateHoldingEarnings(&holding, pnl)
 		if err != nil {
 			log.Printf("⚠️ Error actualizando earnings para holding %s: %v", holding.ID, err)
 			continue
@@ -267,32 +435,19 @@ func (cs *CronService) createSnapshotsForAsset(asset *models.Asset, currentPrice
 	return nil
 }
 
-// updateHoldingEarnings actualiza las ganancias del holding basado en el precio actual
-func (cs *CronService) updateHoldingEarnings(holding *models.Holding, currentPrice float64) error {
-	// Obtener el snapshot más reciente anterior a este para calcular earnings
-	var previousSnapshot models.Snapshot
-	err := database.DB.Where("\"holdingId\" = ?", holding.ID).
-		Order("\"createdAt\" DESC").
-		Offset(1). // Saltar el snapshot que acabamos de crear
-		First(&previousSnapshot).Error
-
-	if err != nil {
-		// Si no hay snapshot anterior, no podemos calcular earnings
-		log.Printf("ℹ️ No hay snapshot anterior para holding %s, earnings se mantienen en 0", holding.ID)
-		return nil
-	}
-
-	// Calcular earnings usando el método del modelo
-	holding.CalculateEarnings(currentPrice, previousSnapshot.Price)
+// updateHoldingEarnings guarda en el holding los earnings del resultado de su último snapshot
+func (cs *CronService) updateHoldingEarnings(holding *models.Holding, pnl *models.SnapshotPnL) error {
+	earnings, relativeEarnings := HoldingEarnings(pnl)
 
-	// Guardar el holding actualizado
-	err = database.DB.Save(holding).Error
+	err := database.DB.Model(holding).
+		Select("Earnings", "RelativeEarnings").
+		Updates(&models.Holding{Earnings: earnings, RelativeEarnings: relativeEarnings}).Error
 	if err != nil {
 		return fmt.Errorf("error guardando holding actualizado: %w", err)
 	}
 
 	log.Printf("📈 Earnings actualizados para holding %s: %.2f (%.2f%%)",
-		holding.ID, holding.Earnings, holding.RelativeEarnings)
+		holding.ID, earnings, relativeEarnings)
 
 	return nil
 }
@@ -303,13 +458,22 @@ func (cs *CronService) ExecuteManualScraping() {
 	cs.ExecuteWeeklyScraping()
 }
 
-// GetNextScheduledRun obtiene la próxima ejecución programada
+// GetNextScheduledRun obtiene la próxima ejecución programada del scraping
 func (cs *CronService) GetNextScheduledRun() time.Time {
-	entries := cs.cron.Entries()
-	if len(entries) > 0 {
-		return entries[0].Next
+	return cs.nextRun(cs.scrapingEntryID)
+}
+
+// GetNextRetentionRun obtiene la próxima ejecución de la retención; cero si no está habilitada
+func (cs *CronService) GetNextRetentionRun() time.Time {
+	return cs.nextRun(cs.retentionEntryID)
+}
+
+// nextRun obtiene la próxima ejecución de un job; cero si no está programado
+func (cs *CronService) nextRun(id cron.EntryID) time.Time {
+	if id == 0 {
+		return time.Time{}
 	}
-	return time.Time{}
+	return cs.cron.Entry(id).Next
 }
 
 // GetCronStatus obtiene el estado del servicio de cron
@@ -317,13 +481,18 @@ func (cs *CronService) GetCronStatus() map[string]interface{} {
 	entries := cs.cron.Entries()
 
 	status := map[string]interface{}{
-		"running":        len(entries) > 0,
-		"total_jobs":     len(entries),
-		"next_execution": nil,
+		"running":                  len(entries) > 0,
+		"total_jobs":               len(entries),
+		"next_execution":           nil,
+		"retention_enabled":        cs.retentionEntryID != 0,
+		"next_retention_execution": nil,
 	}
 
-	if len(entries) > 0 {
-		status["next_execution"] = entries[0].Next.Format("2006-01-02 15:04:05 UTC")
+	if next := cs.GetNextScheduledRun(); !next.IsZero() {
+		status["next_execution"] = next.Format("2006-01-02 15:04:05 UTC")
+	}
+	if next := cs.GetNextRetentionRun(); !next.IsZero() {
+		status["next_retention_execution"] = next.Format("2006-01-02 15:04:05 UTC")
 	}
 
 	return status
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// earningsTolerance es la diferencia mínima para considerar que los earnings cambiaron
+const earningsTolerance = 1e-9
+
+// SnapshotPnLDiff es el resultado de un snapshot que cambia al reproducir el historial
+type SnapshotPnLDiff struct {
+	SnapshotID string              `json:"snapshotId"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	Previous   *models.SnapshotPnL `json:"previous"` // Nil si el snapshot no tenía resultado guardado
+	PnL        *models.SnapshotPnL `json:"pnl"`
+}
+
+// EarningsDiff es el resultado del recálculo de un holding
+type EarningsDiff struct {
+	HoldingID                string            `json:"holdingId"`
+	Snapshots                int               `json:"snapshots"`
+	PreviousEarnings         float64           `json:"previousEarnings"`
+	PreviousRelativeEarnings float64           `json:"previousRelativeEarnings"`
+	Earnings                 float64           `json:"earnings"`
+	RelativeEarnings         float64           `json:"relativeEarnings"`
+	SnapshotPnL              []SnapshotPnLDiff `json:"snapshotPnL"` // Solo los snapshots que cambian
+}
+
+// EarningsRecomputeReport resume un recálculo de earnings
+type EarningsRecomputeReport struct {
+	DryRun             bool           `json:"dryRun"`
+	Holdings           int            `json:"holdings"`           // Holdings recorridos
+	Changed            int            `json:"changed"`            // Holdings con earnings o resultados distintos de los guardados
+	SnapshotPnLChanged int            `json:"snapshotPnLChanged"` // Resultados de snapshots que cambian
+	Diffs              []EarningsDiff `json:"diffs"`              // Solo los holdings que cambian
+	StartedAt          time.Time      `json:"startedAt"`
+	FinishedAt         time.Time      `json:"finishedAt"`
+}
+
+// snapshotReplay es el resultado reconstruido de un snapshot junto al que estaba guardado
+type snapshotReplay struct {
+	Snapshot models.Snapshot
+	Stored   *models.SnapshotPnL // Nil si el snapshot no tenía resultado guardado
+	Rebuilt  *models.SnapshotPnL
+}
+
+type EarningsService struct {
+	pnlService *PnLService
+}
+
+// NewEarningsService crea una nueva instancia del servicio de recálculo de earnings
+func NewEarningsService() *EarningsService {
+	return &EarningsService{
+		pnlService: NewPnLService(),
+	}
+}
+
+// Recompute recalcula los earnings de un holding (o de todos si holdingID está vacío) reproduciendo
+// su historial completo de snapshots: reconstruye el SnapshotPnL de cada snapshot y, con el del último,
+// Holding.Earnings y RelativeEarnings. Con dryRun solo reporta las diferencias sin guardarlas.
+func (es *EarningsService) Recompute(holdingID string, dryRun bool) (*EarningsRecomputeReport, error) {
+	report := &EarningsRecomputeReport{
+		DryRun:    dryRun,
+		Diffs:     []EarningsDiff{},
+		StartedAt: time.Now(),
+	}
+
+	query := database.DB.Order("id")
+	if holdingID != "" {
+		var holding models.Holding
+		if err := database.DB.First(&holding, "id = ?", holdingID).Error; err != nil {
+			return nil, fmt.Errorf("holding no encontrado: %w", err)
+		}
+		query = query.Where("id = ?", holding.ID)
+	}
+
+	var holdings []models.Holding
+	if err := query.Find(&holdings).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo holdings: %w", err)
+	}
+
+	for i := range holdings {
+		diff, err := es.replayHolding(&holdings[i])
+		if err != nil {
+			return nil, err
+		}
+
+		report.Holdings++
+		if diff != nil {
+			report.Diffs = append(report.Diffs, *diff)
+			report.SnapshotPnLChanged += len(diff.SnapshotPnL)
+		}
+	}
+	report.Changed = len(report.Diffs)
+
+	if !dryRun && report.Changed > 0 {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			for _, diff := range report.Diffs {
+				err := tx.Model(&models.Holding{ID: diff.HoldingID}).
+					Select("Earnings", "RelativeEarnings").
+					Updates(&models.Holding{Earnings: diff.Earnings, RelativeEarnings: diff.RelativeEarnings}).Error
+				if err != nil {
+					return fmt.Errorf("error guardando earnings del holding %s: %w", diff.HoldingID, err)
+				}
+
+				for _, snapshotDiff := range diff.SnapshotPnL {
+					if err := saveSnapshotPnL(tx, snapshotDiff.Previous, snapshotDiff.PnL); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	if dryRun {
+		log.Printf("🔎 Recálculo de earnings (dry-run): %d holdings, %d con diferencias, %d resultados de snapshots",
+			report.Holdings, report.Changed, report.SnapshotPnLChanged)
+	} else {
+		log.Printf("📈 Recálculo de earnings: %d holdings, %d actualizados, %d resultados de snapshots",
+			report.Holdings, report.Changed, report.SnapshotPnLChanged)
+	}
+
+	return report, nil
+}
+
+// replayHolding recorre los snapshots del holding del más antiguo al más reciente con el mismo cálculo
+// que el cron. Retorna nil si los resultados y los earnings coinciden con los guardados.
+func (es *EarningsService) replayHolding(holding *models.Holding) (*EarningsDiff, error) {
+	replays, err := es.replaySnapshots(database.DB, holding.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &EarningsDiff{
+		HoldingID:                holding.ID,
+		Snapshots:                len(replays),
+		PreviousEarnings:         holding.Earnings,
+		PreviousRelativeEarnings: holding.RelativeEarnings,
+		SnapshotPnL:              []SnapshotPnLDiff{},
+	}
+
+	for _, replay := range replays {
+		if replay.Stored != nil && sameSnapshotPnL(replay.Stored, replay.Rebuilt) {
+			continue
+		}
+		diff.SnapshotPnL = append(diff.SnapshotPnL, SnapshotPnLDiff{
+			SnapshotID: replay.Snapshot.ID,
+			CreatedAt:  replay.Snapshot.CreatedAt,
+			Previous:   replay.Stored,
+			PnL:        replay.Rebuilt,
+		})
+	}
+
+	// Sin snapshots el cron nunca calculó earnings
+	if len(replays) > 0 {
+		diff.Earnings, diff.RelativeEarnings = HoldingEarnings(replays[len(replays)-1].Rebuilt)
+	}
+
+	if len(diff.SnapshotPnL) == 0 &&
+		math.Abs(diff.Earnings-holding.Earnings) < earningsTolerance &&
+		math.Abs(diff.RelativeEarnings-holding.RelativeEarnings) < earningsTolerance {
+		return nil, nil
+	}
+
+	return diff, nil
+}
+
+// replaySnapshots reconstruye el resultado de cada snapshot del holding, del más antiguo al más
+// reciente, con el snapshot anterior y las transacciones hasta su fecha
+func (es *EarningsService) replaySnapshots(db *gorm.DB, holdingID string) ([]snapshotReplay, error) {
+	var snapshots []models.Snapshot
+	err := db.Where("\"holdingId\" = ?", holdingID).
+		Order("\"createdAt\" ASC, id ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots del holding %s: %w", holdingID, err)
+	}
+
+	var transactions []models.Transaction
+	if err := db.Where("\"holdingId\" = ?", holdingID).Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo transacciones del holding %s: %w", holdingID, err)
+	}
+
+	var stored []models.SnapshotPnL
+	if err := db.Where("\"holdingId\" = ?", holdingID).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo resultados del holding %s: %w", holdingID, err)
+	}
+	storedBySnapshot := make(map[string]*models.SnapshotPnL, len(stored))
+	for i := range stored {
+		storedBySnapshot[stored[i].SnapshotID] = &stored[i]
+	}
+
+	replays := make([]snapshotReplay, 0, len(snapshots))
+	var previous *models.Snapshot
+	for i := range snapshots {
+		// Se usa la cantidad registrada en el snapshot, no la actual del holding
+		rebuilt, err := es.pnlService.computeSnapshotPnL(&snapshots[i], previous, transactions)
+		if err != nil {
+			return nil, fmt.Errorf("error calculando resultado del snapshot %s: %w", snapshots[i].ID, err)
+		}
+
+		replays = append(replays, snapshotReplay{
+			Snapshot: snapshots[i],
+			Stored:   storedBySnapshot[snapshots[i].ID],
+			Rebuilt:  rebuilt,
+		})
+		previous = &snapshots[i]
+	}
+
+	return replays, nil
+}
+
+// sameSnapshotPnL indica si un resultado reconstruido coincide con el guardado
+func sameSnapshotPnL(stored, rebuilt *models.SnapshotPnL) bool {
+	values := [][2]float64{
+		{stored.Price, rebuilt.Price},
+		{stored.Quantity, rebuilt.Quantity},
+		{stored.MarketValue, rebuilt.MarketValue},
+		{stored.CostBasis, rebuilt.CostBasis},
+		{stored.RealizedPnL, rebuilt.RealizedPnL},
+		{stored.UnrealizedPnL, rebuilt.UnrealizedPnL},
+		{stored.PeriodEarnings, rebuilt.PeriodEarnings},
+		{stored.PeriodRelativeEarnings, rebuilt.PeriodRelativeEarnings},
+	}
+	for _, pair := range values {
+		if math.Abs(pair[0]-pair[1]) >= earningsTolerance {
+			return false
+		}
+	}
+	return stored.HasCostBasis == rebuilt.HasCostBasis && stored.Method == rebuilt.Method
+}
+
+// saveSnapshotPnL reemplaza el resultado guardado de un snapshot, o lo crea si no tenía
+func saveSnapshotPnL(tx *gorm.DB, stored, rebuilt *models.SnapshotPnL) error {
+	if stored != nil {
+		rebuilt.ID = stored.ID
+		if err := tx.Save(rebuilt).Error; err != nil {
+			return fmt.Errorf("error actualizando resultado del snapshot %s: %w", rebuilt.SnapshotID, err)
+		}
+		return nil
+	}
+
+	if err := tx.Create(rebuilt).Error; err != nil {
+		return fmt.Errorf("error creando resultado del snapshot %s: %w", rebuilt.SnapshotID, err)
+	}
+	return nil
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"holding-snapshots/internal/export"
+	"holding-snapshots/internal/fx"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+)
+
+// Alcances y conjuntos de datos exportables
+const (
+	ExportScopeHolding = "holding"
+	ExportScopeGroup   = "group"
+	ExportScopeUser    = "user"
+
+	ExportDatasetSnapshots    = "snapshots"
+	ExportDatasetTransactions = "transactions"
+)
+
+// ExportRequest son los parámetros de una exportación
+type ExportRequest struct {
+	Scope    string // holding, group o user
+	OwnerID  string
+	Dataset  string // snapshots o transactions
+	Format   string // csv, xlsx o jsonl
+	Columns  []string
+	From     *time.Time
+	To       *time.Time
+	Currency *CurrencyOptions
+}
+
+// Export es una exportación validada, lista para escribirse
+type Export struct {
+	Filename    string
+	ContentType string
+
+	request   ExportRequest
+	columns   []exportColumn
+	holdings  map[string]exportHolding
+	converter *fx.Converter
+}
+
+// exportHolding son los datos descriptivos de un holding que se repiten en cada fila
+type exportHolding struct {
+	GroupID   string
+	GroupName string
+	Code      string
+	Name      string
+	Currency  string
+}
+
+// exportRow es una fila a exportar, común a snapshots y transacciones
+type exportRow struct {
+	Date     time.Time
+	Holding  string
+	Meta     exportHolding
+	Currency string  // Moneda de los importes de la fila
+	FXRate   float64 // Cotización aplicada; 0 si no se pudo convertir
+
+	// Snapshots
+	Price         float64
+	Quantity      float64
+	Value         float64
+	Earnings      *float64
+	CostBasis     *float64
+	RealizedPnL   *float64
+	UnrealizedPnL *float64
+
+	// Transacciones
+	Type  string
+	Fees  float64
+	Notes string
+}
+
+// exportColumn es una columna exportable con la forma de obtener su valor
+type exportColumn struct {
+	Name   string
+	Amount bool // Importe que se convierte a la moneda base
+	Value  func(row *exportRow) interface{}
+}
+
+// Columnas comunes a ambos conjuntos de datos
+var exportCommonColumns = []exportColumn{
+	{Name: "date", Value: func(r *exportRow) interface{} { return r.Date }},
+	{Name: "holdingId", Value: func(r *exportRow) interface{} { return r.Holding }},
+	{Name: "groupId", Value: func(r *exportRow) interface{} { return r.Meta.GroupID }},
+	{Name: "group", Value: func(r *exportRow) interface{} { return r.Meta.GroupName }},
+	{Name: "asset", Value: func(r *exportRow) interface{} { return r.Meta.Code }},
+	{Name: "assetName", Value: func(r *exportRow) interface{} { return r.Meta.Name }},
+	{Name: "currency", Value: func(r *exportRow) interface{} { return r.Currency }},
+}
+
+var exportSnapshotColumns = append(append([]exportColumn{}, exportCommonColumns...),
+	exportColumn{Name: "price", Amount: true, Value: func(r *exportRow) interface{} { return r.Price }},
+	exportColumn{Name: "quantity", Value: func(r *exportRow) interface{} { return r.Quantity }},
+	exportColumn{Name: "value", Amount: true, Value: func(r *exportRow) interface{} { return r.Value }},
+	exportColumn{Name: "periodEarnings", Amount: true, Value: func(r *exportRow) interface{} { return optionalAmount(r.Earnings) }},
+	exportColumn{Name: "costBasis", Amount: true, Value: func(r *exportRow) interface{} { return optionalAmount(r.CostBasis) }},
+	exportColumn{Name: "unrealizedPnL", Amount: true, Value: func(r *exportRow) interface{} { return optionalAmount(r.UnrealizedPnL) }},
+	exportColumn{Name: "realizedPnL", Amount: true, Value: func(r *exportRow) interface{} { return optionalAmount(r.RealizedPnL) }},
+	exportColumn{Name: "fxRate", Value: fxRateValue},
+)
+
+var exportTransactionColumns = append(append([]exportColumn{}, exportCommonColumns...),
+	exportColumn{Name: "type", Value: func(r *exportRow) interface{} { return r.Type }},
+	exportColumn{Name: "quantity", Value: func(r *exportRow) interface{} { return r.Quantity }},
+	exportColumn{Name: "price", Amount: true, Value: func(r *exportRow) interface{} { return r.Price }},
+	exportColumn{Name: "amount", Amount: true, Value: func(r *exportRow) interface{} { return r.Value }},
+	exportColumn{Name: "fees", Amount: true, Value: func(r *exportRow) interface{} { return r.Fees }},
+	exportColumn{Name: "notes", Value: func(r *exportRow) interface{} { return r.Notes }},
+	exportColumn{Name: "fxRate", Value: fxRateValue},
+)
+
+// Columnas por defecto (fxRate solo se agrega si se pidió conversión)
+var exportDefaultColumns = map[string][]string{
+	ExportDatasetSnapshots:    {"date", "group", "asset", "currency", "price", "quantity", "value", "periodEarnings", "costBasis", "unrealizedPnL", "realizedPnL"},
+	ExportDatasetTransactions: {"date", "group", "asset", "currency", "type", "quantity", "price", "amount", "fees", "notes"},
+}
+
+type ExportService struct {
+	fxService *FXService
+}
+
+// NewExportService crea una nueva instancia del servicio de exportación
+func NewExportService() *ExportService {
+	return &ExportService{
+		fxService: NewFXService(),
+	}
+}
+
+// Prepare valida la exportación y resuelve los holdings del alcance. La lectura de filas
+// se hace recién al escribir, para poder enviarlas en streaming.
+func (es *ExportService) Prepare(req ExportRequest) (*Export, error) {
+	if !export.IsValidFormat(req.Format) {
+		return nil, fmt.Errorf("formato inválido: %s (csv, xlsx o jsonl)", req.Format)
+	}
+
+	var available []exportColumn
+	switch req.Dataset {
+	case ExportDatasetSnapshots:
+		available = exportSnapshotColumns
+	case ExportDatasetTransactions:
+		available = exportTransactionColumns
+	default:
+		return nil, fmt.Errorf("conjunto de datos inválido: %s (snapshots o transactions)", req.Dataset)
+	}
+
+	names := req.Columns
+	if len(names) == 0 {
+		names = exportDefaultColumns[req.Dataset]
+		if req.Currency != nil {
+			names = append(names, "fxRate")
+		}
+	}
+
+	columns, err := selectExportColumns(available, names)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings, err := es.resolveHoldings(req.Scope, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Export{
+		Filename:    fmt.Sprintf("%s-%s-%s-%s.%s", req.Scope, req.OwnerID, req.Dataset, time.Now().Format("20060102"), req.Format),
+		ContentType: export.ContentType(req.Format),
+		request:     req,
+		columns:     columns,
+		holdings:    holdings,
+	}
+
+	if req.Currency != nil {
+		currencies := []string{req.Currency.BaseCurrency}
+		for _, holding := range holdings {
+			currencies = append(currencies, holding.Currency)
+		}
+
+		result.converter, err = es.fxService.NewConverter(req.Currency.Variant, currencies, req.From, req.To)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Write escribe la exportación completa leyendo las filas de a una desde la base de datos
+func (e *Export) Write(w io.Writer) error {
+	names := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		names[i] = column.Name
+	}
+
+	writer, err := export.NewWriter(e.request.Format, w, names)
+	if err != nil {
+		return err
+	}
+
+	writeRow := func(row *exportRow) error {
+		e.convert(row)
+
+		values := make([]interface{}, len(e.columns))
+		for i, column := range e.columns {
+			values[i] = column.Value(row)
+			if column.Amount && e.request.Currency != nil && row.FXRate == 0 {
+				values[i] = nil // Sin cotización no se exporta un importe en otra moneda
+			}
+		}
+		return writer.WriteRow(values)
+	}
+
+	if len(e.holdings) > 0 {
+		if e.request.Dataset == ExportDatasetTransactions {
+			err = e.streamTransactions(writeRow)
+		} else {
+			err = e.streamSnapshots(writeRow)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// streamSnapshots recorre los snapshots del alcance en orden cronológico con su resultado
+func (e *Export) streamSnapshots(writeRow func(*exportRow) error) error {
+	query := `
+		SELECT s."holdingId", s."createdAt", s.price, s.quantity,
+			p."periodEarnings", p."hasCostBasis", p."costBasis", p."realizedPnL", p."unrealizedPnL"
+		FROM "Snapshot" s
+		LEFT JOIN "SnapshotPnL" p ON p."snapshotId" = s.id
+		WHERE s."holdingId" IN ?`
+	args := []interface{}{e.holdingIDs()}
+	if e.request.From != nil {
+		query += ` AND s."createdAt" >= ?`
+		args = append(args, *e.request.From)
+	}
+	if e.request.To != nil {
+		query += ` AND s."createdAt" <= ?`
+		args = append(args, *e.request.To)
+	}
+	query += ` ORDER BY s."createdAt", s.id`
+
+	rows, err := database.DB.Raw(query, args...).Rows()
+	if err != nil {
+		return fmt.Errorf("error obteniendo snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row exportRow
+		var earnings, costBasis, realized, unrealized sql.NullFloat64
+		var hasCostBasis sql.NullBool
+
+		err := rows.Scan(&row.Holding, &row.Date, &row.Price, &row.Quantity,
+			&earnings, &hasCostBasis, &costBasis, &realized, &unrealized)
+		if err != nil {
+			return fmt.Errorf("error leyendo snapshot: %w", err)
+		}
+
+		row.Meta = e.holdings[row.Holding]
+		row.Currency = row.Meta.Currency
+		row.Value = row.Price * row.Quantity
+		row.Earnings = nullableFloat(earnings)
+		if hasCostBasis.Valid && hasCostBasis.Bool {
+			row.CostBasis = nullableFloat(costBasis)
+			row.RealizedPnL = nullableFloat(realized)
+			row.UnrealizedPnL = nullableFloat(unrealized)
+		}
+
+		if err := writeRow(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// streamTransactions recorre las transacciones del alcance en orden cronológico
+func (e *Export) streamTransactions(writeRow func(*exportRow) error) error {
+	query := database.DB.Model(&models.Transaction{}).Where("\"holdingId\" IN ?", e.holdingIDs())
+	if e.request.From != nil {
+		query = query.Where("date >= ?", *e.request.From)
+	}
+	if e.request.To != nil {
+		query = query.Where("date <= ?", *e.request.To)
+	}
+
+	rows, err := query.Order("date, \"createdAt\"").Rows()
+	if err != nil {
+		return fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := database.DB.ScanRows(rows, &transaction); err != nil {
+			return fmt.Errorf("error leyendo transacción: %w", err)
+		}
+
+		row := exportRow{
+			Date:     transaction.Date,
+			Holding:  transaction.HoldingID,
+			Meta:     e.holdings[transaction.HoldingID],
+			Currency: transaction.Currency,
+			Type:     transaction.Type,
+			Quantity: transaction.Quantity,
+			Price:    transaction.Price,
+			Value:    transaction.Price * transaction.Quantity,
+			Fees:     transaction.Fees,
+			Notes:    transaction.Notes,
+		}
+
+		if err := writeRow(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// convert expresa los importes de la fila en la moneda base con la cotización de su fecha
+func (e *Export) convert(row *exportRow) {
+	if e.request.Currency == nil {
+		return
+	}
+
+	rate, err := e.converter.RateAt(row.Currency, e.request.Currency.BaseCurrency, row.Date)
+	row.Currency = e.request.Currency.BaseCurrency
+	if err != nil {
+		row.FXRate = 0
+		return
+	}
+
+	row.FXRate = rate
+	row.Price *= rate
+	row.Value *= rate
+	row.Fees *= rate
+	for _, amount := range []*float64{row.Earnings, row.CostBasis, row.RealizedPnL, row.UnrealizedPnL} {
+		if amount != nil {
+			*amount *= rate
+		}
+	}
+}
+
+// holdingIDs retorna los IDs de los holdings del alcance
+func (e *Export) holdingIDs() []string {
+	ids := make([]string, 0, len(e.holdings))
+	for id := range e.holdings {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// resolveHoldings obtiene los holdings de un holding, grupo o usuario con sus datos descriptivos
+func (es *ExportService) resolveHoldings(scope, ownerID string) (map[string]exportHolding, error) {
+	query := database.DB.Preload("Asset").Preload("Group.Type")
+
+	switch scope {
+	case ExportScopeHolding:
+		var holding models.Holding
+		if err := database.DB.First(&holding, "id = ?", ownerID).Error; err != nil {
+			return nil, fmt.Errorf("holding no encontrado: %w", err)
+		}
+		query = query.Where("id = ?", holding.ID)
+	case ExportScopeGroup:
+		var group models.Group
+		if err := database.DB.First(&group, "id = ?", ownerID).Error; err != nil {
+			return nil, fmt.Errorf("grupo no encontrado: %w", err)
+		}
+		query = query.Where("\"groupId\" = ?", group.ID)
+	case ExportScopeUser:
+		var user models.User
+		if err := database.DB.First(&user, "id = ?", ownerID).Error; err != nil {
+			return nil, fmt.Errorf("usuario no encontrado: %w", err)
+		}
+		userGroups := database.DB.Model(&models.Group{}).Select("id").Where(&models.Group{UserID: user.ID})
+		query = query.Where("\"groupId\" IN (?)", userGroups)
+	default:
+		return nil, fmt.Errorf("alcance de exportación inválido: %s", scope)
+	}
+
+	var holdings []models.Holding
+	if err := query.Find(&holdings).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo holdings: %w", err)
+	}
+
+	result := make(map[string]exportHolding, len(holdings))
+	for _, holding := range holdings {
+		result[holding.ID] = exportHolding{
+			GroupID:   holding.GroupID,
+			GroupName: holding.Group.Name,
+			Code:      holding.Asset.Code,
+			Name:      holding.Asset.Name,
+			Currency:  holding.Group.Type.Currency,
+		}
+	}
+
+	if len(result) == 0 {
+		log.Printf("ℹ️ Exportación de %s %s sin holdings", scope, ownerID)
+	}
+	return result, nil
+}
+
+// selectExportColumns valida y ordena las columnas pedidas
+func selectExportColumns(available []exportColumn, names []string) ([]exportColumn, error) {
+	byName := make(map[string]exportColumn, len(available))
+	valid := make([]string, 0, len(available))
+	for _, column := range available {
+		byName[column.Name] = column
+		valid = append(valid, column.Name)
+	}
+
+	columns := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		column, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("columna inválida: %s (disponibles: %s)", name, strings.Join(valid, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// optionalAmount retorna el importe o nil si no está disponible
+func optionalAmount(amount *float64) interface{} {
+	if amount == nil {
+		return nil
+	}
+	return *amount
+}
+
+// fxRateValue retorna la cotización aplicada o nil si no hubo conversión
+func fxRateValue(row *exportRow) interface{} {
+	if row.FXRate == 0 {
+		return nil
+	}
+	return row.FXRate
+}
+
+// nullableFloat convierte un NullFloat64 en puntero
+func nullableFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
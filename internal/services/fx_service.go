@@ -0,0 +1,308 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/fx"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+)
+
+// Variantes de tipo de cambio aceptadas en los parámetros de la API
+var validFXVariants = map[string]bool{
+	models.FXVariantOfficial:  true,
+	models.FXVariantMEP:       true,
+	models.FXVariantCCL:       true,
+	models.FXVariantBlue:      true,
+	models.FXVariantWholesale: true,
+}
+
+// CurrencyOptions indica a qué moneda convertir un agregado y con qué cotización
+type CurrencyOptions struct {
+	BaseCurrency string
+	Variant      string
+}
+
+type FXService struct {
+	provider fx.RateProvider
+}
+
+// NewFXService crea una nueva instancia del servicio de tipos de cambio
+func NewFXService() *FXService {
+	url := "https://dolarapi.com/v1/dolares"
+	if config.AppConfig != nil && config.AppConfig.FXProviderURL != "" {
+		url = config.AppConfig.FXProviderURL
+	}
+
+	return &FXService{
+		provider: fx.NewDolarAPIProvider(url),
+	}
+}
+
+// ParseCurrencyOptions valida los parámetros currency/fxVariant; retorna nil si no se pidió conversión
+func ParseCurrencyOptions(currency, variant string) (*CurrencyOptions, error) {
+	if currency == "" {
+		return nil, nil
+	}
+
+	if variant == "" {
+		variant = models.FXVariantMEP
+		if config.AppConfig != nil && config.AppConfig.FXDefaultVariant != "" {
+			variant = config.AppConfig.FXDefaultVariant
+		}
+	}
+
+	if !validFXVariants[variant] {
+		return nil, fmt.Errorf("variante de tipo de cambio inválida: %s", variant)
+	}
+
+	return &CurrencyOptions{
+		BaseCurrency: strings.ToUpper(currency),
+		Variant:      variant,
+	}, nil
+}
+
+// IngestRates obtiene las cotizaciones del proveedor y las guarda como snapshot de tipos de cambio
+func (fs *FXService) IngestRates() ([]models.FXRate, error) {
+	if config.AppConfig != nil && !config.AppConfig.FXEnabled {
+		return nil, nil
+	}
+
+	log.Printf("💱 Obteniendo tipos de cambio desde %s...", fs.provider.Name())
+
+	rates, err := fs.provider.FetchRates()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo tipos de cambio: %w", err)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("el proveedor %s no devolvió cotizaciones", fs.provider.Name())
+	}
+
+	if err := database.DB.Create(&rates).Error; err != nil {
+		return nil, fmt.Errorf("error guardando tipos de cambio: %w", err)
+	}
+
+	for _, rate := range rates {
+		log.Printf("💱 %s/%s (%s): %.4f", rate.Base, rate.Quote, rate.Variant, rate.Rate)
+	}
+
+	return rates, nil
+}
+
+// CreateRate registra manualmente una cotización
+func (fs *FXService) CreateRate(rate *models.FXRate) error {
+	rate.Base = strings.ToUpper(rate.Base)
+	rate.Quote = strings.ToUpper(rate.Quote)
+
+	if rate.Base == "" || rate.Quote == "" || rate.Base == rate.Quote {
+		return fmt.Errorf("par de monedas inválido: %s/%s", rate.Base, rate.Quote)
+	}
+
+	if !validFXVariants[rate.Variant] {
+		return fmt.Errorf("variante de tipo de cambio inválida: %s", rate.Variant)
+	}
+
+	if rate.Rate <= 0 {
+		return fmt.Errorf("la cotización debe ser mayor a 0")
+	}
+
+	if rate.CreatedAt.IsZero() {
+		rate.CreatedAt = time.Now()
+	}
+	rate.Source = "manual"
+
+	if err := database.DB.Create(rate).Error; err != nil {
+		return fmt.Errorf("error guardando cotización: %w", err)
+	}
+
+	return nil
+}
+
+// GetRates lista cotizaciones filtradas por par, variante y fechas
+func (fs *FXService) GetRates(base, quote, variant string, from, to *time.Time) ([]models.FXRate, error) {
+	query := database.DB.Order("\"createdAt\" ASC")
+	if base != "" {
+		query = query.Where("base = ?", strings.ToUpper(base))
+	}
+	if quote != "" {
+		query = query.Where("quote = ?", strings.ToUpper(quote))
+	}
+	if variant != "" {
+		query = query.Where("variant = ?", variant)
+	}
+	if from != nil {
+		query = query.Where("\"createdAt\" >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("\"createdAt\" <= ?", *to)
+	}
+
+	var rates []models.FXRate
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo cotizaciones: %w", err)
+	}
+	return rates, nil
+}
+
+// NewConverter carga las cotizaciones de una variante entre las monedas indicadas y crea un conversor
+// en memoria. Con un rango de fechas carga las del rango y la última vigente al inicio de cada par.
+func (fs *FXService) NewConverter(variant string, currencies []string, from, to *time.Time) (*fx.Converter, error) {
+	codes := make([]string, 0, len(currencies))
+	seen := make(map[string]bool)
+	for _, currency := range currencies {
+		currency = strings.ToUpper(currency)
+		if currency != "" && !seen[currency] {
+			seen[currency] = true
+			codes = append(codes, currency)
+		}
+	}
+	if len(codes) < 2 {
+		return fx.NewConverter(variant, nil), nil
+	}
+
+	query := database.DB.Where("variant = ? AND base IN ? AND quote IN ?", variant, codes, codes)
+	if to != nil {
+		query = query.Where("\"createdAt\" <= ?", *to)
+	}
+
+	var rates []models.FXRate
+	if from == nil {
+		if err := query.Find(&rates).Error; err != nil {
+			return nil, fmt.Errorf("error obteniendo cotizaciones: %w", err)
+		}
+		return fx.NewConverter(variant, rates), nil
+	}
+
+	if err := query.Where("\"createdAt\" >= ?", *from).Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo cotizaciones: %w", err)
+	}
+
+	// Cotización vigente al inicio del rango, una por par
+	var opening []models.FXRate
+	err := database.DB.Raw(`SELECT DISTINCT ON (base, quote) * FROM "FXRate"
+		WHERE variant = ? AND base IN ? AND quote IN ? AND "createdAt" < ?
+		ORDER BY base, quote, "createdAt" DESC`, variant, codes, codes, *from).
+		Scan(&opening).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo cotizaciones vigentes: %w", err)
+	}
+
+	return fx.NewConverter(variant, append(opening, rates...)), nil
+}
+
+// ConvertValueSeries convierte una serie de valor a la moneda base usando la cotización de cada período
+func (fs *FXService) ConvertValueSeries(series ValueSeries, converter *fx.Converter, base string) (ValueSeries, error) {
+	converted := ValueSeries{Currency: base, Points: make([]ValuePoint, len(series.Points))}
+
+	for i, point := range series.Points {
+		rate, err := converter.RateAt(series.Currency, base, point.AsOf)
+		if err != nil {
+			return ValueSeries{}, err
+		}
+
+		point.TotalValue *= rate
+		converted.Points[i] = point
+	}
+
+	computeValueEarnings(converted.Points)
+	return converted, nil
+}
+
+// MergeValueSeries suma varias series ya expresadas en la misma moneda, período a período
+func (fs *FXService) MergeValueSeries(series []ValueSeries, base string) ValueSeries {
+	byPeriod := make(map[time.Time]*ValuePoint)
+
+	for _, s := range series {
+		for _, point := range s.Points {
+			merged, ok := byPeriod[point.Period]
+			if !ok {
+				merged = &ValuePoint{Period: point.Period, AsOf: point.AsOf}
+				byPeriod[point.Period] = merged
+			}
+
+			merged.TotalValue += point.TotalValue
+			merged.Holdings += point.Holdings
+			if point.AsOf.After(merged.AsOf) {
+				merged.AsOf = point.AsOf
+			}
+		}
+	}
+
+	result := ValueSeries{Currency: base, Points: make([]ValuePoint, 0, len(byPeriod))}
+	for _, point := range byPeriod {
+		result.Points = append(result.Points, *point)
+	}
+
+	sort.Slice(result.Points, func(i, j int) bool {
+		return result.Points[i].Period.Before(result.Points[j].Period)
+	})
+
+	computeValueEarnings(result.Points)
+	return result
+}
+
+// ConvertPnLSeries convierte una serie de resultados a la moneda base usando la cotización de cada período
+func (fs *FXService) ConvertPnLSeries(series PnLSeries, converter *fx.Converter, base string) (PnLSeries, error) {
+	converted := PnLSeries{Currency: base, Points: make([]PnLPoint, len(series.Points))}
+
+	for i, point := range series.Points {
+		rate, err := converter.RateAt(series.Currency, base, point.AsOf)
+		if err != nil {
+			return PnLSeries{}, err
+		}
+
+		point.MarketValue *= rate
+		point.CostBasis *= rate
+		point.RealizedPnL *= rate
+		point.UnrealizedPnL *= rate
+		point.PeriodEarnings *= rate
+		converted.Points[i] = point
+	}
+
+	computePnLTotals(converted.Points)
+	return converted, nil
+}
+
+// MergePnLSeries suma varias series de resultados ya expresadas en la misma moneda, período a período
+func (fs *FXService) MergePnLSeries(series []PnLSeries, base string) PnLSeries {
+	byPeriod := make(map[time.Time]*PnLPoint)
+
+	for _, s := range series {
+		for _, point := range s.Points {
+			merged, ok := byPeriod[point.Period]
+			if !ok {
+				merged = &PnLPoint{Period: point.Period, AsOf: point.AsOf}
+				byPeriod[point.Period] = merged
+			}
+
+			merged.MarketValue += point.MarketValue
+			merged.CostBasis += point.CostBasis
+			merged.RealizedPnL += point.RealizedPnL
+			merged.UnrealizedPnL += point.UnrealizedPnL
+			merged.PeriodEarnings += point.PeriodEarnings
+			merged.Holdings += point.Holdings
+			merged.HoldingsWithoutBasis += point.HoldingsWithoutBasis
+			if point.AsOf.After(merged.AsOf) {
+				merged.AsOf = point.AsOf
+			}
+		}
+	}
+
+	result := PnLSeries{Currency: base, Points: make([]PnLPoint, 0, len(byPeriod))}
+	for _, point := range byPeriod {
+		result.Points = append(result.Points, *point)
+	}
+
+	sort.Slice(result.Points, func(i, j int) bool {
+		return result.Points[i].Period.Before(result.Points[j].Period)
+	})
+
+	computePnLTotals(result.Points)
+	return result
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"holding-snapshots/internal/brokerimport"
+	"holding-snapshots/internal/ledger"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ImportRequest son los parámetros de una importación de un CSV de broker
+type ImportRequest struct {
+	GroupID     string
+	Broker      string                // Formato predefinido; se ignora si se envía Mapping
+	Mapping     *brokerimport.Mapping // Mapeo propio de columnas
+	Content     io.Reader
+	SkipInvalid bool // Al confirmar, guardar las líneas válidas aunque otras tengan errores
+}
+
+// ImportAsset es un asset referenciado por el archivo
+type ImportAsset struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	AssetID string  `json:"assetId,omitempty"` // Vacío si se crea en la importación
+	New     bool    `json:"new"`
+	Price   float64 `json:"price"` // Precio obtenido al validar el código
+}
+
+// ImportHolding es el cambio resultante en un holding del grupo
+type ImportHolding struct {
+	Code              string  `json:"code"`
+	HoldingID         string  `json:"holdingId,omitempty"` // Vacío si se crea en la importación
+	New               bool    `json:"new"`
+	CurrentQuantity   float64 `json:"currentQuantity"`
+	ResultingQuantity float64 `json:"resultingQuantity"`
+	Transactions      int     `json:"transactions"` // Transacciones nuevas
+}
+
+// ImportReport es la vista previa o el resultado de una importación
+type ImportReport struct {
+	GroupID    string                   `json:"groupId"`
+	Broker     string                   `json:"broker"`
+	Committed  bool                     `json:"committed"`
+	Lines      int                      `json:"lines"`      // Líneas interpretadas
+	Accepted   int                      `json:"accepted"`   // Líneas que se guardan (o guardarían)
+	Duplicates int                      `json:"duplicates"` // Transacciones ya registradas, se omiten
+	Assets     []ImportAsset            `json:"assets"`
+	Holdings   []ImportHolding          `json:"holdings"`
+	Errors     []brokerimport.LineError `json:"errors"`
+	Skipped    []brokerimport.LineError `json:"skipped"`
+}
+
+// plannedHolding es el estado calculado de un holding antes de guardar la importación
+type plannedHolding struct {
+	code         string
+	asset        *models.Asset
+	newAsset     bool
+	holding      models.Holding
+	newHolding   bool
+	existing     []models.Transaction
+	transactions []models.Transaction
+	position     *float64 // Cantidad informada por una línea de posición
+	quantity     float64
+}
+
+type ImportService struct {
+	scrapingService    *ScrapingService
+	transactionService *TransactionService
+}
+
+// NewImportService crea una nueva instancia del servicio de importación de CSV de brokers
+func NewImportService() *ImportService {
+	return &ImportService{
+		scrapingService:    NewScrapingService(),
+		transactionService: NewTransactionService(),
+	}
+}
+
+// Preview interpreta y valida el archivo sin guardar nada
+func (is *ImportService) Preview(req ImportRequest) (*ImportReport, error) {
+	report, _, err := is.plan(req)
+	return report, err
+}
+
+// Commit guarda assets, holdings y transacciones del archivo en una única transacción.
+// Si hay líneas con errores no guarda nada, salvo que se pida SkipInvalid.
+func (is *ImportService) Commit(req ImportRequest) (*ImportReport, error) {
+	report, holdings, err := is.plan(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Errors) > 0 && !req.SkipInvalid {
+		return report, fmt.Errorf("el archivo tiene %d líneas con errores", len(report.Errors))
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, planned := range holdings {
+			if err := is.applyHolding(tx, planned); err != nil {
+				return fmt.Errorf("error importando %s: %w", planned.code, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Completar los IDs generados al guardar
+	for i := range report.Holdings {
+		for _, planned := range holdings {
+			if planned.code == report.Holdings[i].Code {
+				report.Holdings[i].HoldingID = planned.holding.ID
+			}
+		}
+	}
+	for i := range report.Assets {
+		for _, planned := range holdings {
+			if planned.code == report.Assets[i].Code {
+				report.Assets[i].AssetID = planned.asset.ID
+			}
+		}
+	}
+
+	report.Committed = true
+	log.Printf("📥 Importación en grupo %s: %d líneas, %d holdings, %d errores omitidos",
+		report.GroupID, report.Accepted, len(report.Holdings), len(report.Errors))
+
+	return report, nil
+}
+
+// plan interpreta el archivo, valida cada código y calcula el resultado por holding
+func (is *ImportService) plan(req ImportRequest) (*ImportReport, []*plannedHolding, error) {
+	var group models.Group
+	if err := database.DB.Preload("Type").First(&group, "id = ?", req.GroupID).Error; err != nil {
+		return nil, nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	mapping, err := resolveImportMapping(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := brokerimport.Parse(req.Content, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &ImportReport{
+		GroupID:  group.ID,
+		Broker:   mapping.Name,
+		Lines:    len(parsed.Lines),
+		Assets:   []ImportAsset{},
+		Holdings: []ImportHolding{},
+		Errors:   parsed.Errors,
+		Skipped:  parsed.Skipped,
+	}
+
+	// Líneas por código, en el orden en que aparecen los códigos
+	linesByCode := make(map[string][]brokerimport.Line)
+	var codes []string
+	for _, line := range parsed.Lines {
+		code := is.scrapingService.symbolMappingService.Normalize(&group.Type, line.Code)
+		if _, ok := linesByCode[code]; !ok {
+			codes = append(codes, code)
+		}
+		linesByCode[code] = append(linesByCode[code], line)
+	}
+
+	var holdings []*plannedHolding
+	for _, code := range codes {
+		lines := linesByCode[code]
+
+		planned, importAsset, err := is.planAsset(&group, code, lines)
+		if err != nil {
+			for _, line := range lines {
+				report.Errors = append(report.Errors, brokerimport.LineError{Line: line.Number, Code: code, Message: err.Error()})
+			}
+			continue
+		}
+
+		accepted, duplicates, lineErrors := is.planLines(planned, lines, group.Type.Currency)
+		report.Errors = append(report.Errors, lineErrors...)
+		report.Duplicates += duplicates
+		if accepted == 0 {
+			continue
+		}
+
+		report.Accepted += accepted
+		report.Assets = append(report.Assets, *importAsset)
+		report.Holdings = append(report.Holdings, ImportHolding{
+			Code:              code,
+			HoldingID:         planned.holding.ID,
+			New:               planned.newHolding,
+			CurrentQuantity:   planned.holding.Quantity,
+			ResultingQuantity: planned.quantity,
+			Transactions:      len(planned.transactions),
+		})
+		holdings = append(holdings, planned)
+	}
+
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+
+	return report, holdings, nil
+}
+
+// planAsset valida el código contra el tipo de inversión del grupo y busca su asset y holding
+func (is *ImportService) planAsset(group *models.Group, code string, lines []brokerimport.Line) (*plannedHolding, *ImportAsset, error) {
+	price, err := is.scrapingService.ValidateHolding(group.TypeID, code)
+	if err != nil || price <= 0 {
+		return nil, nil, fmt.Errorf("código no válido para %s", group.Type.Name)
+	}
+
+	planned := &plannedHolding{code: code}
+
+	existing, err := is.scrapingService.symbolMappingService.FindAsset(&group.Type, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var asset models.Asset
+	if existing != nil {
+		asset = *existing
+	} else {
+		name := code
+		for _, line := range lines {
+			if line.AssetName != "" {
+				name = line.AssetName
+				break
+			}
+		}
+		asset = models.Asset{Name: name, Code: code, LastPrice: price, TypeID: group.TypeID, IsValid: true}
+		planned.newAsset = true
+	}
+	planned.asset = &asset
+
+	if !planned.newAsset {
+		err := database.DB.Where("\"groupId\" = ? AND \"assetId\" = ?", group.ID, asset.ID).
+			Limit(1).Find(&planned.holding).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("error obteniendo holding: %w", err)
+		}
+
+		if planned.holding.ID != "" {
+			err := database.DB.Where("\"holdingId\" = ?", planned.holding.ID).Find(&planned.existing).Error
+			if err != nil {
+				return nil, nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+			}
+		}
+	}
+
+	if planned.holding.ID == "" {
+		planned.holding = models.Holding{GroupID: group.ID}
+		planned.newHolding = true
+	}
+
+	return planned, &ImportAsset{
+		Code:    code,
+		Name:    asset.Name,
+		AssetID: asset.ID,
+		New:     planned.newAsset,
+		Price:   price,
+	}, nil
+}
+
+// planLines valida las líneas de un código en orden cronológico contra el historial del holding.
+// Retorna la cantidad de líneas aceptadas, las transacciones duplicadas y los errores por línea.
+func (is *ImportService) planLines(planned *plannedHolding, lines []brokerimport.Line, currency string) (int, int, []brokerimport.LineError) {
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Date.Before(lines[j].Date) })
+
+	hasTransactions := len(planned.existing) > 0
+	for _, line := range lines {
+		if !line.IsPosition() {
+			hasTransactions = true
+		}
+	}
+
+	accepted, duplicates := 0, 0
+	claimed := make([]bool, len(planned.existing))
+	var lineErrors []brokerimport.LineError
+	fail := func(line brokerimport.Line, format string, args ...interface{}) {
+		lineErrors = append(lineErrors, brokerimport.LineError{Line: line.Number, Code: line.Code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, line := range lines {
+		if line.IsPosition() {
+			switch {
+			case hasTransactions:
+				fail(line, "el holding tiene transacciones; su cantidad se calcula a partir de ellas")
+			case planned.position != nil:
+				fail(line, "posición repetida para %s", line.Code)
+			default:
+				quantity := line.Quantity
+				planned.position = &quantity
+				accepted++
+			}
+			continue
+		}
+
+		transaction := models.Transaction{
+			HoldingID: planned.holding.ID,
+			Type:      line.Type,
+			Date:      line.Date,
+			Quantity:  line.Quantity,
+			Price:     line.Price,
+			Fees:      line.Fees,
+			Currency:  currency,
+			Notes:     fmt.Sprintf("Importado (línea %d)", line.Number),
+		}
+
+		if claimDuplicateTransaction(planned.existing, claimed, transaction) {
+			duplicates++
+			continue
+		}
+
+		if err := is.transactionService.validateTransaction(&transaction, currency); err != nil {
+			fail(line, "%v", err)
+			continue
+		}
+
+		history := append(append([]models.Transaction{}, planned.existing...), planned.transactions...)
+		if _, err := ledger.BuildPosition(append(history, transaction)); err != nil {
+			fail(line, "%v", err)
+			continue
+		}
+
+		transaction.CreatedAt = time.Now()
+		planned.transactions = append(planned.transactions, transaction)
+		accepted++
+	}
+
+	// Cantidad resultante: derivada de las transacciones o informada por la posición
+	planned.quantity = planned.holding.Quantity
+	if len(planned.existing)+len(planned.transactions) > 0 {
+		position, err := ledger.BuildPosition(append(append([]models.Transaction{}, planned.existing...), planned.transactions...))
+		if err == nil {
+			planned.quantity = position.Quantity
+		}
+	} else if planned.position != nil {
+		planned.quantity = *planned.position
+	}
+
+	return accepted, duplicates, lineErrors
+}
+
+// applyHolding guarda el asset, el holding y las transacciones planificadas
+func (is *ImportService) applyHolding(tx *gorm.DB, planned *plannedHolding) error {
+	if planned.newAsset {
+		if err := tx.Create(planned.asset).Error; err != nil {
+			return fmt.Errorf("error creando asset: %w", err)
+		}
+	}
+
+	if planned.newHolding {
+		planned.holding.AssetID = planned.asset.ID
+		planned.holding.Quantity = planned.quantity
+		if err := tx.Create(&planned.holding).Error; err != nil {
+			return fmt.Errorf("error creando holding: %w", err)
+		}
+	} else {
+		err := tx.Model(&models.Holding{}).Where("id = ?", planned.holding.ID).Update("quantity", planned.quantity).Error
+		if err != nil {
+			return fmt.Errorf("error actualizando cantidad del holding: %w", err)
+		}
+	}
+
+	for i := range planned.transactions {
+		planned.transactions[i].HoldingID = planned.holding.ID
+	}
+	if len(planned.transactions) > 0 {
+		if err := tx.Create(&planned.transactions).Error; err != nil {
+			return fmt.Errorf("error creando transacciones: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resolveImportMapping obtiene el mapeo enviado o el formato predefinido del broker
+func resolveImportMapping(req ImportRequest) (brokerimport.Mapping, error) {
+	if req.Mapping != nil {
+		mapping := *req.Mapping
+		if mapping.Name == "" {
+			mapping.Name = "custom"
+		}
+		return mapping, nil
+	}
+
+	broker := req.Broker
+	if broker == "" {
+		broker = "generic"
+	}
+
+	mapping, ok := brokerimport.Preset(broker)
+	if !ok {
+		return brokerimport.Mapping{}, fmt.Errorf("formato de broker desconocido: %s (disponibles: %v)", broker, brokerimport.PresetNames())
+	}
+	return mapping, nil
+}
+
+// claimDuplicateTransaction indica si ya hay una transacción igual registrada (reimportar el mismo
+// archivo) y la marca como usada: cada transacción existente cubre una sola línea, así la n-ésima línea
+// idéntica del archivo solo es duplicada si ya hay al menos n transacciones iguales
+func claimDuplicateTransaction(existing []models.Transaction, claimed []bool, transaction models.Transaction) bool {
+	for i, candidate := range existing {
+		if !claimed[i] &&
+			candidate.Type == transaction.Type &&
+			candidate.Date.Equal(transaction.Date) &&
+			math.Abs(candidate.Quantity-transaction.Quantity) < 1e-9 &&
+			math.Abs(candidate.Price-transaction.Price) < 1e-9 {
+			claimed[i] = true
+			return true
+		}
+	}
+	return false
+}
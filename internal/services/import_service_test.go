@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"holding-snapshots/internal/models"
+)
+
+func TestClaimDuplicateTransaction(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	buy := models.Transaction{Type: models.TransactionBuy, Date: date, Quantity: 10, Price: 100}
+
+	tests := []struct {
+		name     string
+		existing []models.Transaction
+		lines    []models.Transaction
+		want     []bool
+	}{
+		{
+			name:     "reimportar el mismo archivo",
+			existing: []models.Transaction{buy, buy},
+			lines:    []models.Transaction{buy, buy},
+			want:     []bool{true, true},
+		},
+		{
+			// Dos compras idénticas en el archivo y una ya registrada: la segunda es nueva
+			name:     "más líneas idénticas que transacciones",
+			existing: []models.Transaction{buy},
+			lines:    []models.Transaction{buy, buy},
+			want:     []bool{true, false},
+		},
+		{
+			name:     "distinto precio",
+			existing: []models.Transaction{buy},
+			lines:    []models.Transaction{{Type: models.TransactionBuy, Date: date, Quantity: 10, Price: 101}},
+			want:     []bool{false},
+		},
+		{
+			name:     "distinto tipo",
+			existing: []models.Transaction{buy},
+			lines:    []models.Transaction{{Type: models.TransactionSell, Date: date, Quantity: 10, Price: 100}},
+			want:     []bool{false},
+		},
+		{
+			name:  "holding sin transacciones",
+			lines: []models.Transaction{buy},
+			want:  []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimed := make([]bool, len(tt.existing))
+			for i, line := range tt.lines {
+				if got := claimDuplicateTransaction(tt.existing, claimed, line); got != tt.want[i] {
+					t.Errorf("línea %d: duplicada = %v, se esperaba %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
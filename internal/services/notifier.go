@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/mailer"
+)
+
+// AlertNotifier define la interfaz para los canales de envío de alertas
+type AlertNotifier interface {
+	// Channel retorna el nombre del canal (ver models.AlertChannel*)
+	Channel() string
+
+	// Notify envía la alerta disparada al usuario
+	Notify(user *models.User, trigger *models.AlertTrigger) error
+}
+
+// WebhookNotifier envía las alertas como evento alert.triggered por los webhooks configurados
+type WebhookNotifier struct {
+	webhookService *WebhookService
+}
+
+// NewWebhookNotifier crea un notificador que usa el servicio de webhooks
+func NewWebhookNotifier(webhookService *WebhookService) *WebhookNotifier {
+	return &WebhookNotifier{webhookService: webhookService}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return models.AlertChannelWebhook
+}
+
+// Notify encola el evento; falla si no hay webhooks configurados o el evento está deshabilitado
+func (n *WebhookNotifier) Notify(user *models.User, trigger *models.AlertTrigger) error {
+	return n.webhookService.Dispatch(EventAlertTriggered, map[string]interface{}{
+		"alert":  trigger,
+		"userId": user.ID,
+		"email":  user.Email,
+	})
+}
+
+// EmailNotifier envía las alertas por email al usuario
+type EmailNotifier struct{}
+
+// NewEmailNotifier crea un notificador por email
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{}
+}
+
+func (n *EmailNotifier) Channel() string {
+	return models.AlertChannelEmail
+}
+
+func (n *EmailNotifier) Notify(user *models.User, trigger *models.AlertTrigger) error {
+	if !mailer.IsConfigured() {
+		return fmt.Errorf("servidor SMTP no configurado")
+	}
+
+	return mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "🔔 Alerta de precio: " + trigger.Message,
+		Text:    fmt.Sprintf("Hola %s,\n\n%s\n\nPrecio actual: %.2f\n", user.Name, trigger.Message, trigger.Price),
+	})
+}
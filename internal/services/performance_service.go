@@ -0,0 +1,426 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"holding-snapshots/internal/fx"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/performance"
+	"holding-snapshots/pkg/database"
+)
+
+// HoldingPerformance es el rendimiento de un holding en un rango de fechas
+type HoldingPerformance struct {
+	HoldingID string `json:"holdingId"`
+	Code      string `json:"code"`
+	Currency  string `json:"currency"`
+	*performance.Result
+}
+
+// CurrencyPerformance es el rendimiento de un conjunto de holdings expresado en una moneda
+type CurrencyPerformance struct {
+	Currency string `json:"currency"`
+	*performance.Result
+}
+
+// GroupPerformance es el rendimiento de un grupo en un rango de fechas
+type GroupPerformance struct {
+	GroupID   string `json:"groupId"`
+	Name      string `json:"name"`
+	FXVariant string `json:"fxVariant,omitempty"`
+	CurrencyPerformance
+}
+
+// UserPerformance es el rendimiento de los grupos de un usuario, separado por moneda
+// o en un único resultado si se pidió una moneda base
+type UserPerformance struct {
+	UserID    string                `json:"userId"`
+	FXVariant string                `json:"fxVariant,omitempty"`
+	Results   []CurrencyPerformance `json:"results"`
+}
+
+type PerformanceService struct {
+	fxService *FXService
+}
+
+// NewPerformanceService crea una nueva instancia del servicio de rendimientos
+func NewPerformanceService() *PerformanceService {
+	return &PerformanceService{
+		fxService: NewFXService(),
+	}
+}
+
+// GetHoldingPerformance calcula TWR y MWR de un holding a partir de sus snapshots y transacciones
+func (ps *PerformanceService) GetHoldingPerformance(holdingID string, from, to *time.Time) (*HoldingPerformance, error) {
+	holding, series, err := ps.loadHoldingSeries(holdingID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := performance.Compute(series.Series)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HoldingPerformance{
+		HoldingID: holding.ID,
+		Code:      holding.Asset.Code,
+		Currency:  series.Currency,
+		Result:    result,
+	}, nil
+}
+
+// GetGroupPerformance calcula TWR y MWR de un grupo, convertido a la moneda base si opts no es nil
+func (ps *PerformanceService) GetGroupPerformance(groupID string, from, to *time.Time, opts *CurrencyOptions) (*GroupPerformance, error) {
+	group, series, err := ps.loadGroupSeries(groupID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := performance.Compute(series.Series)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GroupPerformance{
+		GroupID:             group.ID,
+		Name:                group.Name,
+		CurrencyPerformance: CurrencyPerformance{Currency: series.Currency, Result: result},
+	}
+	if opts != nil {
+		report.FXVariant = opts.Variant
+	}
+
+	return report, nil
+}
+
+// GetUserPerformance calcula TWR y MWR de todos los grupos de un usuario por moneda.
+// Si opts no es nil, los holdings de todas las monedas se convierten y se calculan juntos.
+func (ps *PerformanceService) GetUserPerformance(userID string, from, to *time.Time, opts *CurrencyOptions) (*UserPerformance, error) {
+	seriesByCurrency, err := ps.loadUserSeries(userID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UserPerformance{UserID: userID, Results: []CurrencyPerformance{}}
+	if opts != nil {
+		report.FXVariant = opts.Variant
+	}
+
+	for _, series := range seriesByCurrency {
+		result, err := performance.Compute(series.Series)
+		if err != nil {
+			return nil, fmt.Errorf("moneda %s: %w", series.Currency, err)
+		}
+		report.Results = append(report.Results, CurrencyPerformance{Currency: series.Currency, Result: result})
+	}
+
+	return report, nil
+}
+
+// currencySeries es la serie combinada de un conjunto de holdings expresada en una moneda
+type currencySeries struct {
+	Currency string
+	Series   performance.Series
+}
+
+// loadHoldingSeries obtiene un holding y su serie de valuaciones y flujos
+func (ps *PerformanceService) loadHoldingSeries(holdingID string, from, to *time.Time) (*models.Holding, *currencySeries, error) {
+	var holding models.Holding
+	err := database.DB.Preload("Asset").Preload("Group.Type").First(&holding, "id = ?", holdingID).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+
+	series, err := ps.buildHoldingSeries([]string{holding.ID}, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &holding, &currencySeries{
+		Currency: holding.Group.Type.Currency,
+		Series:   performance.Combine(series),
+	}, nil
+}
+
+// loadGroupSeries obtiene un grupo y la serie combinada de sus holdings,
+// convertida a la moneda base si opts no es nil
+func (ps *PerformanceService) loadGroupSeries(groupID string, from, to *time.Time, opts *CurrencyOptions) (*models.Group, *currencySeries, error) {
+	var group models.Group
+	if err := database.DB.Preload("Type").First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	var holdingIDs []string
+	err := database.DB.Model(&models.Holding{}).Where("\"groupId\" = ?", group.ID).Pluck("id", &holdingIDs).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo holdings del grupo: %w", err)
+	}
+
+	series, err := ps.buildHoldingSeries(holdingIDs, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &currencySeries{Currency: group.Type.Currency, Series: performance.Combine(series)}
+	if opts == nil {
+		return &group, result, nil
+	}
+
+	converter, err := ps.fxService.NewConverter(opts.Variant, []string{group.Type.Currency, opts.BaseCurrency}, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result.Series, err = convertPerformanceSeries(result.Series, converter, result.Currency, opts.BaseCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Currency = opts.BaseCurrency
+
+	return &group, result, nil
+}
+
+// loadUserSeries obtiene una serie combinada por moneda con los holdings de un usuario.
+// Si opts no es nil, retorna una única serie convertida a la moneda base.
+func (ps *PerformanceService) loadUserSeries(userID string, from, to *time.Time, opts *CurrencyOptions) ([]currencySeries, error) {
+	currencies, groupsByCurrency, err := getUserGroupsByCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var converter *fx.Converter
+	if opts != nil {
+		converter, err = ps.fxService.NewConverter(opts.Variant, append(currencies, opts.BaseCurrency), from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []currencySeries
+	var converted []performance.Series
+
+	for _, currency := range currencies {
+		var holdingIDs []string
+		err := database.DB.Model(&models.Holding{}).
+			Where("\"groupId\" IN ?", groupsByCurrency[currency]).
+			Pluck("id", &holdingIDs).Error
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo holdings del usuario: %w", err)
+		}
+
+		series, err := ps.buildHoldingSeries(holdingIDs, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		combined := performance.Combine(series)
+
+		if converter == nil {
+			result = append(result, currencySeries{Currency: currency, Series: combined})
+			continue
+		}
+
+		combined, err = convertPerformanceSeries(combined, converter, currency, opts.BaseCurrency)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, combined)
+	}
+
+	if converter != nil {
+		result = []currencySeries{{Currency: opts.BaseCurrency, Series: performance.Combine(converted)}}
+	}
+
+	return result, nil
+}
+
+// buildHoldingSeries arma la serie de valuaciones diarias y flujos de cada holding en el rango.
+// Los flujos salen de las transacciones del holding; si no tiene ninguna, se infieren de los
+// cambios de cantidad entre snapshots valuados al precio del snapshot.
+func (ps *PerformanceService) buildHoldingSeries(holdingIDs []string, from, to *time.Time) ([]performance.Series, error) {
+	if len(holdingIDs) == 0 {
+		return nil, nil
+	}
+
+	snapshotQuery := database.DB.Where("\"holdingId\" IN ?", holdingIDs)
+	transactionQuery := database.DB.Where("\"holdingId\" IN ?", holdingIDs)
+	if from != nil {
+		snapshotQuery = snapshotQuery.Where("\"createdAt\" >= ?", *from)
+		transactionQuery = transactionQuery.Where("date >= ?", *from)
+	}
+	if to != nil {
+		snapshotQuery = snapshotQuery.Where("\"createdAt\" <= ?", *to)
+		transactionQuery = transactionQuery.Where("date <= ?", *to)
+	}
+
+	var snapshots []models.Snapshot
+	if err := snapshotQuery.Order("\"createdAt\" ASC").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots: %w", err)
+	}
+
+	var transactions []models.Transaction
+	if err := transactionQuery.Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+
+	// Holdings con registro de transacciones, aunque no tengan movimientos en el rango
+	var withLedger []string
+	err := database.DB.Model(&models.Transaction{}).
+		Where("\"holdingId\" IN ?", holdingIDs).
+		Distinct("\"holdingId\"").
+		Pluck("\"holdingId\"", &withLedger).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+
+	hasLedger := make(map[string]bool, len(withLedger))
+	for _, id := range withLedger {
+		hasLedger[id] = true
+	}
+
+	snapshotsByHolding := make(map[string][]models.Snapshot)
+	for _, snapshot := range snapshots {
+		snapshotsByHolding[snapshot.HoldingID] = append(snapshotsByHolding[snapshot.HoldingID], snapshot)
+	}
+
+	transactionsByHolding := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		transactionsByHolding[transaction.HoldingID] = append(transactionsByHolding[transaction.HoldingID], transaction)
+	}
+
+	var result []performance.Series
+	for _, holdingID := range holdingIDs {
+		daily := dailySnapshots(snapshotsByHolding[holdingID])
+		if len(daily) == 0 {
+			continue
+		}
+
+		series := performance.Series{Valuations: make([]performance.Valuation, len(daily))}
+		for i, snapshot := range daily {
+			series.Valuations[i] = performance.Valuation{
+				Date:  truncateDay(snapshot.CreatedAt),
+				Value: snapshot.Price * snapshot.Quantity,
+			}
+		}
+
+		if hasLedger[holdingID] {
+			series.Flows = transactionFlows(transactionsByHolding[holdingID], daily)
+		} else {
+			series.Flows = inferredFlows(daily)
+		}
+
+		result = append(result, series)
+	}
+
+	return result, nil
+}
+
+// dailySnapshots conserva el último snapshot de cada día (los snapshots vienen ordenados)
+func dailySnapshots(snapshots []models.Snapshot) []models.Snapshot {
+	var daily []models.Snapshot
+	for _, snapshot := range snapshots {
+		if n := len(daily); n > 0 && truncateDay(daily[n-1].CreatedAt).Equal(truncateDay(snapshot.CreatedAt)) {
+			daily[n-1] = snapshot
+			continue
+		}
+		daily = append(daily, snapshot)
+	}
+	return daily
+}
+
+// transactionFlows convierte transacciones en flujos de dinero. Las transferencias sin precio
+// se valúan al precio del snapshot vigente a su fecha.
+func transactionFlows(transactions []models.Transaction, daily []models.Snapshot) []performance.CashFlow {
+	flows := make([]performance.CashFlow, 0, len(transactions))
+
+	for _, transaction := range transactions {
+		price := transaction.Price
+		if price == 0 {
+			price = priceAt(daily, transaction.Date)
+		}
+
+		amount := transaction.Quantity * price
+		switch transaction.Type {
+		case models.TransactionBuy:
+			amount += transaction.Fees
+		case models.TransactionSell:
+			amount = -(amount - transaction.Fees)
+		case models.TransactionTransferOut:
+			amount = -amount
+		}
+
+		flows = append(flows, performance.CashFlow{Date: truncateDay(transaction.Date), Amount: amount})
+	}
+
+	return flows
+}
+
+// inferredFlows deduce aportes y retiros de los cambios de cantidad entre snapshots.
+// El primer snapshot se registra como aporte por si el holding aparece a mitad del rango;
+// si coincide con el inicio del portafolio queda incluido en la valuación inicial.
+func inferredFlows(daily []models.Snapshot) []performance.CashFlow {
+	flows := []performance.CashFlow{{
+		Date:   truncateDay(daily[0].CreatedAt),
+		Amount: daily[0].Price * daily[0].Quantity,
+	}}
+
+	for i := 1; i < len(daily); i++ {
+		delta := daily[i].Quantity - daily[i-1].Quantity
+		if math.Abs(delta) < 1e-9 {
+			continue
+		}
+
+		flows = append(flows, performance.CashFlow{
+			Date:   truncateDay(daily[i].CreatedAt),
+			Amount: delta * daily[i].Price,
+		})
+	}
+
+	return flows
+}
+
+// priceAt retorna el precio del último snapshot anterior o igual a la fecha, o el primero si no hay
+func priceAt(daily []models.Snapshot, at time.Time) float64 {
+	price := daily[0].Price
+	for _, snapshot := range daily {
+		if snapshot.CreatedAt.After(at) {
+			break
+		}
+		price = snapshot.Price
+	}
+	return price
+}
+
+// convertPerformanceSeries convierte valuaciones y flujos a la moneda base con la cotización de cada fecha
+func convertPerformanceSeries(series performance.Series, converter *fx.Converter, currency, base string) (performance.Series, error) {
+	converted := performance.Series{
+		Valuations: make([]performance.Valuation, len(series.Valuations)),
+		Flows:      make([]performance.CashFlow, len(series.Flows)),
+	}
+
+	for i, valuation := range series.Valuations {
+		value, err := converter.Convert(valuation.Value, currency, base, valuation.Date)
+		if err != nil {
+			return performance.Series{}, err
+		}
+		converted.Valuations[i] = performance.Valuation{Date: valuation.Date, Value: value}
+	}
+
+	for i, flow := range series.Flows {
+		amount, err := converter.Convert(flow.Amount, currency, base, flow.Date)
+		if err != nil {
+			return performance.Series{}, err
+		}
+		converted.Flows[i] = performance.CashFlow{Date: flow.Date, Amount: amount}
+	}
+
+	return converted, nil
+}
+
+// truncateDay lleva una fecha al inicio de su día en UTC
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/ledger"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// PnLPoint es el resultado agregado de un portafolio en un período
+type PnLPoint struct {
+	Period               time.Time `json:"period"`
+	AsOf                 time.Time `json:"asOf"` // Fecha del registro más reciente del período
+	MarketValue          float64   `json:"marketValue"`
+	CostBasis            float64   `json:"costBasis"`
+	RealizedPnL          float64   `json:"realizedPnL"`       // Acumulado al cierre del período
+	PeriodRealizedPnL    float64   `json:"periodRealizedPnL"` // Realizado durante el período
+	UnrealizedPnL        float64   `json:"unrealizedPnL"`
+	TotalPnL             float64   `json:"totalPnL"`
+	PeriodEarnings       float64   `json:"periodEarnings"`
+	Holdings             int       `json:"holdings"`
+	HoldingsWithoutBasis int       `json:"holdingsWithoutBasis"`
+}
+
+// PnLSeries es la serie de resultados de un portafolio en una moneda
+type PnLSeries struct {
+	Currency string     `json:"currency"`
+	Points   []PnLPoint `json:"points"`
+}
+
+// HoldingPnLReport es el historial de resultados de un holding por snapshot
+type HoldingPnLReport struct {
+	HoldingID string               `json:"holdingId"`
+	Code      string               `json:"code"`
+	Currency  string               `json:"currency"`
+	Points    []models.SnapshotPnL `json:"points"`
+}
+
+// GroupPnLReport es la serie de resultados de un grupo
+type GroupPnLReport struct {
+	GroupID   string `json:"groupId"`
+	Name      string `json:"name"`
+	Interval  string `json:"interval"`
+	FXVariant string `json:"fxVariant,omitempty"` // Cotización usada si se pidió conversión
+	PnLSeries
+}
+
+// UserPnLReport es la serie de resultados de un usuario, separada por moneda
+// o en una única serie si se pidió una moneda base
+type UserPnLReport struct {
+	UserID    string      `json:"userId"`
+	Interval  string      `json:"interval"`
+	FXVariant string      `json:"fxVariant,omitempty"`
+	Series    []PnLSeries `json:"series"`
+}
+
+type PnLService struct {
+	fxService *FXService
+}
+
+// NewPnLService crea una nueva instancia del servicio de resultados
+func NewPnLService() *PnLService {
+	return &PnLService{
+		fxService: NewFXService(),
+	}
+}
+
+// costMethod retorna el método de costo configurado
+func (ps *PnLService) costMethod() string {
+	if config.AppConfig != nil && ledger.IsValidMethod(config.AppConfig.PnLCostMethod) {
+		return config.AppConfig.PnLCostMethod
+	}
+	return ledger.MethodAverage
+}
+
+// RecordSnapshotPnL calcula y guarda el resultado realizado y no realizado del holding en un snapshot
+func (ps *PnLService) RecordSnapshotPnL(snapshot *models.Snapshot) (*models.SnapshotPnL, error) {
+	pnl, err := ps.BuildSnapshotPnL(database.DB, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Create(pnl).Error; err != nil {
+		return nil, fmt.Errorf("error guardando resultado del snapshot: %w", err)
+	}
+
+	return pnl, nil
+}
+
+// HoldingEarnings obtiene los earnings que se guardan en el holding a partir del resultado de su
+// último snapshot: el no realizado sobre el costo si tiene transacciones, o la variación del período si no
+func HoldingEarnings(pnl *models.SnapshotPnL) (earnings, relativeEarnings float64) {
+	if !pnl.HasCostBasis {
+		return pnl.PeriodEarnings, pnl.PeriodRelativeEarnings
+	}
+
+	if pnl.CostBasis > 0 {
+		relativeEarnings = (pnl.UnrealizedPnL / pnl.CostBasis) * 100
+	}
+	return pnl.UnrealizedPnL, relativeEarnings
+}
+
+// BuildSnapshotPnL calcula el resultado de un snapshot a partir de las transacciones hasta su fecha
+// y del snapshot anterior del mismo holding
+func (ps *PnLService) BuildSnapshotPnL(db *gorm.DB, snapshot *models.Snapshot) (*models.SnapshotPnL, error) {
+	var previous models.Snapshot
+	err := db.Where("\"holdingId\" = ? AND \"createdAt\" < ?", snapshot.HoldingID, snapshot.CreatedAt).
+		Order("\"createdAt\" DESC").
+		Limit(1).
+		Find(&previous).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshot anterior: %w", err)
+	}
+
+	var transactions []models.Transaction
+	err = db.Where("\"holdingId\" = ? AND date <= ?", snapshot.HoldingID, snapshot.CreatedAt).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+
+	if previous.ID == "" {
+		return ps.computeSnapshotPnL(snapshot, nil, transactions)
+	}
+	return ps.computeSnapshotPnL(snapshot, &previous, transactions)
+}
+
+// computeSnapshotPnL calcula el resultado de un snapshot dado el snapshot anterior del holding (nil si
+// es el primero) y sus transacciones; solo cuentan las transacciones hasta la fecha del snapshot
+func (ps *PnLService) computeSnapshotPnL(snapshot, previous *models.Snapshot, transactions []models.Transaction) (*models.SnapshotPnL, error) {
+	method := ps.costMethod()
+
+	pnl := &models.SnapshotPnL{
+		SnapshotID:  snapshot.ID,
+		HoldingID:   snapshot.HoldingID,
+		Price:       snapshot.Price,
+		Quantity:    snapshot.Quantity,
+		MarketValue: snapshot.Price * snapshot.Quantity,
+		Method:      method,
+		CreatedAt:   snapshot.CreatedAt,
+	}
+
+	// Variación contra el snapshot anterior (lo que antes solo quedaba en Holding.Earnings)
+	if previous != nil && previous.Price > 0 {
+		pnl.PeriodEarnings = (snapshot.Price - previous.Price) * snapshot.Quantity
+		pnl.PeriodRelativeEarnings = ((snapshot.Price - previous.Price) / previous.Price) * 100
+	}
+
+	// Costo y resultado realizado según las transacciones hasta la fecha del snapshot
+	var until []models.Transaction
+	for _, transaction := range transactions {
+		if !transaction.Date.After(snapshot.CreatedAt) {
+			until = append(until, transaction)
+		}
+	}
+
+	if len(until) == 0 {
+		return pnl, nil
+	}
+
+	position, err := ledger.BuildPosition(until)
+	if err != nil {
+		return nil, fmt.Errorf("historial de transacciones inválido: %w", err)
+	}
+
+	pnl.HasCostBasis = true
+	pnl.CostBasis = position.CostBasis(method)
+	pnl.RealizedPnL = position.RealizedPnL(method)
+	pnl.UnrealizedPnL = position.UnrealizedPnL(snapshot.Price, method)
+
+	return pnl, nil
+}
+
+// GetHoldingReport obtiene el historial de resultados de un holding
+func (ps *PnLService) GetHoldingReport(holdingID string, from, to *time.Time) (*HoldingPnLReport, error) {
+	var holding models.Holding
+	err := database.DB.Preload("Asset").Preload("Group.Type").First(&holding, "id = ?", holdingID).Error
+	if err != nil {
+		return nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+
+	query := database.DB.Where("\"holdingId\" = ?", holdingID)
+	if from != nil {
+		query = query.Where("\"createdAt\" >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("\"createdAt\" <= ?", *to)
+	}
+
+	var points []models.SnapshotPnL
+	if err := query.Order("\"createdAt\" ASC").Find(&points).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo resultados del holding: %w", err)
+	}
+
+	return &HoldingPnLReport{
+		HoldingID: holding.ID,
+		Code:      holding.Asset.Code,
+		Currency:  holding.Group.Type.Currency,
+		Points:    points,
+	}, nil
+}
+
+// GetGroupReport obtiene la serie de resultados de un grupo por período,
+// convertida a la moneda base si opts no es nil
+func (ps *PnLService) GetGroupReport(groupID, interval string, from, to *time.Time, opts *CurrencyOptions) (*GroupPnLReport, error) {
+	if !validIntervals[interval] {
+		return nil, fmt.Errorf("intervalo inválido: %s (day, week o month)", interval)
+	}
+
+	var group models.Group
+	if err := database.DB.Preload("Type").First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("\"groupId\" = ?", group.ID)
+
+	points, err := ps.aggregatePnL(holdings, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GroupPnLReport{
+		GroupID:   group.ID,
+		Name:      group.Name,
+		Interval:  interval,
+		PnLSeries: PnLSeries{Currency: group.Type.Currency, Points: points},
+	}
+
+	if opts == nil {
+		return report, nil
+	}
+
+	converter, err := ps.fxService.NewConverter(opts.Variant, []string{group.Type.Currency, opts.BaseCurrency}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.PnLSeries, err = ps.fxService.ConvertPnLSeries(report.PnLSeries, converter, opts.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	report.FXVariant = opts.Variant
+
+	return report, nil
+}
+
+// GetUserReport obtiene la serie de resultados de todos los grupos de un usuario, por moneda.
+// Si opts no es nil, las series se convierten a la moneda base y se suman en una sola.
+func (ps *PnLService) GetUserReport(userID, interval string, from, to *time.Time, opts *CurrencyOptions) (*UserPnLReport, error) {
+	if !validIntervals[interval] {
+		return nil, fmt.Errorf("intervalo inválido: %s (day, week o month)", interval)
+	}
+
+	currencies, groupsByCurrency, err := getUserGroupsByCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UserPnLReport{
+		UserID:   userID,
+		Interval: interval,
+		Series:   make([]PnLSeries, 0, len(currencies)),
+	}
+
+	for _, currency := range currencies {
+		holdings := database.DB.Model(&models.Holding{}).Select("id").
+			Where("\"groupId\" IN ?", groupsByCurrency[currency])
+
+		points, err := ps.aggregatePnL(holdings, interval, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Series = append(report.Series, PnLSeries{Currency: currency, Points: points})
+	}
+
+	if opts == nil {
+		return report, nil
+	}
+
+	converter, err := ps.fxService.NewConverter(opts.Variant, append(currencies, opts.BaseCurrency), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]PnLSeries, 0, len(report.Series))
+	for _, series := range report.Series {
+		c, err := ps.fxService.ConvertPnLSeries(series, converter, opts.BaseCurrency)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, c)
+	}
+
+	report.Series = []PnLSeries{ps.fxService.MergePnLSeries(converted, opts.BaseCurrency)}
+	report.FXVariant = opts.Variant
+
+	return report, nil
+}
+
+// aggregatePnL suma los resultados del último registro de cada holding en cada período
+func (ps *PnLService) aggregatePnL(holdings *gorm.DB, interval string, from, to *time.Time) ([]PnLPoint, error) {
+	conditions := "\"holdingId\" IN (?)"
+	args := []interface{}{interval, holdings}
+
+	if from != nil {
+		conditions += " AND \"createdAt\" >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		conditions += " AND \"createdAt\" <= ?"
+		args = append(args, *to)
+	}
+
+	query := `
+		SELECT period,
+			MAX("createdAt") AS as_of,
+			SUM("marketValue") AS market_value,
+			SUM("costBasis") AS cost_basis,
+			SUM("realizedPnL") AS realized_pn_l,
+			SUM("unrealizedPnL") AS unrealized_pn_l,
+			SUM("periodEarnings") AS period_earnings,
+			COUNT(*) AS holdings,
+			COUNT(*) FILTER (WHERE NOT "hasCostBasis") AS holdings_without_basis
+		FROM (
+			SELECT DISTINCT ON ("holdingId", period) *
+			FROM (
+				SELECT *, date_trunc(?, "createdAt") AS period
+				FROM "SnapshotPnL"
+				WHERE ` + conditions + `
+			) AS bucketed
+			ORDER BY "holdingId", period, "createdAt" DESC
+		) AS latest
+		GROUP BY period
+		ORDER BY period ASC`
+
+	var rows []struct {
+		Period               time.Time
+		AsOf                 time.Time
+		MarketValue          float64
+		CostBasis            float64
+		RealizedPnL          float64 `gorm:"column:realized_pn_l"`
+		UnrealizedPnL        float64 `gorm:"column:unrealized_pn_l"`
+		PeriodEarnings       float64
+		Holdings             int
+		HoldingsWithoutBasis int
+	}
+
+	if err := database.DB.Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error agregando resultados: %w", err)
+	}
+
+	points := make([]PnLPoint, 0, len(rows))
+	for _, row := range rows {
+		points = append(points, PnLPoint{
+			Period:               row.Period,
+			AsOf:                 row.AsOf,
+			MarketValue:          row.MarketValue,
+			CostBasis:            row.CostBasis,
+			RealizedPnL:          row.RealizedPnL,
+			UnrealizedPnL:        row.UnrealizedPnL,
+			PeriodEarnings:       row.PeriodEarnings,
+			Holdings:             row.Holdings,
+			HoldingsWithoutBasis: row.HoldingsWithoutBasis,
+		})
+	}
+
+	computePnLTotals(points)
+	return points, nil
+}
+
+// computePnLTotals calcula el resultado total y el realizado en cada período.
+// En el primer período del rango se incluye lo realizado antes de él.
+func computePnLTotals(points []PnLPoint) {
+	for i := range points {
+		points[i].TotalPnL = points[i].RealizedPnL + points[i].UnrealizedPnL
+		points[i].PeriodRealizedPnL = points[i].RealizedPnL
+		if i > 0 {
+			points[i].PeriodRealizedPnL = points[i].RealizedPnL - points[i-1].RealizedPnL
+		}
+	}
+}
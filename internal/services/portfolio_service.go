@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// Intervalos de agregación soportados (argumento de date_trunc en PostgreSQL)
+var validIntervals = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+}
+
+// ValuePoint es el valor total de un portafolio en un período
+type ValuePoint struct {
+	Period           time.Time `json:"period"`
+	AsOf             time.Time `json:"asOf"` // Fecha del snapshot más reciente del período
+	TotalValue       float64   `json:"totalValue"`
+	Holdings         int       `json:"holdings"`
+	Earnings         float64   `json:"earnings"`
+	RelativeEarnings float64   `json:"relativeEarnings"`
+}
+
+// ValueSeries es la serie temporal de valor de un portafolio en una moneda
+type ValueSeries struct {
+	Currency string       `json:"currency"`
+	Points   []ValuePoint `json:"points"`
+}
+
+// GroupValueSeries es la serie de valor de un grupo
+type GroupValueSeries struct {
+	GroupID   string `json:"groupId"`
+	Name      string `json:"name"`
+	Interval  string `json:"interval"`
+	FXVariant string `json:"fxVariant,omitempty"` // Cotización usada si se pidió conversión
+	ValueSeries
+}
+
+// UserValueSeries es la serie de valor de todos los grupos de un usuario, separada por moneda
+// o en una única serie si se pidió una moneda base
+type UserValueSeries struct {
+	UserID    string        `json:"userId"`
+	Interval  string        `json:"interval"`
+	FXVariant string        `json:"fxVariant,omitempty"`
+	Series    []ValueSeries `json:"series"`
+}
+
+type PortfolioService struct {
+	fxService *FXService
+}
+
+// NewPortfolioService crea una nueva instancia del servicio de portafolios
+func NewPortfolioService() *PortfolioService {
+	return &PortfolioService{
+		fxService: NewFXService(),
+	}
+}
+
+// GetGroupValueSeries calcula la serie de valor total de un grupo por período.
+// Si opts no es nil, los valores se convierten a la moneda base con la cotización de cada período.
+func (ps *PortfolioService) GetGroupValueSeries(groupID, interval string, from, to *time.Time, opts *CurrencyOptions) (*GroupValueSeries, error) {
+	if !validIntervals[interval] {
+		return nil, fmt.Errorf("intervalo inválido: %s (day, week o month)", interval)
+	}
+
+	var group models.Group
+	if err := database.DB.Preload("Type").First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, fmt.Errorf("grupo no encontrado: %w", err)
+	}
+
+	holdings := database.DB.Model(&models.Holding{}).Select("id").Where("\"groupId\" = ?", group.ID)
+
+	points, err := ps.aggregateValues(holdings, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupValueSeries{
+		GroupID:  group.ID,
+		Name:     group.Name,
+		Interval: interval,
+		ValueSeries: ValueSeries{
+			Currency: group.Type.Currency,
+			Points:   points,
+		},
+	}
+
+	if opts == nil {
+		return result, nil
+	}
+
+	converter, err := ps.fxService.NewConverter(opts.Variant, []string{group.Type.Currency, opts.BaseCurrency}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ValueSeries, err = ps.fxService.ConvertValueSeries(result.ValueSeries, converter, opts.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	result.FXVariant = opts.Variant
+
+	return result, nil
+}
+
+// GetUserValueSeries calcula la serie de valor de todos los grupos de un usuario,
+// agrupando los grupos por moneda ya que no se pueden sumar valores en monedas distintas.
+// Si opts no es nil, cada serie se convierte a la moneda base y se suman en una sola.
+func (ps *PortfolioService) GetUserValueSeries(userID, interval string, from, to *time.Time, opts *CurrencyOptions) (*UserValueSeries, error) {
+	if !validIntervals[interval] {
+		return nil, fmt.Errorf("intervalo inválido: %s (day, week o month)", interval)
+	}
+
+	currencies, groupsByCurrency, err := getUserGroupsByCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserValueSeries{
+		UserID:   userID,
+		Interval: interval,
+		Series:   make([]ValueSeries, 0, len(currencies)),
+	}
+
+	for _, currency := range currencies {
+		holdings := database.DB.Model(&models.Holding{}).Select("id").
+			Where("\"groupId\" IN ?", groupsByCurrency[currency])
+
+		points, err := ps.aggregateValues(holdings, interval, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Series = append(result.Series, ValueSeries{Currency: currency, Points: points})
+	}
+
+	if opts == nil {
+		return result, nil
+	}
+
+	converter, err := ps.fxService.NewConverter(opts.Variant, append(currencies, opts.BaseCurrency), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]ValueSeries, 0, len(result.Series))
+	for _, series := range result.Series {
+		c, err := ps.fxService.ConvertValueSeries(series, converter, opts.BaseCurrency)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, c)
+	}
+
+	result.Series = []ValueSeries{ps.fxService.MergeValueSeries(converted, opts.BaseCurrency)}
+	result.FXVariant = opts.Variant
+
+	return result, nil
+}
+
+// getUserGroupsByCurrency obtiene los IDs de los grupos de un usuario agrupados por moneda,
+// junto con las monedas en el orden en que aparecen
+func getUserGroupsByCurrency(userID string) ([]string, map[string][]string, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, nil, fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	var groups []models.Group
+	if err := database.DB.Preload("Type").Where(&models.Group{UserID: user.ID}).Find(&groups).Error; err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo grupos del usuario: %w", err)
+	}
+
+	groupsByCurrency := make(map[string][]string)
+	var currencies []string
+	for _, group := range groups {
+		if _, ok := groupsByCurrency[group.Type.Currency]; !ok {
+			currencies = append(currencies, group.Type.Currency)
+		}
+		groupsByCurrency[group.Type.Currency] = append(groupsByCurrency[group.Type.Currency], group.ID)
+	}
+
+	return currencies, groupsByCurrency, nil
+}
+
+// aggregateValues suma precio × cantidad del último snapshot de cada holding en cada período
+// y calcula la variación respecto al período anterior
+func (ps *PortfolioService) aggregateValues(holdings *gorm.DB, interval string, from, to *time.Time) ([]ValuePoint, error) {
+	conditions := "\"holdingId\" IN (?)"
+	args := []interface{}{interval, holdings}
+
+	if from != nil {
+		conditions += " AND \"createdAt\" >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		conditions += " AND \"createdAt\" <= ?"
+		args = append(args, *to)
+	}
+
+	// DISTINCT ON evita contar dos veces un holding con varios snapshots en el mismo período
+	query := `
+		SELECT period, MAX("createdAt") AS as_of, SUM(price * quantity) AS total_value, COUNT(*) AS holdings
+		FROM (
+			SELECT DISTINCT ON ("holdingId", period) "holdingId", period, price, quantity, "createdAt"
+			FROM (
+				SELECT "holdingId", date_trunc(?, "createdAt") AS period, price, quantity, "createdAt"
+				FROM "Snapshot"
+				WHERE ` + conditions + `
+			) AS bucketed
+			ORDER BY "holdingId", period, "createdAt" DESC
+		) AS latest
+		GROUP BY period
+		ORDER BY period ASC`
+
+	var rows []struct {
+		Period     time.Time
+		AsOf       time.Time
+		TotalValue float64
+		Holdings   int
+	}
+
+	if err := database.DB.Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error agregando valores de snapshots: %w", err)
+	}
+
+	points := make([]ValuePoint, 0, len(rows))
+	for _, row := range rows {
+		points = append(points, ValuePoint{
+			Period:     row.Period,
+			AsOf:       row.AsOf,
+			TotalValue: row.TotalValue,
+			Holdings:   row.Holdings,
+		})
+	}
+
+	computeValueEarnings(points)
+	return points, nil
+}
+
+// computeValueEarnings calcula la variación de cada punto respecto al anterior
+func computeValueEarnings(points []ValuePoint) {
+	for i := range points {
+		points[i].Earnings = 0
+		points[i].RelativeEarnings = 0
+		if i == 0 {
+			continue
+		}
+
+		previous := points[i-1].TotalValue
+		points[i].Earnings = points[i].TotalValue - previous
+		if previous > 0 {
+			points[i].RelativeEarnings = (points[i].Earnings / previous) * 100
+		}
+	}
+}
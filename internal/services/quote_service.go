@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/pkg/cache"
+	"holding-snapshots/pkg/database"
+)
+
+// Orígenes de una cotización
+const (
+	QuoteSourceLive      = "live"      // Scrapeada en esta request
+	QuoteSourceCache     = "cache"     // Scrapeada hace menos que la frescura del tipo
+	QuoteSourceLastPrice = "lastPrice" // Falló el scraping y se usa el último precio del asset
+)
+
+// ErrQuoteUnavailable indica que no se pudo scrapear el precio y no hay un último precio guardado
+var ErrQuoteUnavailable = errors.New("cotización no disponible")
+
+// Quote es el precio actual de un código para un tipo de inversión
+type Quote struct {
+	Code      string     `json:"code"`
+	TypeID    string     `json:"typeId"`
+	TypeName  string     `json:"typeName"`
+	Price     float64    `json:"price"`
+	Currency  string     `json:"currency"`
+	Source    string     `json:"source"`
+	Stale     bool       `json:"stale"`               // El precio no es de un scraping reciente
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"` // Momento en que se obtuvo el precio, si se conoce
+	Error     string     `json:"error,omitempty"`     // Motivo por el que no se pudo scrapear
+}
+
+// QuoteResult es el resultado de una cotización dentro de una consulta de varios códigos
+type QuoteResult struct {
+	Code  string `json:"code"`
+	Quote *Quote `json:"quote,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// defaultQuoteTTLs es la frescura por tipo de inversión si no se configura QUOTE_CACHE_TTLS
+var defaultQuoteTTLs = map[string]time.Duration{
+	scraping.CryptoStrategyEnum:  3 * time.Minute,
+	scraping.CedearsStrategyEnum: 15 * time.Minute,
+	scraping.StockStrategyEnum:   15 * time.Minute,
+}
+
+type QuoteService struct {
+	scrapingService *ScrapingService
+	ttls            map[string]time.Duration
+	defaultTTL      time.Duration
+}
+
+// NewQuoteService crea una nueva instancia del servicio de cotizaciones
+func NewQuoteService() *QuoteService {
+	qs := &QuoteService{
+		scrapingService: NewScrapingService(),
+		ttls:            defaultQuoteTTLs,
+		defaultTTL:      15 * time.Minute,
+	}
+
+	if config.AppConfig != nil {
+		if config.AppConfig.QuoteCacheDefaultTTLMinutes > 0 {
+			qs.defaultTTL = time.Duration(config.AppConfig.QuoteCacheDefaultTTLMinutes) * time.Minute
+		}
+		if len(config.AppConfig.QuoteCacheTTLs) > 0 {
+			qs.ttls = parseQuoteTTLs(config.AppConfig.QuoteCacheTTLs)
+		}
+	}
+
+	return qs
+}
+
+// parseQuoteTTLs interpreta entradas "<nombre o id del tipo>=<duración>"; las inválidas se ignoran
+func parseQuoteTTLs(entries []string) map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			log.Printf("⚠️ QUOTE_CACHE_TTLS: entrada inválida %q", entry)
+			continue
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || ttl <= 0 {
+			log.Printf("⚠️ QUOTE_CACHE_TTLS: duración inválida en %q", entry)
+			continue
+		}
+		ttls[strings.TrimSpace(parts[0])] = ttl
+	}
+	return ttls
+}
+
+// freshness retorna cuánto tiempo se sirve una cotización desde el cache para el tipo de inversión
+func (qs *QuoteService) freshness(typeInvestment *models.TypeInvestment) time.Duration {
+	if ttl, ok := qs.ttls[typeInvestment.ID]; ok {
+		return ttl
+	}
+	if ttl, ok := qs.ttls[typeInvestment.Name]; ok {
+		return ttl
+	}
+	return qs.defaultTTL
+}
+
+// GetQuote obtiene la cotización de un código
+func (qs *QuoteService) GetQuote(typeID, code string) (*Quote, error) {
+	typeInvestment, err := qs.scrapingService.GetTypeInvestmentByID(typeID)
+	if err != nil {
+		return nil, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+	}
+
+	return qs.quote(typeInvestment, code)
+}
+
+// GetQuotes obtiene las cotizaciones de varios códigos del mismo tipo, en paralelo y en el orden pedido
+func (qs *QuoteService) GetQuotes(typeID string, codes []string) ([]QuoteResult, error) {
+	typeInvestment, err := qs.scrapingService.GetTypeInvestmentByID(typeID)
+	if err != nil {
+		return nil, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+	}
+
+	concurrency := 4
+	if config.AppConfig != nil && config.AppConfig.ValidationBatchConcurrency > 0 {
+		concurrency = config.AppConfig.ValidationBatchConcurrency
+	}
+
+	results := make([]QuoteResult, len(codes))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, code := range codes {
+		results[i].Code = strings.ToUpper(strings.TrimSpace(code))
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(result *QuoteResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			quote, err := qs.quote(typeInvestment, result.Code)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.Quote = quote
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// quote resuelve la cotización: cache, scraping y, si este falla, el último precio del asset
+func (qs *QuoteService) quote(typeInvestment *models.TypeInvestment, code string) (*Quote, error) {
+	code = qs.scrapingService.symbolMappingService.Normalize(typeInvestment, code)
+	if code == "" {
+		return nil, fmt.Errorf("código vacío")
+	}
+
+	key := fmt.Sprintf("quote:%s:%s", typeInvestment.ID, code)
+	if quote, ok := getCachedQuote(key); ok {
+		quote.Source = QuoteSourceCache
+		return quote, nil
+	}
+
+	waitScrapingTurn()
+	price, err := qs.scrapingService.FetchAssetPrice(typeInvestment, code)
+	if err == nil && price <= 0 {
+		err = scraping.ErrPriceNotFound
+	}
+
+	if err == nil {
+		now := time.Now().UTC()
+		quote := &Quote{
+			Code:      code,
+			TypeID:    typeInvestment.ID,
+			TypeName:  typeInvestment.Name,
+			Price:     price,
+			Currency:  typeInvestment.Currency,
+			Source:    QuoteSourceLive,
+			FetchedAt: &now,
+		}
+		setCachedQuote(key, quote, qs.freshness(typeInvestment))
+		return quote, nil
+	}
+
+	log.Printf("⚠️ No se pudo scrapear la cotización de %s (%s), se busca el último precio: %v", code, typeInvestment.Name, err)
+	return qs.lastPriceQuote(typeInvestment, code, err)
+}
+
+// lastPriceQuote arma una cotización desactualizada con el lastPrice del asset y la fecha de su último precio registrado
+func (qs *QuoteService) lastPriceQuote(typeInvestment *models.TypeInvestment, code string, scrapeErr error) (*Quote, error) {
+	asset, err := qs.scrapingService.symbolMappingService.FindAsset(typeInvestment, code)
+	if err != nil {
+		return nil, err
+	}
+	if asset == nil || asset.LastPrice <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrQuoteUnavailable, scrapeErr)
+	}
+
+	quote := &Quote{
+		Code:     code,
+		TypeID:   typeInvestment.ID,
+		TypeName: typeInvestment.Name,
+		Price:    asset.LastPrice,
+		Currency: typeInvestment.Currency,
+		Source:   QuoteSourceLastPrice,
+		Stale:    true,
+		Error:    scrapeErr.Error(),
+	}
+
+	var latest models.AssetPrice
+	if err := database.DB.Where("\"assetId\" = ?", asset.ID).Order("\"createdAt\" DESC").First(&latest).Error; err == nil {
+		fetchedAt := latest.CreatedAt.UTC()
+		quote.FetchedAt = &fetchedAt
+	}
+
+	return quote, nil
+}
+
+// getCachedQuote lee una cotización del cache; cualquier error de Redis se trata como cache miss
+func getCachedQuote(key string) (*Quote, bool) {
+	if cache.GetClient() == nil {
+		return nil, false
+	}
+
+	data, err := cache.Get(context.Background(), key)
+	if err != nil {
+		return nil, false
+	}
+
+	var quote Quote
+	if err := json.Unmarshal([]byte(data), &quote); err != nil {
+		return nil, false
+	}
+	return &quote, true
+}
+
+// setCachedQuote guarda una cotización con la frescura de su tipo; los errores solo se registran
+func setCachedQuote(key string, quote *Quote, ttl time.Duration) {
+	if cache.GetClient() == nil {
+		return
+	}
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return
+	}
+
+	if err := cache.Set(context.Background(), key, string(data), ttl); err != nil {
+		log.Printf("⚠️ Error guardando cotización en cache: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/retention"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// retentionDeleteBatch es la cantidad máxima de IDs por sentencia de borrado
+const retentionDeleteBatch = 500
+
+// retentionMutex evita dos ejecuciones simultáneas (cron y endpoint de administración)
+var retentionMutex sync.Mutex
+
+// RetentionReport resume una ejecución de la política de retención
+type RetentionReport struct {
+	DryRun           bool             `json:"dryRun"`
+	Policy           retention.Policy `json:"policy"`
+	MonthlyCutoff    time.Time        `json:"monthlyCutoff"`   // Snapshots anteriores se resumen por mes
+	QuarterlyCutoff  time.Time        `json:"quarterlyCutoff"` // Snapshots anteriores se resumen por trimestre
+	Holdings         int              `json:"holdings"`        // Holdings con períodos resumidos
+	MonthlyRollups   int              `json:"monthlyRollups"`
+	QuarterlyRollups int              `json:"quarterlyRollups"`
+	ReplacedRollups  int              `json:"replacedRollups"`
+	SnapshotsDeleted int              `json:"snapshotsDeleted"`
+	SnapshotsKept    int              `json:"snapshotsKept"` // Último snapshot de cada período resumido
+	StartedAt        time.Time        `json:"startedAt"`
+	FinishedAt       time.Time        `json:"finishedAt"`
+}
+
+type RetentionService struct{}
+
+// NewRetentionService crea una nueva instancia del servicio de retención de snapshots
+func NewRetentionService() *RetentionService {
+	return &RetentionService{}
+}
+
+// Policy retorna la política de retención configurada
+func (rs *RetentionService) Policy() retention.Policy {
+	policy := retention.Policy{FullResolutionDays: 365, MonthlyDays: 1095}
+	if config.AppConfig != nil {
+		policy.FullResolutionDays = config.AppConfig.RetentionFullResolutionDays
+		policy.MonthlyDays = config.AppConfig.RetentionMonthlyDays
+	}
+	return policy
+}
+
+// Run aplica la política de retención: resume por mes o trimestre los snapshots fuera del período
+// de resolución completa y conserva solo el último de cada período. Con dryRun solo reporta.
+func (rs *RetentionService) Run(dryRun bool) (*RetentionReport, error) {
+	if !retentionMutex.TryLock() {
+		return nil, fmt.Errorf("ya hay una ejecución de retención en curso")
+	}
+	defer retentionMutex.Unlock()
+
+	policy := rs.Policy()
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("política de retención inválida: %w", err)
+	}
+
+	report := &RetentionReport{
+		DryRun:    dryRun,
+		Policy:    policy,
+		StartedAt: time.Now(),
+	}
+	report.MonthlyCutoff, report.QuarterlyCutoff = policy.Cutoffs(report.StartedAt)
+
+	holdingIDs, err := rs.pendingHoldings(report.MonthlyCutoff, report.QuarterlyCutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, holdingID := range holdingIDs {
+		buckets, err := rs.planHolding(holdingID, report.MonthlyCutoff, report.QuarterlyCutoff)
+		if err != nil {
+			return nil, err
+		}
+		if len(buckets) == 0 {
+			continue
+		}
+
+		if !dryRun {
+			if err := rs.applyBuckets(buckets); err != nil {
+				return nil, fmt.Errorf("error aplicando retención al holding %s: %w", holdingID, err)
+			}
+		}
+
+		report.Holdings++
+		for _, bucket := range buckets {
+			if bucket.Rollup.Granularity == models.RollupGranularityQuarter {
+				report.QuarterlyRollups++
+			} else {
+				report.MonthlyRollups++
+			}
+			report.ReplacedRollups += len(bucket.ReplaceRollupIDs)
+			report.SnapshotsDeleted += len(bucket.DeleteSnapshotIDs)
+			if bucket.KeepSnapshotID != "" {
+				report.SnapshotsKept++
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	prefix := "🗄️ Retención de snapshots"
+	if dryRun {
+		prefix += " (dry-run)"
+	}
+	log.Printf("%s: %d holdings, %d agregados mensuales, %d trimestrales, %d snapshots eliminados",
+		prefix, report.Holdings, report.MonthlyRollups, report.QuarterlyRollups, report.SnapshotsDeleted)
+
+	return report, nil
+}
+
+// GetHoldingRollups obtiene los agregados de un holding en orden cronológico
+func (rs *RetentionService) GetHoldingRollups(holdingID string, from, to *time.Time) ([]models.SnapshotRollup, error) {
+	var holding models.Holding
+	if err := database.DB.First(&holding, "id = ?", holdingID).Error; err != nil {
+		return nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+
+	query := database.DB.Where("\"holdingId\" = ?", holding.ID)
+	if from != nil {
+		query = query.Where("\"periodEnd\" > ?", *from)
+	}
+	if to != nil {
+		query = query.Where("\"periodStart\" <= ?", *to)
+	}
+
+	rollups := []models.SnapshotRollup{}
+	if err := query.Order("\"periodStart\" ASC").Find(&rollups).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo agregados del holding: %w", err)
+	}
+	return rollups, nil
+}
+
+// pendingHoldings obtiene los holdings con snapshots anteriores al corte mensual o con
+// agregados mensuales que ya corresponden a un trimestre
+func (rs *RetentionService) pendingHoldings(monthlyCutoff, quarterlyCutoff time.Time) ([]string, error) {
+	var fromSnapshots []string
+	err := database.DB.Model(&models.Snapshot{}).
+		Where("\"createdAt\" < ?", monthlyCutoff).
+		Distinct().
+		Pluck("\"holdingId\"", &fromSnapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo holdings con snapshots antiguos: %w", err)
+	}
+
+	var fromRollups []string
+	err = database.DB.Model(&models.SnapshotRollup{}).
+		Where("granularity = ? AND \"periodStart\" < ?", models.RollupGranularityMonth, quarterlyCutoff).
+		Distinct().
+		Pluck("\"holdingId\"", &fromRollups).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo holdings con agregados mensuales: %w", err)
+	}
+
+	seen := make(map[string]bool, len(fromSnapshots)+len(fromRollups))
+	var holdingIDs []string
+	for _, id := range append(fromSnapshots, fromRollups...) {
+		if !seen[id] {
+			seen[id] = true
+			holdingIDs = append(holdingIDs, id)
+		}
+	}
+	sort.Strings(holdingIDs)
+
+	return holdingIDs, nil
+}
+
+// planHolding calcula los períodos a resumir de un holding
+func (rs *RetentionService) planHolding(holdingID string, monthlyCutoff, quarterlyCutoff time.Time) ([]retention.Bucket, error) {
+	var snapshots []models.Snapshot
+	err := database.DB.Select("id", "\"holdingId\"", "price", "quantity", "\"createdAt\"").
+		Where("\"holdingId\" = ? AND \"createdAt\" < ?", holdingID, monthlyCutoff).
+		Order("\"createdAt\" ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots del holding %s: %w", holdingID, err)
+	}
+
+	var rollups []models.SnapshotRollup
+	err = database.DB.Where("\"holdingId\" = ? AND \"periodStart\" < ?", holdingID, monthlyCutoff).
+		Find(&rollups).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo agregados del holding %s: %w", holdingID, err)
+	}
+
+	return retention.Plan(holdingID, snapshots, rollups, monthlyCutoff, quarterlyCutoff), nil
+}
+
+// applyBuckets guarda los agregados y elimina los snapshots resumidos de un holding en una transacción
+func (rs *RetentionService) applyBuckets(buckets []retention.Bucket) error {
+	var replaceRollups, deleteSnapshots []string
+	rollups := make([]models.SnapshotRollup, 0, len(buckets))
+	for _, bucket := range buckets {
+		replaceRollups = append(replaceRollups, bucket.ReplaceRollupIDs...)
+		deleteSnapshots = append(deleteSnapshots, bucket.DeleteSnapshotIDs...)
+
+		rollup := bucket.Rollup
+		rollup.CreatedAt = time.Now()
+		rollups = append(rollups, rollup)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(replaceRollups) > 0 {
+			if err := tx.Where("id IN ?", replaceRollups).Delete(&models.SnapshotRollup{}).Error; err != nil {
+				return fmt.Errorf("error eliminando agregados previos: %w", err)
+			}
+		}
+
+		if err := tx.Create(&rollups).Error; err != nil {
+			return fmt.Errorf("error guardando agregados: %w", err)
+		}
+
+		for start := 0; start < len(deleteSnapshots); start += retentionDeleteBatch {
+			end := start + retentionDeleteBatch
+			if end > len(deleteSnapshots) {
+				end = len(deleteSnapshots)
+			}
+			ids := deleteSnapshots[start:end]
+
+			if err := tx.Where("\"snapshotId\" IN ?", ids).Delete(&models.SnapshotPnL{}).Error; err != nil {
+				return fmt.Errorf("error eliminando resultados de snapshots: %w", err)
+			}
+			if err := tx.Where("id IN ?", ids).Delete(&models.Snapshot{}).Error; err != nil {
+				return fmt.Errorf("error eliminando snapshots: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -1,19 +1,26 @@
 package services
 
 import (
+	"holding-snapshots/internal/config"
 	"holding-snapshots/internal/models"
 	"holding-snapshots/internal/scraping"
+	"holding-snapshots/internal/symbols"
 	"holding-snapshots/pkg/database"
 	"log"
+	"strings"
+	"sync"
+	"time"
 )
 
 type ScrapingService struct {
-	factory *scraping.ScrapingFactory
+	factory              *scraping.ScrapingFactory
+	symbolMappingService *SymbolMappingService
 }
 
 func NewScrapingService() *ScrapingService {
 	return &ScrapingService{
-		factory: &scraping.ScrapingFactory{},
+		factory:              &scraping.ScrapingFactory{},
+		symbolMappingService: NewSymbolMappingService(),
 	}
 }
 
@@ -24,7 +31,7 @@ func (s *ScrapingService) FetchAssetPrice(typeInvestment *models.TypeInvestment,
 		log.Print("[FetchAssetPrice] Error getting strategy")
 		return 0, err
 	}
-	price, err := strategy.FetchPrice(typeInvestment, code)
+	price, err := strategy.FetchPrice(typeInvestment, s.symbolMappingService.ProviderSymbol(typeInvestment, code, symbols.ProviderYahoo))
 	if err != nil {
 		log.Print("[FetchAssetPrice] Error getting price")
 		return 0, err
@@ -54,17 +61,157 @@ func (s *ScrapingService) GetTypeInvestmentByID(id string) (*models.TypeInvestme
 
 // ValidateHolding valida si un holding es válido obteniendo su precio
 func (s *ScrapingService) ValidateHolding(typeInvestmentId string, code string) (float64, error) {
+	price, _, err := s.ValidateHoldingCached(typeInvestmentId, code)
+	return price, err
+}
+
+// ValidateHoldingCached valida un holding e indica si el resultado vino del cache de validaciones
+func (s *ScrapingService) ValidateHoldingCached(typeInvestmentId string, code string) (float64, bool, error) {
 	typeInvestment, err := s.GetTypeInvestmentByID(typeInvestmentId)
 	if err != nil {
 		log.Print("[ValidateHolding] Error getting type investment")
-		return 0, err
+		return 0, false, err
 	}
-	price, err := s.FetchAssetPrice(typeInvestment, code)
+	price, cached, err := s.validateCode(typeInvestment, code)
 	if err != nil {
 		log.Print("[ValidateHolding] Error getting price")
-		return 0, err
+		return 0, cached, err
 	}
-	return price, nil
+	return price, cached, nil
+}
+
+// BatchValidationItem es un código a validar dentro de un lote
+type BatchValidationItem struct {
+	Code             string `json:"code"`
+	TypeInvestmentID string `json:"typeInvestmentId"`
+}
+
+// BatchValidationResult es el resultado de validar un código del lote
+type BatchValidationResult struct {
+	Code             string        `json:"code"`
+	TypeInvestmentID string        `json:"typeInvestmentId"`
+	IsValid          bool          `json:"isValid"`
+	Price            *float64      `json:"price,omitempty"`
+	Currency         string        `json:"currency,omitempty"`
+	Error            string        `json:"error,omitempty"`
+	Cached           bool          `json:"cached"`                // El resultado vino del cache de validaciones
+	Suggestions      []SymbolMatch `json:"suggestions,omitempty"` // Símbolos parecidos cuando no es válido
+	AssetID          string        `json:"assetId,omitempty"`     // Asset registrado, si se pidió
+	AssetCreated     bool          `json:"assetCreated,omitempty"`
+	RegisterError    string        `json:"registerError,omitempty"`
+}
+
+// scrapingThrottle espacia los requests al sitio de scraping, compartido entre validaciones, cotizaciones,
+// búsquedas y el cron
+var (
+	scrapingThrottleMutex sync.Mutex
+	scrapingThrottleLast  time.Time
+)
+
+// waitScrapingTurn bloquea hasta que pase el intervalo mínimo desde el último request de scraping
+func waitScrapingTurn() {
+	interval := 200 * time.Millisecond
+	if config.AppConfig != nil && config.AppConfig.ScrapingMinIntervalMs >= 0 {
+		interval = time.Duration(config.AppConfig.ScrapingMinIntervalMs) * time.Millisecond
+	}
+
+	scrapingThrottleMutex.Lock()
+	next := scrapingThrottleLast.Add(interval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	scrapingThrottleLast = next
+	scrapingThrottleMutex.Unlock()
+
+	time.Sleep(time.Until(next))
+}
+
+// ValidateBatch valida varios códigos en paralelo respetando la pausa mínima entre requests de scraping.
+// Los resultados respetan el orden de los items y traen el código canónico; los códigos repetidos se consultan una sola vez
+// y los que están en el cache de validaciones no se scrapean.
+func (s *ScrapingService) ValidateBatch(items []BatchValidationItem) []BatchValidationResult {
+	results := make([]BatchValidationResult, len(items))
+	typeInvestments := make(map[string]*models.TypeInvestment)
+	pending := make(map[string][]int) // tipo|código -> posiciones en el lote
+	order := []string{}
+
+	for i, item := range items {
+		code := strings.TrimSpace(item.Code)
+		results[i] = BatchValidationResult{Code: code, TypeInvestmentID: item.TypeInvestmentID}
+
+		if code == "" {
+			results[i].Error = "código vacío"
+			continue
+		}
+
+		typeInvestment, loaded := typeInvestments[item.TypeInvestmentID]
+		if !loaded {
+			typeInvestment, _ = s.GetTypeInvestmentByID(item.TypeInvestmentID)
+			typeInvestments[item.TypeInvestmentID] = typeInvestment
+		}
+		if typeInvestment == nil {
+			results[i].Error = "tipo de inversión no encontrado"
+			continue
+		}
+		results[i].Currency = typeInvestment.Currency
+		code = s.symbolMappingService.Normalize(typeInvestment, code)
+		results[i].Code = code
+
+		key := item.TypeInvestmentID + "|" + code
+		if _, ok := pending[key]; !ok {
+			order = append(order, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+
+	concurrency := 4
+	if config.AppConfig != nil && config.AppConfig.ValidationBatchConcurrency > 0 {
+		concurrency = config.AppConfig.ValidationBatchConcurrency
+	}
+
+	keys := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < len(order); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				mu.Lock()
+				first := results[pending[key][0]]
+				mu.Unlock()
+
+				price, cached, err := s.validateCode(typeInvestments[first.TypeInvestmentID], first.Code)
+
+				mu.Lock()
+				for _, i := range pending[key] {
+					results[i].Cached = cached
+					if err != nil {
+						results[i].Error = err.Error()
+						continue
+					}
+					value := price
+					results[i].Price = &value
+					results[i].IsValid = price > 0
+					if price <= 0 {
+						results[i].Error = "precio no disponible"
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range order {
+		keys <- key
+	}
+	close(keys)
+	wg.Wait()
+
+	log.Printf("📋 Lote de validación procesado: %d códigos, %d distintos", len(items), len(order))
+	return results
 }
 
 /*
@@ -144,158 +291,6 @@ func (s *ScrapingService) ProcessHolding(holding *models.Holding) error {
 	return nil
 }
 
-// ValidatedHoldingCache representa la estructura de datos para el cache de holdings validados
-type ValidatedHoldingCache struct {
-	Name     string    `json:"name"`
-	Code     string    `json:"code"`
-	TypeID   string    `json:"type_id"`
-	TypeName string    `json:"type_name"`
-	Valid    bool      `json:"valid"`
-	CachedAt time.Time `json:"cached_at"`
-}
-
-// ValidateHoldingWithType valida si un holding existe usando directamente el tipo de inversión
-// Este método es útil para validar holdings antes de crear un grupo
-func (s *ScrapingService) ValidateHoldingWithType(name, code string, typeInvestment *models.TypeInvestment, groupName string, quantity float64) (*models.Holding, bool, error) {
-	// Crear clave de cache basada en tipo de inversión y código
-	cacheKey := fmt.Sprintf("validated_holding:%s:%s", typeInvestment.ID, code)
-	ctx := context.Background()
-
-	// Verificar cache primero
-	if cachedData, err := cache.Get(ctx, cacheKey); err == nil {
-		var cachedHolding ValidatedHoldingCache
-		if json.Unmarshal([]byte(cachedData), &cachedHolding) == nil {
-			log.Printf("📦 Holding %s (%s) encontrado en cache para tipo %s - Válido: %v",
-				name, code, cachedHolding.TypeName, cachedHolding.Valid)
-
-			if cachedHolding.Valid {
-				// Crear el holding usando los datos cacheados
-				holding := &models.Holding{
-					Name:     name,
-					Code:     code,
-					Quantity: quantity,
-				}
-				return holding, true, nil
-			} else {
-				// Si el cache dice que no es válido, devolver false sin hacer scraping
-				return nil, false, nil
-			}
-		}
-	}
-
-	log.Printf("🔍 Holding %s (%s) no encontrado en cache, realizando validación por scraping...", name, code)
-
-	// Debug: Log antes de llamar fetchAssetPriceWithType
-	log.Printf("🚀 ANTES de fetchAssetPriceWithType - TypeInvestment.ScrapingURL: '%s'", typeInvestment.ScrapingURL)
-
-	// No está en cache, verificar si el activo existe usando la estrategia apropiada
-	_, err := s.fetchAssetPriceWithType(typeInvestment, code, groupName)
-
-	// Crear estructura para cache
-	cacheData := ValidatedHoldingCache{
-		Name:     name,
-		Code:     code,
-		TypeID:   typeInvestment.ID,
-		TypeName: typeInvestment.Name,
-		Valid:    err == nil,
-		CachedAt: time.Now(),
-	}
-
-	// Guardar resultado en cache (tanto si es válido como si no lo es)
-	// TTL de 24 horas para holdings válidos, 2 horas para inválidos
-	cacheTTL := 2 * time.Hour
-	if cacheData.Valid {
-		cacheTTL = 24 * time.Hour
-	}
-
-	if cacheJSON, jsonErr := json.Marshal(cacheData); jsonErr == nil {
-		cache.Set(ctx, cacheKey, string(cacheJSON), cacheTTL)
-		log.Printf("💾 Resultado de validación guardado en cache para %s (%s) - Válido: %v, TTL: %v",
-			name, code, cacheData.Valid, cacheTTL)
-	}
-
-	if err != nil {
-		log.Printf("⚠️ Holding %s (%s) no válido: %v", name, code, err)
-		return nil, false, nil // No es válido pero no es un error del sistema
-	}
-
-	// Crear el holding de respuesta (sin guardarlo en DB)
-	holding := &models.Holding{
-		Name:     name,
-		Code:     code,
-		Quantity: quantity,
-	}
-
-	return holding, true, nil
-}
-
-// ValidateHolding valida si un holding existe en la URL de scraping con cache Redis
-// Este método requiere que el grupo ya exista para obtener el tipo de inversión
-func (s *ScrapingService) ValidateHolding(name, code, groupID string, quantity float64) (*models.Holding, bool, error) {
-	// Obtener el grupo y su tipo de inversión
-	var group models.Group
-	err := database.DB.Preload("Type").First(&group, "id = ?", groupID).Error
-	if err != nil {
-		return nil, false, fmt.Errorf("grupo no encontrado: %w", err)
-	}
-
-	// Usar el nuevo método que no depende de grupo existente
-	holding, isValid, err := s.ValidateHoldingWithType(name, code, &group.Type, group.Name, quantity)
-	if err != nil {
-		return nil, false, err
-	}
-
-	// Si es válido, agregar el GroupID al holding
-	if isValid && holding != nil {
-		holding.GroupID = groupID
-	}
-
-	return holding, isValid, nil
-}
-
-// ClearValidatedHoldingCache elimina el cache de un holding específico
-func (s *ScrapingService) ClearValidatedHoldingCache(typeID, code string) error {
-	cacheKey := fmt.Sprintf("validated_holding:%s:%s", typeID, code)
-	ctx := context.Background()
-
-	err := cache.Delete(ctx, cacheKey)
-	if err != nil {
-		return fmt.Errorf("error eliminando cache para holding %s del tipo %s: %w", code, typeID, err)
-	}
-
-	log.Printf("🗑️ Cache eliminado para holding %s del tipo %s", code, typeID)
-	return nil
-}
-
-// GetValidatedHoldingFromCache obtiene información de un holding desde el cache
-func (s *ScrapingService) GetValidatedHoldingFromCache(typeID, code string) (*ValidatedHoldingCache, bool, error) {
-	cacheKey := fmt.Sprintf("validated_holding:%s:%s", typeID, code)
-	ctx := context.Background()
-
-	cachedData, err := cache.Get(ctx, cacheKey)
-	if err != nil {
-		return nil, false, nil // No está en cache, no es un error
-	}
-
-	var cachedHolding ValidatedHoldingCache
-	if err := json.Unmarshal([]byte(cachedData), &cachedHolding); err != nil {
-		return nil, false, fmt.Errorf("error deserializando cache: %w", err)
-	}
-
-	return &cachedHolding, true, nil
-}
-
-// GetValidationCacheStats obtiene estadísticas del cache de validaciones
-func (s *ScrapingService) GetValidationCacheStats() map[string]interface{} {
-	stats := map[string]interface{}{
-		"cache_pattern": "validated_holding:*",
-		"description":   "Cache de holdings validados por tipo de inversión",
-		"ttl_valid":     "24 horas para holdings válidos",
-		"ttl_invalid":   "2 horas para holdings inválidos",
-	}
-	return stats
-}
-
 // FetchAssetPrice obtiene el precio de un activo usando la estrategia apropiada
 // Este método requiere un grupo existente para obtener el tipo de inversión
 func (s *ScrapingService) FetchAssetPrice(typeInvestment *models.TypeInvestment, code, groupName string) (float64, error) {
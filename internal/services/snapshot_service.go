@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+	"holding-snapshots/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotFilter define los filtros y la paginación para listar snapshots
+type SnapshotFilter struct {
+	HoldingID string
+	AssetID   string
+	GroupID   string
+	UserID    string
+	From      *time.Time
+	To        *time.Time
+	Cursor    *utils.Cursor
+	Limit     int
+	Ascending bool
+}
+
+// SnapshotItem es la representación de un snapshot expuesta por la API
+type SnapshotItem struct {
+	ID        string    `json:"id"`
+	HoldingID string    `json:"holdingId"`
+	GroupID   string    `json:"groupId"`
+	AssetID   string    `json:"assetId"`
+	Code      string    `json:"code"`
+	Price     float64   `json:"price"`
+	Quantity  float64   `json:"quantity"`
+	Value     float64   `json:"value"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SnapshotPage es una página de snapshots con el cursor para la siguiente
+type SnapshotPage struct {
+	Items      []SnapshotItem `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	HasMore    bool           `json:"hasMore"`
+}
+
+type SnapshotService struct{}
+
+// NewSnapshotService crea una nueva instancia del servicio de snapshots
+func NewSnapshotService() *SnapshotService {
+	return &SnapshotService{}
+}
+
+// ListSnapshots obtiene una página de snapshots según los filtros indicados
+func (ss *SnapshotService) ListSnapshots(filter SnapshotFilter) (*SnapshotPage, error) {
+	query := ss.applyFilters(database.DB.Model(&models.Snapshot{}), filter)
+
+	direction := "DESC"
+	comparator := "<"
+	if filter.Ascending {
+		direction = "ASC"
+		comparator = ">"
+	}
+
+	if filter.Cursor != nil {
+		query = query.Where(fmt.Sprintf("(\"createdAt\", id) %s (?, ?)", comparator),
+			filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	var snapshots []models.Snapshot
+	err := query.
+		Preload("Holding.Asset").
+		Order(fmt.Sprintf("\"createdAt\" %s, id %s", direction, direction)).
+		Limit(filter.Limit + 1). // Un elemento extra para saber si hay más páginas
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshots: %w", err)
+	}
+
+	page := &SnapshotPage{Items: make([]SnapshotItem, 0, len(snapshots))}
+	if len(snapshots) > filter.Limit {
+		page.HasMore = true
+		snapshots = snapshots[:filter.Limit]
+	}
+
+	for _, snapshot := range snapshots {
+		page.Items = append(page.Items, SnapshotItem{
+			ID:        snapshot.ID,
+			HoldingID: snapshot.HoldingID,
+			GroupID:   snapshot.Holding.GroupID,
+			AssetID:   snapshot.Holding.AssetID,
+			Code:      snapshot.Holding.Asset.Code,
+			Price:     snapshot.Price,
+			Quantity:  snapshot.Quantity,
+			Value:     snapshot.Price * snapshot.Quantity,
+			CreatedAt: snapshot.CreatedAt,
+		})
+	}
+
+	if page.HasMore {
+		last := snapshots[len(snapshots)-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// HoldingExists verifica si existe un holding con el ID indicado
+func (ss *SnapshotService) HoldingExists(holdingID string) bool {
+	var count int64
+	database.DB.Model(&models.Holding{}).Where("id = ?", holdingID).Count(&count)
+	return count > 0
+}
+
+// applyFilters agrega las condiciones de holding, asset, grupo, usuario y fechas
+func (ss *SnapshotService) applyFilters(query *gorm.DB, filter SnapshotFilter) *gorm.DB {
+	if filter.HoldingID != "" {
+		query = query.Where("\"holdingId\" = ?", filter.HoldingID)
+	}
+
+	if filter.AssetID != "" {
+		query = query.Where("\"holdingId\" IN (?)", database.DB.Model(&models.Holding{}).
+			Select("id").Where("\"assetId\" = ?", filter.AssetID))
+	}
+
+	if filter.GroupID != "" {
+		query = query.Where("\"holdingId\" IN (?)", database.DB.Model(&models.Holding{}).
+			Select("id").Where("\"groupId\" = ?", filter.GroupID))
+	}
+
+	if filter.UserID != "" {
+		userGroups := database.DB.Model(&models.Group{}).Select("id").Where(&models.Group{UserID: filter.UserID})
+		query = query.Where("\"holdingId\" IN (?)", database.DB.Model(&models.Holding{}).
+			Select("id").Where("\"groupId\" IN (?)", userGroups))
+	}
+
+	if filter.From != nil {
+		query = query.Where("\"createdAt\" >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("\"createdAt\" <= ?", *filter.To)
+	}
+
+	return query
+}
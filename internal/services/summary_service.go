@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sort"
+	texttemplate "text/template"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+	"holding-snapshots/pkg/mailer"
+)
+
+//go:embed templates/weekly_summary.html templates/weekly_summary.txt
+var summaryTemplates embed.FS
+
+// HoldingSummary resume la variación semanal de un holding
+type HoldingSummary struct {
+	HoldingID        string  `json:"holdingId"`
+	Code             string  `json:"code"`
+	Name             string  `json:"name"`
+	Quantity         float64 `json:"quantity"`
+	Price            float64 `json:"price"`
+	Value            float64 `json:"value"`
+	PreviousValue    float64 `json:"previousValue"`
+	Earnings         float64 `json:"earnings"`
+	RelativeEarnings float64 `json:"relativeEarnings"`
+}
+
+// GroupSummary resume el valor total y la variación semanal de un grupo
+type GroupSummary struct {
+	GroupID          string           `json:"groupId"`
+	Name             string           `json:"name"`
+	Currency         string           `json:"currency"`
+	TotalValue       float64          `json:"totalValue"`
+	PreviousValue    float64          `json:"previousValue"`
+	Earnings         float64          `json:"earnings"`
+	RelativeEarnings float64          `json:"relativeEarnings"`
+	Holdings         []HoldingSummary `json:"holdings"`
+}
+
+// UserSummary es el resumen semanal completo de un usuario
+type UserSummary struct {
+	UserID    string          `json:"userId"`
+	UserName  string          `json:"userName"`
+	Email     string          `json:"email"`
+	PeriodEnd time.Time       `json:"periodEnd"`
+	Groups    []GroupSummary  `json:"groups"`
+	Best      *HoldingSummary `json:"best"`
+	Worst     *HoldingSummary `json:"worst"`
+}
+
+type SummaryService struct {
+	htmlTemplate *htmltemplate.Template
+	textTemplate *texttemplate.Template
+}
+
+// NewSummaryService crea una nueva instancia del servicio de resúmenes
+func NewSummaryService() *SummaryService {
+	funcs := map[string]interface{}{
+		"money":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		"percent":  func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+		"quantity": func(v float64) string { return fmt.Sprintf("%g", v) },
+	}
+
+	return &SummaryService{
+		htmlTemplate: htmltemplate.Must(htmltemplate.New("weekly_summary.html").
+			Funcs(funcs).ParseFS(summaryTemplates, "templates/weekly_summary.html")),
+		textTemplate: texttemplate.Must(texttemplate.New("weekly_summary.txt").
+			Funcs(funcs).ParseFS(summaryTemplates, "templates/weekly_summary.txt")),
+	}
+}
+
+// SendWeeklySummaries envía el resumen semanal a todos los usuarios que lo tengan habilitado
+func (ss *SummaryService) SendWeeklySummaries() {
+	if !config.AppConfig.WeeklySummaryEnabled {
+		return
+	}
+
+	if !mailer.IsConfigured() {
+		log.Println("⚠️ Resumen semanal habilitado pero SMTP no configurado, se omite el envío")
+		return
+	}
+
+	users, err := ss.getSubscribedUsers()
+	if err != nil {
+		log.Printf("❌ Error obteniendo usuarios para resumen semanal: %v", err)
+		return
+	}
+
+	log.Printf("📧 Enviando resumen semanal a %d usuarios...", len(users))
+
+	sent := 0
+	for _, user := range users {
+		if err := ss.SendUserSummary(user.ID); err != nil {
+			log.Printf("⚠️ Error enviando resumen semanal a %s: %v", user.Email, err)
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("📧 Resumen semanal enviado a %d/%d usuarios", sent, len(users))
+}
+
+// SendUserSummary construye, renderiza y envía el resumen de un usuario
+func (ss *SummaryService) SendUserSummary(userID string) error {
+	summary, err := ss.BuildUserSummary(userID)
+	if err != nil {
+		return err
+	}
+
+	if len(summary.Groups) == 0 {
+		log.Printf("ℹ️ Usuario %s sin holdings, no se envía resumen", summary.Email)
+		return nil
+	}
+
+	html, text, err := ss.Render(summary)
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(mailer.Message{
+		To:      summary.Email,
+		Subject: fmt.Sprintf("Resumen semanal de tu portafolio - %s", summary.PeriodEnd.Format("02/01/2006")),
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+// BuildUserSummary calcula el valor por grupo y la variación semanal de los holdings de un usuario
+func (ss *SummaryService) BuildUserSummary(userID string) (*UserSummary, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	var groups []models.Group
+	err := database.DB.
+		Preload("Type").
+		Preload("Holdings.Asset").
+		Where(&models.Group{UserID: user.ID}).
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo grupos del usuario: %w", err)
+	}
+
+	summary := &UserSummary{
+		UserID:    user.ID,
+		UserName:  user.Name,
+		Email:     user.Email,
+		PeriodEnd: time.Now().UTC(),
+	}
+
+	var allHoldings []HoldingSummary
+
+	for _, group := range groups {
+		groupSummary := GroupSummary{
+			GroupID:  group.ID,
+			Name:     group.Name,
+			Currency: group.Type.Currency,
+		}
+
+		for _, holding := range group.Holdings {
+			holdingSummary, ok := ss.buildHoldingSummary(&holding)
+			if !ok {
+				continue
+			}
+
+			groupSummary.TotalValue += holdingSummary.Value
+			groupSummary.PreviousValue += holdingSummary.PreviousValue
+			groupSummary.Holdings = append(groupSummary.Holdings, holdingSummary)
+			allHoldings = append(allHoldings, holdingSummary)
+		}
+
+		if len(groupSummary.Holdings) == 0 {
+			continue
+		}
+
+		groupSummary.Earnings = groupSummary.TotalValue - groupSummary.PreviousValue
+		if groupSummary.PreviousValue > 0 {
+			groupSummary.RelativeEarnings = (groupSummary.Earnings / groupSummary.PreviousValue) * 100
+		}
+
+		summary.Groups = append(summary.Groups, groupSummary)
+	}
+
+	// Mejor y peor holding según la variación relativa de la semana
+	if len(allHoldings) > 0 {
+		sort.Slice(allHoldings, func(i, j int) bool {
+			return allHoldings[i].RelativeEarnings > allHoldings[j].RelativeEarnings
+		})
+		summary.Best = &allHoldings[0]
+		summary.Worst = &allHoldings[len(allHoldings)-1]
+	}
+
+	return summary, nil
+}
+
+// Render genera las versiones HTML y texto plano del resumen
+func (ss *SummaryService) Render(summary *UserSummary) (string, string, error) {
+	var html, text bytes.Buffer
+
+	if err := ss.htmlTemplate.Execute(&html, summary); err != nil {
+		return "", "", fmt.Errorf("error renderizando template HTML: %w", err)
+	}
+
+	if err := ss.textTemplate.Execute(&text, summary); err != nil {
+		return "", "", fmt.Errorf("error renderizando template de texto: %w", err)
+	}
+
+	return html.String(), text.String(), nil
+}
+
+// GetPreference obtiene la preferencia de notificación de un usuario (o la default si no existe)
+func (ss *SummaryService) GetPreference(userID string) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	err := database.DB.Where("\"userId\" = ?", userID).Limit(1).Find(&preference).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo preferencias: %w", err)
+	}
+
+	if preference.ID == "" {
+		preference.UserID = userID
+		preference.WeeklySummary = config.AppConfig.WeeklySummaryDefaultOptIn
+	}
+
+	return &preference, nil
+}
+
+// UpdatePreference crea o actualiza la preferencia de resumen semanal de un usuario
+func (ss *SummaryService) UpdatePreference(userID string, weeklySummary bool) (*models.NotificationPreference, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	preference, err := ss.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preference.WeeklySummary = weeklySummary
+	preference.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(preference).Error; err != nil {
+		return nil, fmt.Errorf("error guardando preferencias: %w", err)
+	}
+
+	return preference, nil
+}
+
+// buildHoldingSummary calcula la variación del holding entre sus dos últimos snapshots
+func (ss *SummaryService) buildHoldingSummary(holding *models.Holding) (HoldingSummary, bool) {
+	var snapshots []models.Snapshot
+	err := database.DB.Where("\"holdingId\" = ?", holding.ID).
+		Order("\"createdAt\" DESC").
+		Limit(2).
+		Find(&snapshots).Error
+	if err != nil || len(snapshots) == 0 {
+		return HoldingSummary{}, false
+	}
+
+	latest := snapshots[0]
+	summary := HoldingSummary{
+		HoldingID: holding.ID,
+		Code:      holding.Asset.Code,
+		Name:      holding.Asset.Name,
+		Quantity:  latest.Quantity,
+		Price:     latest.Price,
+		Value:     latest.Price * latest.Quantity,
+	}
+
+	if len(snapshots) > 1 {
+		previous := snapshots[1]
+		summary.PreviousValue = previous.Price * previous.Quantity
+		summary.Earnings = summary.Value - summary.PreviousValue
+		if summary.PreviousValue > 0 {
+			summary.RelativeEarnings = (summary.Earnings / summary.PreviousValue) * 100
+		}
+	} else {
+		// Sin semana anterior la variación es cero
+		summary.PreviousValue = summary.Value
+	}
+
+	return summary, true
+}
+
+// getSubscribedUsers obtiene los usuarios con el resumen semanal habilitado
+func (ss *SummaryService) getSubscribedUsers() ([]models.User, error) {
+	var users []models.User
+
+	if config.AppConfig.WeeklySummaryDefaultOptIn {
+		// Todos los usuarios salvo los que lo desactivaron explícitamente
+		err := database.DB.
+			Where("id NOT IN (?)", database.DB.Model(&models.NotificationPreference{}).
+				Select("\"userId\"").Where("\"weeklySummary\" = ?", false)).
+			Find(&users).Error
+		return users, err
+	}
+
+	err := database.DB.
+		Where("id IN (?)", database.DB.Model(&models.NotificationPreference{}).
+			Select("\"userId\"").Where("\"weeklySummary\" = ?", true)).
+		Find(&users).Error
+	return users, err
+}
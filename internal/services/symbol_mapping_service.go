@@ -0,0 +1,243 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/internal/symbols"
+	"holding-snapshots/pkg/database"
+)
+
+// SymbolMappingRequest representa la creación de un mapeo de símbolo
+type SymbolMappingRequest struct {
+	TypeID   string `json:"typeId"`
+	Code     string `json:"code"`     // Código canónico (se normaliza)
+	Provider string `json:"provider"` // yahoo o alias
+	Symbol   string `json:"symbol"`   // Símbolo en el proveedor o alias de usuario
+}
+
+// NormalizedSymbol es el código canónico de una entrada y su símbolo en cada proveedor
+type NormalizedSymbol struct {
+	Input           string            `json:"input"`
+	Code            string            `json:"code"`
+	TypeID          string            `json:"typeId"`
+	ProviderSymbols map[string]string `json:"providerSymbols"`
+}
+
+type SymbolMappingService struct {
+	suffixes map[string]string // Tipo de inversión (nombre o ID) -> sufijo de mercado
+}
+
+// NewSymbolMappingService crea una nueva instancia del servicio de mapeo de símbolos
+func NewSymbolMappingService() *SymbolMappingService {
+	sms := &SymbolMappingService{suffixes: make(map[string]string)}
+
+	if config.AppConfig != nil {
+		for _, entry := range config.AppConfig.SymbolProviderSuffixes {
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+				log.Printf("⚠️ SYMBOL_PROVIDER_SUFFIXES: entrada inválida %q", entry)
+				continue
+			}
+			sms.suffixes[strings.TrimSpace(parts[0])] = strings.ToUpper(strings.TrimSpace(parts[1]))
+		}
+	}
+
+	return sms
+}
+
+// rules retorna las reglas de normalización del tipo de inversión
+func (sms *SymbolMappingService) rules(typeInvestment *models.TypeInvestment) symbols.Rules {
+	suffix, ok := sms.suffixes[typeInvestment.ID]
+	if !ok {
+		suffix = sms.suffixes[typeInvestment.Name]
+	}
+	return symbols.Rules{
+		Crypto: typeInvestment.Name == scraping.CryptoStrategyEnum,
+		Suffix: suffix,
+	}
+}
+
+// Normalize convierte una entrada de usuario al código canónico del tipo de inversión. Primero busca
+// la entrada (tal cual o normalizada) entre los símbolos mapeados; si no hay mapeo aplica las reglas.
+func (sms *SymbolMappingService) Normalize(typeInvestment *models.TypeInvestment, input string) string {
+	normalized := symbols.Normalize(input, sms.rules(typeInvestment))
+	if normalized == "" {
+		return ""
+	}
+
+	var mapping models.SymbolMapping
+	err := database.DB.Where("\"typeId\" = ? AND symbol IN ?", typeInvestment.ID,
+		[]string{strings.ToUpper(strings.TrimSpace(input)), normalized}).
+		Limit(1).Find(&mapping).Error
+	if err != nil {
+		log.Printf("⚠️ Error buscando mapeo de %s, se usan las reglas: %v", input, err)
+		return normalized
+	}
+	if mapping.ID != "" {
+		return mapping.Code
+	}
+
+	return normalized
+}
+
+// ProviderSymbol retorna el símbolo de un código en el proveedor: el mapeo explícito o el de las reglas
+func (sms *SymbolMappingService) ProviderSymbol(typeInvestment *models.TypeInvestment, code, provider string) string {
+	rules := sms.rules(typeInvestment)
+	canonical := sms.Normalize(typeInvestment, code)
+
+	var mapping models.SymbolMapping
+	err := database.DB.Where("\"typeId\" = ? AND code = ? AND provider = ?", typeInvestment.ID, canonical, provider).
+		Limit(1).Find(&mapping).Error
+	if err != nil {
+		log.Printf("⚠️ Error buscando símbolo de %s en %s, se usan las reglas: %v", code, provider, err)
+	} else if mapping.ID != "" {
+		return mapping.Symbol
+	}
+
+	return symbols.ProviderSymbol(canonical, provider, rules)
+}
+
+// StoredCodes retorna los códigos con los que puede estar guardado un asset: el canónico, sus formas
+// previas a la normalización (GGAL.BA, BRK-B) y los símbolos mapeados al código canónico
+func (sms *SymbolMappingService) StoredCodes(typeInvestment *models.TypeInvestment, code string) []string {
+	canonical := sms.Normalize(typeInvestment, code)
+	codes := symbols.StoredForms(canonical, sms.rules(typeInvestment))
+	if len(codes) == 0 {
+		return nil
+	}
+
+	var mapped []string
+	err := database.DB.Model(&models.SymbolMapping{}).
+		Where("\"typeId\" = ? AND code = ?", typeInvestment.ID, canonical).
+		Pluck("symbol", &mapped).Error
+	if err != nil {
+		log.Printf("⚠️ Error buscando símbolos mapeados de %s: %v", canonical, err)
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for _, stored := range codes {
+		seen[stored] = true
+	}
+	for _, symbol := range mapped {
+		if !seen[symbol] {
+			seen[symbol] = true
+			codes = append(codes, symbol)
+		}
+	}
+
+	return codes
+}
+
+// FindAsset busca el asset de un código en cualquiera de sus formas guardadas, prefiriendo el código
+// canónico y si no el más antiguo. Retorna nil si no existe.
+func (sms *SymbolMappingService) FindAsset(typeInvestment *models.TypeInvestment, code string) (*models.Asset, error) {
+	codes := sms.StoredCodes(typeInvestment, code)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	var assets []models.Asset
+	err := database.DB.Where("\"typeId\" = ? AND UPPER(code) IN ?", typeInvestment.ID, codes).
+		Order("\"createdAt\"").Find(&assets).Error
+	if err != nil {
+		return nil, fmt.Errorf("error buscando asset %s: %w", code, err)
+	}
+	if len(assets) == 0 {
+		return nil, nil
+	}
+
+	for i := range assets {
+		if strings.ToUpper(assets[i].Code) == codes[0] {
+			return &assets[i], nil
+		}
+	}
+	return &assets[0], nil
+}
+
+// Resolve normaliza una entrada y retorna sus símbolos en los proveedores conocidos
+func (sms *SymbolMappingService) Resolve(typeID, input string) (*NormalizedSymbol, error) {
+	var typeInvestment models.TypeInvestment
+	if err := database.DB.First(&typeInvestment, "id = ?", typeID).Error; err != nil {
+		return nil, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+	}
+
+	code := sms.Normalize(&typeInvestment, input)
+	if code == "" {
+		return nil, fmt.Errorf("código vacío")
+	}
+
+	return &NormalizedSymbol{
+		Input:  input,
+		Code:   code,
+		TypeID: typeID,
+		ProviderSymbols: map[string]string{
+			symbols.ProviderYahoo: sms.ProviderSymbol(&typeInvestment, code, symbols.ProviderYahoo),
+		},
+	}, nil
+}
+
+// GetMappings obtiene los mapeos, opcionalmente de un tipo de inversión y código canónico
+func (sms *SymbolMappingService) GetMappings(typeID, code string) ([]models.SymbolMapping, error) {
+	db := database.DB.Model(&models.SymbolMapping{})
+	if typeID != "" {
+		db = db.Where("\"typeId\" = ?", typeID)
+	}
+	if code != "" {
+		db = db.Where("code = ?", strings.ToUpper(strings.TrimSpace(code)))
+	}
+
+	var mappings []models.SymbolMapping
+	if err := db.Order("code, provider, symbol").Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo mapeos de símbolos: %w", err)
+	}
+	return mappings, nil
+}
+
+// CreateMapping registra un mapeo; el código se guarda normalizado y el símbolo en mayúsculas
+func (sms *SymbolMappingService) CreateMapping(req SymbolMappingRequest) (*models.SymbolMapping, error) {
+	var typeInvestment models.TypeInvestment
+	if err := database.DB.First(&typeInvestment, "id = ?", req.TypeID).Error; err != nil {
+		return nil, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	if provider != symbols.ProviderYahoo && provider != models.SymbolMappingProviderAlias {
+		return nil, fmt.Errorf("proveedor inválido: %s (%s o %s)", req.Provider, symbols.ProviderYahoo, models.SymbolMappingProviderAlias)
+	}
+
+	code := symbols.Normalize(req.Code, sms.rules(&typeInvestment))
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if code == "" || symbol == "" {
+		return nil, fmt.Errorf("el código y el símbolo son requeridos")
+	}
+
+	mapping := &models.SymbolMapping{
+		TypeID:   typeInvestment.ID,
+		Code:     code,
+		Provider: provider,
+		Symbol:   symbol,
+	}
+	if err := database.DB.Create(mapping).Error; err != nil {
+		return nil, fmt.Errorf("error creando mapeo de símbolo: %w", err)
+	}
+
+	log.Printf("🔗 Mapeo de símbolo creado: %s %s -> %s (%s)", provider, symbol, code, typeInvestment.Name)
+	return mapping, nil
+}
+
+// DeleteMapping elimina un mapeo
+func (sms *SymbolMappingService) DeleteMapping(id string) error {
+	result := database.DB.Delete(&models.SymbolMapping{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("error eliminando mapeo de símbolo: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("mapeo de símbolo no encontrado")
+	}
+	return nil
+}
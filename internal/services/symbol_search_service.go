@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/pkg/cache"
+	"holding-snapshots/pkg/database"
+)
+
+// Orígenes de un símbolo encontrado
+const (
+	SymbolSourceAsset    = "asset"    // Asset ya registrado en el servicio
+	SymbolSourceProvider = "provider" // Resultado del proveedor de búsqueda
+)
+
+// SymbolMatch es un ticker candidato para una búsqueda o sugerencia
+type SymbolMatch struct {
+	scraping.SymbolCandidate
+	Source  string `json:"source"`
+	AssetID string `json:"assetId,omitempty"`
+}
+
+// typeQuoteTypes son los tipos de cotización del proveedor que corresponden a cada tipo de inversión
+var typeQuoteTypes = map[string][]string{
+	scraping.CryptoStrategyEnum:  {"CRYPTOCURRENCY"},
+	scraping.StockStrategyEnum:   {"EQUITY", "ETF"},
+	scraping.CedearsStrategyEnum: {"EQUITY", "ETF"},
+}
+
+type SymbolSearchService struct {
+	provider scraping.SymbolSearchProvider
+}
+
+// NewSymbolSearchService crea una nueva instancia del servicio de búsqueda de símbolos
+func NewSymbolSearchService() *SymbolSearchService {
+	providerURL := "https://query1.finance.yahoo.com/v1/finance/search"
+	if config.AppConfig != nil && config.AppConfig.SymbolSearchProviderURL != "" {
+		providerURL = config.AppConfig.SymbolSearchProviderURL
+	}
+
+	return &SymbolSearchService{
+		provider: scraping.NewYahooSearchProvider(providerURL),
+	}
+}
+
+// Search busca tickers por código o nombre en los assets registrados y en el proveedor.
+// Con typeID solo se devuelven candidatos de ese tipo de inversión. Si el proveedor falla
+// se devuelven solo los assets registrados.
+func (ss *SymbolSearchService) Search(query, typeID string, limit int) ([]SymbolMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("el texto de búsqueda es requerido")
+	}
+
+	var typeInvestment *models.TypeInvestment
+	if typeID != "" {
+		typeInvestment = &models.TypeInvestment{}
+		if err := database.DB.First(typeInvestment, "id = ?", typeID).Error; err != nil {
+			return nil, fmt.Errorf("tipo de inversión no encontrado: %w", err)
+		}
+	}
+
+	matches, err := ss.searchAssets(query, typeInvestment, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		seen[match.Symbol] = true
+	}
+
+	candidates, err := ss.searchProvider(query, limit)
+	if err != nil {
+		log.Printf("⚠️ Error buscando símbolos en el proveedor, solo se usan los assets registrados: %v", err)
+		return matches, nil
+	}
+
+	for _, candidate := range candidates {
+		if len(matches) >= limit {
+			break
+		}
+		if seen[strings.ToUpper(candidate.Symbol)] || !matchesType(candidate, typeInvestment) {
+			continue
+		}
+		if candidate.Currency == "" && typeInvestment != nil {
+			candidate.Currency = typeInvestment.Currency
+		}
+		seen[strings.ToUpper(candidate.Symbol)] = true
+		matches = append(matches, SymbolMatch{SymbolCandidate: candidate, Source: SymbolSourceProvider})
+	}
+
+	return matches, nil
+}
+
+// Suggest retorna candidatos para un código que no se pudo validar, sin el código mismo
+func (ss *SymbolSearchService) Suggest(typeID, code string, limit int) []SymbolMatch {
+	matches, err := ss.Search(code, typeID, limit+1)
+	if err != nil {
+		log.Printf("⚠️ No se pudieron obtener sugerencias para %s: %v", code, err)
+		return []SymbolMatch{}
+	}
+
+	suggestions := make([]SymbolMatch, 0, limit)
+	for _, match := range matches {
+		if strings.EqualFold(match.Symbol, strings.TrimSpace(code)) || len(suggestions) >= limit {
+			continue
+		}
+		suggestions = append(suggestions, match)
+	}
+	return suggestions
+}
+
+// LookupName obtiene del proveedor el nombre del ticker exacto; si no lo encuentra retorna el código
+func (ss *SymbolSearchService) LookupName(code string) string {
+	candidates, err := ss.searchProvider(code, 5)
+	if err != nil {
+		log.Printf("⚠️ No se pudo obtener el nombre de %s del proveedor: %v", code, err)
+		return code
+	}
+
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate.Symbol, code) && candidate.Name != "" {
+			return candidate.Name
+		}
+	}
+	return code
+}
+
+// searchAssets busca en los assets registrados por prefijo de código o parte del nombre
+func (ss *SymbolSearchService) searchAssets(query string, typeInvestment *models.TypeInvestment, limit int) ([]SymbolMatch, error) {
+	pattern := strings.NewReplacer("%", "\\%", "_", "\\_").Replace(query)
+
+	db := database.DB.Preload("Type").
+		Where("(code ILIKE ? OR name ILIKE ?)", pattern+"%", "%"+pattern+"%").
+		Where("is_valid = ?", true)
+	if typeInvestment != nil {
+		db = db.Where("\"typeId\" = ?", typeInvestment.ID)
+	}
+
+	var assets []models.Asset
+	if err := db.Order("code").Limit(limit).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("error buscando assets: %w", err)
+	}
+
+	matches := make([]SymbolMatch, 0, len(assets))
+	for _, asset := range assets {
+		matches = append(matches, SymbolMatch{
+			SymbolCandidate: scraping.SymbolCandidate{
+				Symbol:   strings.ToUpper(asset.Code),
+				Name:     asset.Name,
+				Currency: asset.Type.Currency,
+			},
+			Source:  SymbolSourceAsset,
+			AssetID: asset.ID,
+		})
+	}
+	return matches, nil
+}
+
+// searchProvider consulta el proveedor con cache en Redis; los errores de Redis se ignoran
+func (ss *SymbolSearchService) searchProvider(query string, limit int) ([]scraping.SymbolCandidate, error) {
+	key := fmt.Sprintf("symbol_search:%s:%d", strings.ToLower(query), limit)
+
+	if cache.GetClient() != nil {
+		if data, err := cache.Get(context.Background(), key); err == nil {
+			var candidates []scraping.SymbolCandidate
+			if json.Unmarshal([]byte(data), &candidates) == nil {
+				return candidates, nil
+			}
+		}
+	}
+
+	waitScrapingTurn()
+	candidates, err := ss.provider.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache.GetClient() != nil {
+		if data, err := json.Marshal(candidates); err == nil {
+			if err := cache.Set(context.Background(), key, string(data), ss.cacheTTL()); err != nil {
+				log.Printf("⚠️ Error guardando búsqueda de símbolos en cache: %v", err)
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+// cacheTTL retorna cuánto se cachean los resultados del proveedor
+func (ss *SymbolSearchService) cacheTTL() time.Duration {
+	if config.AppConfig != nil && config.AppConfig.SymbolSearchCacheTTLMinutes > 0 {
+		return time.Duration(config.AppConfig.SymbolSearchCacheTTLMinutes) * time.Minute
+	}
+	return time.Hour
+}
+
+// matchesType indica si el candidato corresponde al tipo de inversión; sin tipo o con un tipo desconocido acepta todos
+func matchesType(candidate scraping.SymbolCandidate, typeInvestment *models.TypeInvestment) bool {
+	if typeInvestment == nil {
+		return true
+	}
+	quoteTypes, ok := typeQuoteTypes[typeInvestment.Name]
+	if !ok {
+		return true
+	}
+	for _, quoteType := range quoteTypes {
+		if strings.EqualFold(candidate.QuoteType, quoteType) {
+			return true
+		}
+	}
+	return false
+}
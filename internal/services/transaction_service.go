@@ -0,0 +1,293 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"holding-snapshots/internal/ledger"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CostBasisReport es la posición de un holding con sus ganancias desde el inicio
+type CostBasisReport struct {
+	HoldingID        string           `json:"holdingId"`
+	Code             string           `json:"code"`
+	Currency         string           `json:"currency"`
+	Method           string           `json:"method"`
+	MarketPrice      float64          `json:"marketPrice"`
+	MarketValue      float64          `json:"marketValue"`
+	CostBasis        float64          `json:"costBasis"`
+	UnrealizedPnL    float64          `json:"unrealizedPnL"`
+	RealizedPnL      float64          `json:"realizedPnL"`
+	Earnings         float64          `json:"earnings"`         // Realizado + no realizado desde la primera transacción
+	RelativeEarnings float64          `json:"relativeEarnings"` // Earnings sobre el total invertido, en %
+	Dividends        float64          `json:"dividends"`        // Dividendos cobrados, no incluidos en earnings
+	Position         *ledger.Position `json:"position"`
+}
+
+type TransactionService struct{}
+
+// NewTransactionService crea una nueva instancia del servicio de transacciones
+func NewTransactionService() *TransactionService {
+	return &TransactionService{}
+}
+
+// GetHoldingTransactions obtiene las transacciones de un holding en orden cronológico
+func (ts *TransactionService) GetHoldingTransactions(holdingID string) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := database.DB.Where("\"holdingId\" = ?", holdingID).
+		Order("date ASC, \"createdAt\" ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo transacciones: %w", err)
+	}
+	return transactions, nil
+}
+
+// CreateTransaction valida y registra una transacción, recalculando la cantidad del holding
+func (ts *TransactionService) CreateTransaction(transaction *models.Transaction) error {
+	holding, err := ts.getHoldingWithCurrency(transaction.HoldingID)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.validateTransaction(transaction, holding.Group.Type.Currency); err != nil {
+		return err
+	}
+
+	transaction.CreatedAt = time.Now()
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// El bloqueo del holding serializa las transacciones concurrentes: dos ventas no pueden
+		// validarse contra el mismo historial
+		locked, err := ts.lockHolding(tx, holding.ID)
+		if err != nil {
+			return err
+		}
+
+		var existing []models.Transaction
+		if err := tx.Where("\"holdingId\" = ?", holding.ID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("error obteniendo transacciones: %w", err)
+		}
+
+		// La primera transacción de un holding con cantidad cargada parte de esa cantidad
+		if len(existing) == 0 && locked.Quantity > 0 {
+			opening, err := ts.openingBalance(tx, locked, transaction)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(opening).Error; err != nil {
+				return fmt.Errorf("error creando saldo de apertura: %w", err)
+			}
+			existing = append(existing, *opening)
+		}
+
+		position, err := ledger.BuildPosition(append(existing, *transaction))
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(transaction).Error; err != nil {
+			return fmt.Errorf("error creando transacción: %w", err)
+		}
+
+		return ts.syncHoldingQuantity(tx, holding.ID, position.Quantity)
+	})
+}
+
+// DeleteTransaction elimina una transacción si el historial resultante sigue siendo válido
+func (ts *TransactionService) DeleteTransaction(transactionID string) error {
+	var transaction models.Transaction
+	if err := database.DB.First(&transaction, "id = ?", transactionID).Error; err != nil {
+		return fmt.Errorf("transacción no encontrada: %w", err)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := ts.lockHolding(tx, transaction.HoldingID); err != nil {
+			return err
+		}
+
+		var remaining []models.Transaction
+		err := tx.Where("\"holdingId\" = ? AND id <> ?", transaction.HoldingID, transaction.ID).
+			Find(&remaining).Error
+		if err != nil {
+			return fmt.Errorf("error obteniendo transacciones: %w", err)
+		}
+
+		position, err := ledger.BuildPosition(remaining)
+		if err != nil {
+			return fmt.Errorf("no se puede eliminar la transacción: %w", err)
+		}
+
+		if err := tx.Delete(&transaction).Error; err != nil {
+			return fmt.Errorf("error eliminando transacción: %w", err)
+		}
+
+		return ts.syncHoldingQuantity(tx, transaction.HoldingID, position.Quantity)
+	})
+}
+
+// GetCostBasis calcula la posición y las ganancias desde el inicio de un holding
+func (ts *TransactionService) GetCostBasis(holdingID, method string) (*CostBasisReport, error) {
+	if !ledger.IsValidMethod(method) {
+		return nil, fmt.Errorf("método de costo inválido: %s (average o fifo)", method)
+	}
+
+	holding, err := ts.getHoldingWithCurrency(holdingID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := ts.GetHoldingTransactions(holdingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("el holding no tiene transacciones registradas")
+	}
+
+	position, err := ledger.BuildPosition(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	marketPrice := holding.Asset.LastPrice
+	report := &CostBasisReport{
+		HoldingID:     holding.ID,
+		Code:          holding.Asset.Code,
+		Currency:      holding.Group.Type.Currency,
+		Method:        method,
+		MarketPrice:   marketPrice,
+		MarketValue:   position.Quantity * marketPrice,
+		CostBasis:     position.CostBasis(method),
+		UnrealizedPnL: position.UnrealizedPnL(marketPrice, method),
+		RealizedPnL:   position.RealizedPnL(method),
+		Position:      position,
+	}
+
+	report.Earnings = report.UnrealizedPnL + report.RealizedPnL
+	if position.TotalInvested > 0 {
+		report.RelativeEarnings = (report.Earnings / position.TotalInvested) * 100
+	}
+
+	err = database.DB.Model(&models.DividendIncome{}).
+		Where("\"holdingId\" = ?", holding.ID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&report.Dividends).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo dividendos: %w", err)
+	}
+
+	return report, nil
+}
+
+// validateTransaction valida tipo, importes y moneda de la transacción
+func (ts *TransactionService) validateTransaction(transaction *models.Transaction, groupCurrency string) error {
+	switch transaction.Type {
+	case models.TransactionBuy, models.TransactionSell,
+		models.TransactionTransferIn, models.TransactionTransferOut:
+	default:
+		return fmt.Errorf("tipo de transacción inválido: %s", transaction.Type)
+	}
+
+	if transaction.Quantity <= 0 {
+		return fmt.Errorf("la cantidad debe ser mayor a 0")
+	}
+
+	if transaction.Price < 0 || transaction.Fees < 0 {
+		return fmt.Errorf("precio y comisiones no pueden ser negativos")
+	}
+
+	if (transaction.Type == models.TransactionBuy || transaction.Type == models.TransactionSell) && transaction.Price == 0 {
+		return fmt.Errorf("las compras y ventas requieren precio")
+	}
+
+	if transaction.Date.IsZero() {
+		return fmt.Errorf("la fecha de la transacción es requerida")
+	}
+
+	if transaction.Date.After(time.Now()) {
+		return fmt.Errorf("la fecha de la transacción no puede ser futura")
+	}
+
+	if transaction.Currency == "" {
+		transaction.Currency = groupCurrency
+	}
+
+	if transaction.Currency != groupCurrency {
+		return fmt.Errorf("la moneda de la transacción (%s) no coincide con la del grupo (%s)",
+			transaction.Currency, groupCurrency)
+	}
+
+	return nil
+}
+
+// getHoldingWithCurrency obtiene el holding con su asset y el tipo de inversión del grupo
+func (ts *TransactionService) getHoldingWithCurrency(holdingID string) (*models.Holding, error) {
+	var holding models.Holding
+	err := database.DB.Preload("Asset").Preload("Group.Type").First(&holding, "id = ?", holdingID).Error
+	if err != nil {
+		return nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+	return &holding, nil
+}
+
+// lockHolding bloquea el holding hasta el fin de la transacción y lo retorna con su cantidad actual
+func (ts *TransactionService) lockHolding(tx *gorm.DB, holdingID string) (*models.Holding, error) {
+	var holding models.Holding
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&holding, "id = ?", holdingID).Error; err != nil {
+		return nil, fmt.Errorf("holding no encontrado: %w", err)
+	}
+	return &holding, nil
+}
+
+// openingBalance arma la transferencia entrante con la cantidad que el holding tenía antes de su
+// primera transacción, para que esa cantidad no se pierda al derivarla del historial. Se registra con
+// la fecha de la primera transacción (y antes que ella) al precio del último snapshot hasta esa fecha,
+// o al lastPrice del asset si no hay snapshots: el costo real de esas unidades no se conoce.
+func (ts *TransactionService) openingBalance(tx *gorm.DB, holding *models.Holding, first *models.Transaction) (*models.Transaction, error) {
+	var snapshot models.Snapshot
+	err := tx.Where("\"holdingId\" = ? AND \"createdAt\" <= ?", holding.ID, first.Date).
+		Order("\"createdAt\" DESC").Limit(1).Find(&snapshot).Error
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo snapshot: %w", err)
+	}
+
+	price := snapshot.Price
+	if snapshot.ID == "" {
+		var asset models.Asset
+		if err := tx.Select("\"lastPrice\"").First(&asset, "id = ?", holding.AssetID).Error; err != nil {
+			return nil, fmt.Errorf("asset no encontrado: %w", err)
+		}
+		price = asset.LastPrice
+	}
+
+	log.Printf("📒 Saldo de apertura del holding %s: %f unidades a %f", holding.ID, holding.Quantity, price)
+
+	return &models.Transaction{
+		HoldingID: holding.ID,
+		Type:      models.TransactionTransferIn,
+		Date:      first.Date,
+		Quantity:  holding.Quantity,
+		Price:     price,
+		Currency:  first.Currency,
+		Notes:     "Saldo de apertura: cantidad del holding antes de registrar transacciones",
+		CreatedAt: first.CreatedAt.Add(-time.Millisecond),
+	}, nil
+}
+
+// syncHoldingQuantity actualiza Holding.Quantity con la cantidad derivada de las transacciones
+func (ts *TransactionService) syncHoldingQuantity(tx *gorm.DB, holdingID string, quantity float64) error {
+	err := tx.Model(&models.Holding{}).Where("id = ?", holdingID).Update("quantity", quantity).Error
+	if err != nil {
+		return fmt.Errorf("error actualizando cantidad del holding: %w", err)
+	}
+
+	log.Printf("📒 Cantidad del holding %s recalculada desde transacciones: %f", holdingID, quantity)
+	return nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/internal/scraping"
+	"holding-snapshots/pkg/cache"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// validationCachePrefix es el prefijo de las claves validated_holding:<tipo>:<código>
+const validationCachePrefix = "validated_holding"
+
+// ErrValidationCacheUnavailable indica que Redis no está disponible
+var ErrValidationCacheUnavailable = errors.New("cache de validaciones no disponible")
+
+// ValidatedHoldingCache es el resultado de una validación guardado en Redis
+type ValidatedHoldingCache struct {
+	Code     string    `json:"code"`
+	TypeID   string    `json:"typeId"`
+	TypeName string    `json:"typeName"`
+	Valid    bool      `json:"valid"`
+	Price    float64   `json:"price,omitempty"`
+	Currency string    `json:"currency,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	CachedAt time.Time `json:"cachedAt"`
+}
+
+// ValidationCacheEntry es una entrada del cache con su tiempo de vida restante
+type ValidationCacheEntry struct {
+	Key        string                `json:"key"`
+	TTLSeconds int64                 `json:"ttlSeconds"`
+	Value      ValidatedHoldingCache `json:"value"`
+}
+
+// ValidationCacheStats son los contadores del cache desde que arrancó el proceso
+type ValidationCacheStats struct {
+	Available       bool      `json:"available"`
+	Hits            int64     `json:"hits"`
+	Misses          int64     `json:"misses"`
+	Errors          int64     `json:"errors"` // Lecturas o escrituras fallidas contra Redis
+	Writes          int64     `json:"writes"`
+	HitRate         float64   `json:"hitRate"`
+	Entries         int       `json:"entries"`
+	ValidTTLHours   int       `json:"validTtlHours"`
+	InvalidTTLHours int       `json:"invalidTtlHours"`
+	Since           time.Time `json:"since"`
+}
+
+// Contadores del cache de validaciones, compartidos por todas las instancias del servicio
+var (
+	validationCacheHits   int64
+	validationCacheMisses int64
+	validationCacheErrors int64
+	validationCacheWrites int64
+	validationCacheSince  = time.Now()
+)
+
+// validationCacheKey arma la clave de cache de un código; el código se normaliza a mayúsculas
+func validationCacheKey(typeID, code string) string {
+	return fmt.Sprintf("%s:%s:%s", validationCachePrefix, typeID, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// validationCacheTTLs retorna los TTL de resultados válidos e inválidos
+func validationCacheTTLs() (time.Duration, time.Duration) {
+	validTTL, invalidTTL := 24*time.Hour, 2*time.Hour
+	if config.AppConfig != nil {
+		if config.AppConfig.ValidationCacheValidTTLHours > 0 {
+			validTTL = time.Duration(config.AppConfig.ValidationCacheValidTTLHours) * time.Hour
+		}
+		if config.AppConfig.ValidationCacheInvalidTTLHours > 0 {
+			invalidTTL = time.Duration(config.AppConfig.ValidationCacheInvalidTTLHours) * time.Hour
+		}
+	}
+	return validTTL, invalidTTL
+}
+
+// validateCode valida un código usando el cache y, si no está, scrapeando con la pausa mínima entre requests.
+// El código se normaliza al canónico del tipo de inversión, así las variantes comparten la entrada de cache.
+// Solo se cachean los precios obtenidos y los códigos para los que el proveedor no publica precio.
+// Si Redis no responde se valida igual por scraping. Retorna si el resultado vino del cache.
+func (s *ScrapingService) validateCode(typeInvestment *models.TypeInvestment, code string) (float64, bool, error) {
+	code = s.symbolMappingService.Normalize(typeInvestment, code)
+	if code == "" {
+		return 0, false, fmt.Errorf("código vacío")
+	}
+	key := validationCacheKey(typeInvestment.ID, code)
+
+	if entry, ok := getValidationCache(key); ok {
+		log.Printf("📦 Validación de %s encontrada en cache para tipo %s - Válido: %v", code, typeInvestment.Name, entry.Valid)
+		if !entry.Valid {
+			return 0, true, errors.New(entry.Error)
+		}
+		return entry.Price, true, nil
+	}
+
+	waitScrapingTurn()
+	price, err := s.FetchAssetPrice(typeInvestment, code)
+
+	// Timeouts, errores de red o del proveedor no dicen nada del código: no se cachean
+	if err != nil && !errors.Is(err, scraping.ErrPriceNotFound) {
+		log.Printf("⚠️ Validación de %s no cacheada por error transitorio: %v", code, err)
+		return 0, false, err
+	}
+
+	entry := ValidatedHoldingCache{
+		Code:     strings.ToUpper(strings.TrimSpace(code)),
+		TypeID:   typeInvestment.ID,
+		TypeName: typeInvestment.Name,
+		Valid:    err == nil && price > 0,
+		Currency: typeInvestment.Currency,
+		CachedAt: time.Now().UTC(),
+	}
+	switch {
+	case err != nil:
+		entry.Error = err.Error()
+	case price <= 0:
+		entry.Error = "precio no disponible"
+	default:
+		entry.Price = price
+	}
+	setValidationCache(key, entry)
+
+	return price, false, err
+}
+
+// getValidationCache lee una validación del cache; cualquier error de Redis se trata como miss
+func getValidationCache(key string) (*ValidatedHoldingCache, bool) {
+	if cache.GetClient() == nil {
+		atomic.AddInt64(&validationCacheMisses, 1)
+		return nil, false
+	}
+
+	data, err := cache.Get(context.Background(), key)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			atomic.AddInt64(&validationCacheErrors, 1)
+			log.Printf("⚠️ Error leyendo cache de validación %s: %v", key, err)
+		}
+		atomic.AddInt64(&validationCacheMisses, 1)
+		return nil, false
+	}
+
+	var entry ValidatedHoldingCache
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		atomic.AddInt64(&validationCacheMisses, 1)
+		return nil, false
+	}
+
+	atomic.AddInt64(&validationCacheHits, 1)
+	return &entry, true
+}
+
+// setValidationCache guarda una validación con el TTL según sea válida o no; los errores solo se registran
+func setValidationCache(key string, entry ValidatedHoldingCache) {
+	if cache.GetClient() == nil {
+		return
+	}
+
+	validTTL, invalidTTL := validationCacheTTLs()
+	ttl := invalidTTL
+	if entry.Valid {
+		ttl = validTTL
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := cache.Set(context.Background(), key, string(data), ttl); err != nil {
+		atomic.AddInt64(&validationCacheErrors, 1)
+		log.Printf("⚠️ Error guardando validación en cache: %v", err)
+		return
+	}
+	atomic.AddInt64(&validationCacheWrites, 1)
+	log.Printf("💾 Validación guardada en cache: %s (válido: %v, TTL: %v)", key, entry.Valid, ttl)
+}
+
+// validationCachePattern arma el patrón de búsqueda; typeID o code vacíos matchean cualquiera
+func validationCachePattern(typeID, code string) string {
+	if typeID == "" {
+		typeID = "*"
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		code = "*"
+	}
+	return fmt.Sprintf("%s:%s:%s", validationCachePrefix, typeID, code)
+}
+
+// GetValidationCacheEntries lista las validaciones cacheadas, opcionalmente por tipo de inversión y código
+func (s *ScrapingService) GetValidationCacheEntries(typeID, code string, limit int) ([]ValidationCacheEntry, error) {
+	if cache.GetClient() == nil {
+		return nil, ErrValidationCacheUnavailable
+	}
+
+	ctx := context.Background()
+	keys, err := cache.ScanKeys(ctx, validationCachePattern(typeID, code), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidationCacheUnavailable, err)
+	}
+
+	entries := make([]ValidationCacheEntry, 0, len(keys))
+	for _, key := range keys {
+		data, err := cache.Get(ctx, key)
+		if err != nil {
+			continue // Expiró entre el SCAN y la lectura
+		}
+
+		entry := ValidationCacheEntry{Key: key}
+		if err := json.Unmarshal([]byte(data), &entry.Value); err != nil {
+			continue
+		}
+		if ttl, err := cache.TTL(ctx, key); err == nil {
+			entry.TTLSeconds = int64(ttl.Seconds())
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// PurgeValidationCache elimina las validaciones cacheadas que coinciden; sin filtros elimina todas
+func (s *ScrapingService) PurgeValidationCache(typeID, code string) (int, error) {
+	if cache.GetClient() == nil {
+		return 0, ErrValidationCacheUnavailable
+	}
+
+	ctx := context.Background()
+	keys, err := cache.ScanKeys(ctx, validationCachePattern(typeID, code), 0)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrValidationCacheUnavailable, err)
+	}
+
+	for start := 0; start < len(keys); start += 500 {
+		end := start + 500
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := cache.Delete(ctx, keys[start:end]...); err != nil {
+			return start, fmt.Errorf("error eliminando validaciones del cache: %w", err)
+		}
+	}
+
+	log.Printf("🗑️ %d validaciones eliminadas del cache (%s)", len(keys), validationCachePattern(typeID, code))
+	return len(keys), nil
+}
+
+// GetValidationCacheStats retorna los contadores de hits y misses y la cantidad de entradas
+func (s *ScrapingService) GetValidationCacheStats() ValidationCacheStats {
+	validTTL, invalidTTL := validationCacheTTLs()
+	stats := ValidationCacheStats{
+		Hits:            atomic.LoadInt64(&validationCacheHits),
+		Misses:          atomic.LoadInt64(&validationCacheMisses),
+		Errors:          atomic.LoadInt64(&validationCacheErrors),
+		Writes:          atomic.LoadInt64(&validationCacheWrites),
+		ValidTTLHours:   int(validTTL.Hours()),
+		InvalidTTLHours: int(invalidTTL.Hours()),
+		Since:           validationCacheSince.UTC(),
+	}
+
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+
+	if cache.GetClient() != nil {
+		if keys, err := cache.ScanKeys(context.Background(), validationCachePattern("", ""), 0); err == nil {
+			stats.Available = true
+			stats.Entries = len(keys)
+		}
+	}
+
+	return stats
+}
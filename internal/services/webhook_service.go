@@ -0,0 +1,314 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+	"holding-snapshots/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+// Eventos que pueden notificarse por webhook
+const (
+	EventRunStarted       = "run.started"
+	EventRunCompleted     = "run.completed"
+	EventRunFailed        = "run.failed"
+	EventAssetInvalidated = "asset.invalidated"
+	EventPriceAnomaly     = "price.anomaly"
+	EventAlertTriggered   = "alert.triggered"
+)
+
+// WebhookPayload es el cuerpo JSON que recibe cada endpoint configurado
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookService struct {
+	client      *http.Client
+	baseBackoff time.Duration                       // Espera antes del primer reintento; se duplica en cada uno
+	save        func(*models.WebhookDelivery) error // Persiste el estado de una entrega
+}
+
+// NewWebhookService crea una nueva instancia del servicio de webhooks
+func NewWebhookService() *WebhookService {
+	timeout := 10 * time.Second
+	if config.AppConfig != nil && config.AppConfig.WebhookTimeoutSeconds > 0 {
+		timeout = time.Duration(config.AppConfig.WebhookTimeoutSeconds) * time.Second
+	}
+
+	return &WebhookService{
+		client:      &http.Client{Timeout: timeout},
+		baseBackoff: time.Second,
+		save: func(delivery *models.WebhookDelivery) error {
+			return database.DB.Save(delivery).Error
+		},
+	}
+}
+
+// Errores por los que Dispatch no encola ninguna entrega
+var (
+	ErrWebhooksNotConfigured = errors.New("no hay webhooks configurados")
+	ErrWebhookEventDisabled  = errors.New("evento de webhook deshabilitado")
+)
+
+// Dispatch registra y envía en segundo plano un evento a todos los endpoints configurados.
+// Retorna un error si no se encoló ninguna entrega.
+func (ws *WebhookService) Dispatch(event string, data interface{}) error {
+	if config.AppConfig == nil || len(config.AppConfig.WebhookURLs) == 0 {
+		return ErrWebhooksNotConfigured
+	}
+
+	// Sin secreto los receptores no pueden distinguir un payload legítimo de uno falsificado
+	if config.AppConfig.WebhookSecret == "" {
+		log.Printf("⚠️ Webhook %s no enviado: WEBHOOK_SECRET no está configurado", event)
+		return fmt.Errorf("%w: falta WEBHOOK_SECRET", ErrWebhooksNotConfigured)
+	}
+
+	if !ws.isEventEnabled(event) {
+		return fmt.Errorf("%w: %s", ErrWebhookEventDisabled, event)
+	}
+
+	queued := 0
+	var lastErr error
+	for _, url := range config.AppConfig.WebhookURLs {
+		delivery, err := ws.createDelivery(event, url, data, nil)
+		if err != nil {
+			log.Printf("⚠️ Error registrando webhook %s para %s: %v", event, url, err)
+			lastErr = err
+			continue
+		}
+
+		go ws.deliverWithRetries(delivery)
+		queued++
+	}
+
+	if queued == 0 {
+		return fmt.Errorf("no se encoló ninguna entrega del webhook %s: %w", event, lastErr)
+	}
+	return nil
+}
+
+// ReplayDelivery reenvía el payload de una entrega existente como una nueva entrega
+func (ws *WebhookService) ReplayDelivery(id string) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	err := database.DB.First(&original, "id = ?", id).Error
+	if err != nil {
+		return nil, fmt.Errorf("entrega de webhook no encontrada: %w", err)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal([]byte(original.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("error deserializando payload original: %w", err)
+	}
+
+	delivery, err := ws.createDelivery(original.Event, original.URL, payload.Data, &original.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔁 Reenviando webhook %s (%s) como entrega %s", original.ID, original.Event, delivery.ID)
+	go ws.deliverWithRetries(delivery)
+
+	return delivery, nil
+}
+
+// ResumePending reanuda las entregas que quedaron pendientes o en reintento cuando el servicio se
+// detuvo. Cada una retoma desde sus intentos registrados y espera hasta su nextAttemptAt.
+func (ws *WebhookService) ResumePending() (int, error) {
+	if config.AppConfig == nil || len(config.AppConfig.WebhookURLs) == 0 {
+		return 0, nil
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := database.DB.Where("status IN ?", []string{models.WebhookStatusPending, models.WebhookStatusRetrying}).
+		Order("\"createdAt\" ASC").Find(&deliveries).Error
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo entregas de webhooks pendientes: %w", err)
+	}
+
+	for i := range deliveries {
+		go ws.deliverWithRetries(&deliveries[i])
+	}
+
+	if len(deliveries) > 0 {
+		log.Printf("🔁 %d entregas de webhooks reanudadas", len(deliveries))
+	}
+	return len(deliveries), nil
+}
+
+// GetDeliveries obtiene las entregas más recientes, opcionalmente filtradas por estado y evento
+func (ws *WebhookService) GetDeliveries(status, event string, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	query := database.DB.Order("\"createdAt\" DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo entregas de webhooks: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// isEventEnabled verifica si el evento está habilitado en WEBHOOK_EVENTS
+func (ws *WebhookService) isEventEnabled(event string) bool {
+	if len(config.AppConfig.WebhookEvents) == 0 {
+		return true
+	}
+
+	for _, enabled := range config.AppConfig.WebhookEvents {
+		if enabled == event {
+			return true
+		}
+	}
+	return false
+}
+
+// createDelivery serializa el payload y crea el registro de entrega pendiente
+func (ws *WebhookService) createDelivery(event, url string, data interface{}, replayOfID *string) (*models.WebhookDelivery, error) {
+	// El ID se genera antes de crear para incluirlo en el payload
+	delivery := &models.WebhookDelivery{
+		ID:         uuid.New().String(),
+		Event:      event,
+		URL:        url,
+		Status:     models.WebhookStatusPending,
+		ReplayOfID: replayOfID,
+		CreatedAt:  time.Now(),
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		ID:        delivery.ID,
+		Event:     event,
+		CreatedAt: delivery.CreatedAt.UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error serializando payload: %w", err)
+	}
+	delivery.Payload = string(body)
+
+	if err := database.DB.Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("error guardando entrega de webhook: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// deliverWithRetries envía la entrega reintentando con backoff exponencial. Retoma desde los intentos
+// ya registrados y espera hasta nextAttemptAt, así una entrega reanudada tras un reinicio respeta el backoff.
+func (ws *WebhookService) deliverWithRetries(delivery *models.WebhookDelivery) {
+	maxAttempts := 1
+	if config.AppConfig.WebhookMaxRetries > 0 {
+		maxAttempts += config.AppConfig.WebhookMaxRetries
+	}
+
+	for attempt := delivery.Attempts + 1; attempt <= maxAttempts; attempt++ {
+		if delivery.NextAttemptAt != nil {
+			if wait := time.Until(*delivery.NextAttemptAt); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+
+		statusCode, err := ws.send(delivery)
+
+		delivery.Attempts++
+		delivery.ResponseStatus = statusCode
+
+		if err == nil {
+			now := time.Now()
+			delivery.Status = models.WebhookStatusDelivered
+			delivery.DeliveredAt = &now
+			delivery.NextAttemptAt = nil
+			delivery.LastError = ""
+			ws.saveDelivery(delivery)
+
+			log.Printf("📬 Webhook %s entregado a %s (intento %d)", delivery.Event, delivery.URL, attempt)
+			return
+		}
+
+		delivery.LastError = err.Error()
+		log.Printf("⚠️ Error entregando webhook %s a %s (intento %d/%d): %v",
+			delivery.Event, delivery.URL, attempt, maxAttempts, err)
+
+		if attempt < maxAttempts {
+			next := time.Now().Add(ws.baseBackoff << (attempt - 1))
+			delivery.Status = models.WebhookStatusRetrying
+			delivery.NextAttemptAt = &next
+			ws.saveDelivery(delivery)
+		}
+	}
+
+	delivery.Status = models.WebhookStatusFailed
+	delivery.NextAttemptAt = nil
+	ws.saveDelivery(delivery)
+	log.Printf("❌ Webhook %s a %s falló tras %d intentos", delivery.Event, delivery.URL, delivery.Attempts)
+}
+
+// send realiza la petición HTTP firmada y retorna el status de la respuesta
+func (ws *WebhookService) send(delivery *models.WebhookDelivery) (int, error) {
+	if config.AppConfig.WebhookSecret == "" {
+		return 0, fmt.Errorf("WEBHOOK_SECRET no está configurado, no se envían payloads sin firma")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creando request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Holding-Snapshots-Webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(config.AppConfig.WebhookSecret, timestamp, delivery.Payload))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("respuesta no exitosa: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// saveDelivery persiste el estado actual de la entrega
+func (ws *WebhookService) saveDelivery(delivery *models.WebhookDelivery) {
+	if err := ws.save(delivery); err != nil {
+		log.Printf("⚠️ Error actualizando entrega de webhook %s: %v", delivery.ID, err)
+	}
+}
+
+// SignWebhookPayload calcula la firma HMAC-SHA256 de "<timestamp>.<body>" en hexadecimal
+func SignWebhookPayload(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"holding-snapshots/internal/config"
+	"holding-snapshots/internal/models"
+)
+
+const testWebhookSecret = "secreto-de-prueba"
+
+// webhookReceiver es un receptor local que responde con los status configurados, en orden
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	status := http.StatusOK
+	if len(wr.requests) < len(wr.statuses) {
+		status = wr.statuses[len(wr.requests)]
+	}
+	wr.requests = append(wr.requests, r)
+	wr.bodies = append(wr.bodies, string(body))
+	w.WriteHeader(status)
+}
+
+// newTestWebhookService arma un servicio sin base de datos que registra cada estado guardado
+func newTestWebhookService(t *testing.T, maxRetries int) (*WebhookService, *[]models.WebhookDelivery) {
+	t.Helper()
+
+	previous := config.AppConfig
+	config.AppConfig = &config.Config{WebhookSecret: testWebhookSecret, WebhookMaxRetries: maxRetries}
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	var saved []models.WebhookDelivery
+	ws := &WebhookService{
+		client:      &http.Client{Timeout: time.Second},
+		baseBackoff: time.Millisecond,
+		save: func(delivery *models.WebhookDelivery) error {
+			saved = append(saved, *delivery)
+			return nil
+		},
+	}
+	return ws, &saved
+}
+
+func TestWebhookSignature(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ws, _ := newTestWebhookService(t, 0)
+	delivery := &models.WebhookDelivery{
+		ID:      "entrega-1",
+		Event:   EventRunCompleted,
+		URL:     server.URL,
+		Payload: `{"id":"entrega-1","event":"run.completed"}`,
+	}
+
+	ws.deliverWithRetries(delivery)
+
+	if len(receiver.requests) != 1 {
+		t.Fatalf("requests = %d, se esperaba 1", len(receiver.requests))
+	}
+	req := receiver.requests[0]
+
+	timestamp := req.Header.Get("X-Webhook-Timestamp")
+	want := "sha256=" + SignWebhookPayload(testWebhookSecret, timestamp, receiver.bodies[0])
+	if got := req.Header.Get("X-Webhook-Signature"); got != want {
+		t.Errorf("X-Webhook-Signature = %q, se esperaba %q", got, want)
+	}
+	if receiver.bodies[0] != delivery.Payload {
+		t.Errorf("body = %q, se esperaba %q", receiver.bodies[0], delivery.Payload)
+	}
+	if got := req.Header.Get("X-Webhook-Event"); got != EventRunCompleted {
+		t.Errorf("X-Webhook-Event = %q, se esperaba %q", got, EventRunCompleted)
+	}
+	if got := req.Header.Get("X-Webhook-Delivery"); got != delivery.ID {
+		t.Errorf("X-Webhook-Delivery = %q, se esperaba %q", got, delivery.ID)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		statuses     []int
+		wantRequests int
+		wantStatus   string
+		wantResponse int
+	}{
+		{
+			name:         "entregado tras errores 5xx",
+			maxRetries:   3,
+			statuses:     []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK},
+			wantRequests: 3,
+			wantStatus:   models.WebhookStatusDelivered,
+			wantResponse: http.StatusOK,
+		},
+		{
+			name:         "falla al agotar los reintentos",
+			maxRetries:   2,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantRequests: 3,
+			wantStatus:   models.WebhookStatusFailed,
+			wantResponse: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "sin reintentos",
+			maxRetries:   0,
+			statuses:     []int{http.StatusInternalServerError},
+			wantRequests: 1,
+			wantStatus:   models.WebhookStatusFailed,
+			wantResponse: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			ws, saved := newTestWebhookService(t, tt.maxRetries)
+			delivery := &models.WebhookDelivery{ID: "entrega", Event: EventRunFailed, URL: server.URL, Payload: "{}"}
+
+			ws.deliverWithRetries(delivery)
+
+			if len(receiver.requests) != tt.wantRequests {
+				t.Errorf("requests = %d, se esperaban %d", len(receiver.requests), tt.wantRequests)
+			}
+			if delivery.Attempts != tt.wantRequests {
+				t.Errorf("Attempts = %d, se esperaban %d", delivery.Attempts, tt.wantRequests)
+			}
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("Status = %s, se esperaba %s", delivery.Status, tt.wantStatus)
+			}
+			if delivery.ResponseStatus != tt.wantResponse {
+				t.Errorf("ResponseStatus = %d, se esperaba %d", delivery.ResponseStatus, tt.wantResponse)
+			}
+			if delivery.NextAttemptAt != nil {
+				t.Error("NextAttemptAt debe quedar vacío al terminar")
+			}
+
+			// Cada reintento queda guardado como retrying con la fecha del próximo intento
+			for _, state := range (*saved)[:len(*saved)-1] {
+				if state.Status != models.WebhookStatusRetrying || state.NextAttemptAt == nil {
+					t.Errorf("estado intermedio %s sin próximo intento", state.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookResumeFromAttempts(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ws, _ := newTestWebhookService(t, 3)
+
+	// Entrega que ya hizo dos intentos antes de un reinicio
+	next := time.Now().Add(20 * time.Millisecond)
+	delivery := &models.WebhookDelivery{
+		ID:            "entrega",
+		Event:         EventRunStarted,
+		URL:           server.URL,
+		Payload:       "{}",
+		Status:        models.WebhookStatusRetrying,
+		Attempts:      2,
+		NextAttemptAt: &next,
+	}
+
+	ws.deliverWithRetries(delivery)
+
+	if time.Now().Before(next) {
+		t.Error("el reintento no esperó hasta nextAttemptAt")
+	}
+	if len(receiver.requests) != 1 || delivery.Attempts != 3 {
+		t.Errorf("requests = %d, Attempts = %d, se esperaban 1 y 3", len(receiver.requests), delivery.Attempts)
+	}
+	if delivery.Status != models.WebhookStatusDelivered {
+		t.Errorf("Status = %s, se esperaba %s", delivery.Status, models.WebhookStatusDelivered)
+	}
+}
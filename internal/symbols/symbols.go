@@ -0,0 +1,121 @@
+// Package symbols normaliza los códigos ingresados por los usuarios a un código canónico
+// y los traduce al formato de cada proveedor de precios.
+package symbols
+
+import "strings"
+
+// Proveedores de precios conocidos
+const (
+	ProviderYahoo = "yahoo"
+)
+
+// Rules son las reglas de un tipo de inversión
+type Rules struct {
+	Crypto bool   // Pares como BTC-USD: el separador canónico es "-"
+	Suffix string // Sufijo de mercado del proveedor (ej. ".BA"), no forma parte del código canónico
+}
+
+// Normalize convierte un código ingresado al código canónico: mayúsculas, sin el sufijo de mercado
+// y con un único separador ("." para clases de acciones como BRK.B, "-" para pares cripto).
+func Normalize(input string, rules Rules) string {
+	code := strings.ToUpper(strings.TrimSpace(input))
+	if code == "" {
+		return ""
+	}
+
+	if suffix := strings.ToUpper(rules.Suffix); suffix != "" && strings.HasSuffix(code, suffix) && len(code) > len(suffix) {
+		code = strings.TrimSuffix(code, suffix)
+	}
+
+	separator := "."
+	if rules.Crypto {
+		separator = "-"
+	}
+
+	var b strings.Builder
+	pendingSeparator := false
+	for _, r := range code {
+		switch r {
+		case '.', '-', '/', '_', ' ':
+			pendingSeparator = b.Len() > 0
+			continue
+		}
+		if pendingSeparator {
+			b.WriteString(separator)
+			pendingSeparator = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// marketSuffixes son sufijos de mercado de Yahoo que pueden venir en códigos ya guardados (GGAL.BA)
+// y no deben confundirse con clases de acciones (BRK.B)
+var marketSuffixes = map[string]bool{
+	"BA": true, "SA": true, "MX": true, "SN": true, "TO": true, "DE": true, "PA": true,
+	"MI": true, "AS": true, "MC": true, "SW": true, "HK": true, "AX": true,
+}
+
+// ProviderSymbol traduce un código canónico al formato del proveedor cuando no hay un mapeo explícito
+func ProviderSymbol(code, provider string, rules Rules) string {
+	switch provider {
+	case ProviderYahoo:
+		if rules.Crypto {
+			return code
+		}
+		// Yahoo usa "-" para las clases de acciones (BRK-B) y un sufijo por mercado (GGAL.BA)
+		base, market := splitMarket(code)
+		symbol := strings.ReplaceAll(base, ".", "-") + market
+		if market == "" {
+			symbol += rules.Suffix
+		}
+		return symbol
+	default:
+		return code
+	}
+}
+
+// splitMarket separa un sufijo de mercado conocido del código ("GGAL.BA" -> "GGAL", ".BA")
+func splitMarket(code string) (string, string) {
+	i := strings.LastIndex(code, ".")
+	if i <= 0 || !marketSuffixes[code[i+1:]] {
+		return code, ""
+	}
+	return code[:i], code[i:]
+}
+
+// StoredForms retorna las formas en que un código canónico puede estar guardado en registros previos
+// a la normalización: con otro separador (BRK-B, BTC/USD), con el sufijo de mercado (GGAL.BA) o con el
+// formato del proveedor. El código canónico va primero.
+func StoredForms(code string, rules Rules) []string {
+	if code == "" {
+		return nil
+	}
+
+	forms := []string{code}
+	seen := map[string]bool{code: true}
+	add := func(form string) {
+		if form != "" && !seen[form] {
+			seen[form] = true
+			forms = append(forms, form)
+		}
+	}
+
+	canonicalSeparator := "."
+	if rules.Crypto {
+		canonicalSeparator = "-"
+	}
+
+	base, market := splitMarket(code)
+	for _, separator := range []string{".", "-", "/"} {
+		variant := strings.ReplaceAll(base, canonicalSeparator, separator)
+		add(variant + market)
+		if market == "" && rules.Suffix != "" {
+			add(variant + strings.ToUpper(rules.Suffix))
+		}
+	}
+	add(ProviderSymbol(code, ProviderYahoo, rules))
+
+	return forms
+}
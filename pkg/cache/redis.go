@@ -47,7 +47,33 @@ func Get(ctx context.Context, key string) (string, error) {
 	return RedisClient.Get(ctx, key).Result()
 }
 
-// Delete elimina una clave del cache
-func Delete(ctx context.Context, key string) error {
-	return RedisClient.Del(ctx, key).Err()
-}
\ No newline at end of file
+// Delete elimina una o más claves del cache
+func Delete(ctx context.Context, keys ...string) error {
+	return RedisClient.Del(ctx, keys...).Err()
+}
+
+// TTL obtiene el tiempo de vida restante de una clave
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	return RedisClient.TTL(ctx, key).Result()
+}
+
+// ScanKeys obtiene las claves que coinciden con un patrón usando SCAN (sin bloquear Redis como KEYS).
+// Con limit > 0 se detiene al alcanzar esa cantidad de claves.
+func ScanKeys(ctx context.Context, pattern string, limit int) ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := RedisClient.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if limit > 0 && len(keys) >= limit {
+			return keys[:limit], nil
+		}
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
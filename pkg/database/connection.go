@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"log"
 
 	"gorm.io/driver/postgres"
@@ -42,3 +43,83 @@ func EnableUUIDExtension() error {
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// AutoMigrate crea o actualiza las tablas propias de este servicio.
+// Las tablas del servicio principal (User, Group, Holding, etc.) no se migran desde aquí,
+// por eso los modelos propios solo guardan los IDs y no declaran relaciones hacia ellas.
+func AutoMigrate(models ...interface{}) error {
+	err := DB.AutoMigrate(models...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Migraciones aplicadas para %d tablas", len(models))
+	return nil
+}
+
+// AddColumnIfMissing agrega a una tabla del servicio principal una columna usada solo por este servicio.
+// field es el nombre del campo en el modelo.
+func AddColumnIfMissing(model interface{}, field string) error {
+	if DB.Migrator().HasColumn(model, field) {
+		return nil
+	}
+
+	if err := DB.Migrator().AddColumn(model, field); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Columna %s agregada", field)
+	return nil
+}
+
+// ReplaceUniqueIndex reemplaza los índices únicos de una sola columna de una tabla del servicio principal
+// por el índice único indexName con la definición dada (por ejemplo `("typeId", UPPER(code))`, para que
+// la unicidad sea por tipo). Los drops y el create corren en una transacción: si el índice nuevo no se
+// puede crear (por ejemplo por duplicados) la tabla queda como estaba.
+func ReplaceUniqueIndex(table, column, indexName, definition string) error {
+	var exists bool
+	if err := DB.Raw(`SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE tablename = ? AND indexname = ?)`,
+		table, indexName).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var existing []struct {
+			IndexName      string
+			ConstraintName *string
+		}
+		err := tx.Raw(`
+			SELECT i.relname AS index_name, c.conname AS constraint_name
+			FROM pg_index x
+			JOIN pg_class i ON i.oid = x.indexrelid
+			JOIN pg_class t ON t.oid = x.indrelid
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+			LEFT JOIN pg_constraint c ON c.conindid = x.indexrelid AND c.contype = 'u'
+			WHERE t.relname = ? AND a.attname = ? AND x.indisunique AND NOT x.indisprimary AND x.indnatts = 1`,
+			table, column).Scan(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		for _, index := range existing {
+			statement := fmt.Sprintf(`DROP INDEX IF EXISTS %q`, index.IndexName)
+			if index.ConstraintName != nil {
+				statement = fmt.Sprintf(`ALTER TABLE %q DROP CONSTRAINT %q`, table, *index.ConstraintName)
+			}
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+			log.Printf("🗑️ Índice único %s de %s.%s eliminado", index.IndexName, table, column)
+		}
+
+		if err := tx.Exec(fmt.Sprintf(`CREATE UNIQUE INDEX %q ON %q %s`, indexName, table, definition)).Error; err != nil {
+			return err
+		}
+
+		log.Printf("✅ Índice %s creado", indexName)
+		return nil
+	})
+}
@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// Settings contiene los datos de conexión al servidor SMTP
+type Settings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Message representa un email con versión de texto plano y HTML
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+var settings *Settings
+
+// Configure establece la configuración SMTP usada por Send
+func Configure(s Settings) {
+	settings = &s
+	log.Printf("✅ Mailer configurado con servidor SMTP %s:%d", s.Host, s.Port)
+}
+
+// IsConfigured indica si hay un servidor SMTP configurado
+func IsConfigured() bool {
+	return settings != nil && settings.Host != ""
+}
+
+// Send envía un email multipart/alternative (texto + HTML).
+// Si no hay usuario configurado se envía sin autenticación (útil para sinks locales como MailHog).
+func Send(msg Message) error {
+	if !IsConfigured() {
+		return fmt.Errorf("servidor SMTP no configurado")
+	}
+
+	from, err := mail.ParseAddress(settings.From)
+	if err != nil {
+		return fmt.Errorf("remitente inválido '%s': %w", settings.From, err)
+	}
+
+	body, err := buildMessage(from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+	}
+
+	addr := settings.Host + ":" + strconv.Itoa(settings.Port)
+	if err := smtp.SendMail(addr, auth, from.Address, []string{msg.To}, body); err != nil {
+		return fmt.Errorf("error enviando email a %s: %w", msg.To, err)
+	}
+
+	return nil
+}
+
+// buildMessage arma el mensaje MIME con las partes de texto y HTML
+func buildMessage(from *mail.Address, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + msg.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + writer.Boundary(),
+	}
+	for _, header := range headers {
+		buf.WriteString(header + "\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creando parte MIME: %w", err)
+		}
+		part.Write([]byte(p.content))
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error cerrando mensaje MIME: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
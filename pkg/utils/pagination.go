@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cursor identifica la posición de un elemento en un listado ordenado por fecha e ID
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// EncodeCursor serializa un cursor como string opaco para la API
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor deserializa un cursor generado por EncodeCursor
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || !IsValidUUID(parts[1]) {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+// ParseDateParam interpreta una fecha en formato RFC3339 o YYYY-MM-DD (UTC)
+func ParseDateParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("fecha inválida '%s', se espera YYYY-MM-DD o RFC3339", value)
+	}
+	return &t, nil
+}
+
+// ParseDateRange interpreta los parámetros from/to; si "to" es solo fecha se incluye el día completo
+func ParseDateRange(fromValue, toValue string) (*time.Time, *time.Time, error) {
+	from, err := ParseDateParam(fromValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := ParseDateParam(toValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if to != nil && len(toValue) == len("2006-01-02") {
+		endOfDay := to.Add(24*time.Hour - time.Nanosecond)
+		to = &endOfDay
+	}
+
+	if from != nil && to != nil && from.After(*to) {
+		return nil, nil, fmt.Errorf("el parámetro from debe ser anterior a to")
+	}
+
+	return from, to, nil
+}
+
+// ClampLimit normaliza el tamaño de página al rango [1, max]
+func ClampLimit(limit, defaultLimit, max int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}